@@ -0,0 +1,431 @@
+// Package analysis finds modelling bugs in statechartx machines.
+//
+// NewMachine and MachineBuilder.Build reject structurally broken charts (cycles,
+// duplicate IDs, unknown targets). Lint goes further and reports charts that build
+// fine but will not behave as intended:
+//
+//   - states that can never be entered
+//   - non-final states with no transition out
+//   - transitions that can never fire because an earlier unguarded transition on the
+//     same event always wins
+//   - cycles of unguarded eventless transitions, which spin until MAX_MICROSTEPS
+//   - compound states whose Initial is not one of their children
+//   - history states whose default is missing or outside their parent
+//   - parallel states with fewer than two regions
+//
+// Lint is conservative about guards: a guarded transition is assumed to be able to
+// fire, so reachability is an over-approximation and only guard-free cycles are reported.
+//
+// Example:
+//
+//	machine, _ := b.Build()
+//	for _, d := range analysis.LintNamed(machine, b) {
+//		log.Println(d)
+//	}
+package analysis
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/comalice/statechartx"
+)
+
+// Severity classifies a diagnostic.
+type Severity int
+
+const (
+	// Warning marks a likely modelling mistake that the runtimes tolerate.
+	Warning Severity = iota
+	// Error marks a chart the runtimes will execute incorrectly.
+	Error
+)
+
+func (s Severity) String() string {
+	if s == Error {
+		return "error"
+	}
+	return "warning"
+}
+
+// Code identifies the kind of problem a diagnostic reports.
+type Code string
+
+// Diagnostic codes
+const (
+	UnreachableState      Code = "unreachable-state"
+	DeadEndState          Code = "dead-end-state"
+	ShadowedTransition    Code = "shadowed-transition"
+	EventlessCycle        Code = "eventless-cycle"
+	InvalidInitial        Code = "invalid-initial"
+	InvalidHistoryDefault Code = "invalid-history-default"
+	SingleRegionParallel  Code = "single-region-parallel"
+)
+
+// Diagnostic is a single problem found by Lint.
+type Diagnostic struct {
+	Code     Code
+	Severity Severity
+	State    statechartx.StateID // state the problem belongs to
+	// Transition is the index into State's Transitions, or -1 if the
+	// diagnostic is not about a transition.
+	Transition int
+	Message    string
+}
+
+// String formats the diagnostic as "severity [code] message".
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("%s [%s] %s", d.Severity, d.Code, d.Message)
+}
+
+// HasErrors reports whether any diagnostic has Error severity.
+func HasErrors(diags []Diagnostic) bool {
+	for _, d := range diags {
+		if d.Severity == Error {
+			return true
+		}
+	}
+	return false
+}
+
+// Lint analyses m and returns its diagnostics ordered by state ID.
+// States are named "s<id>" in messages; use LintNamed to use builder names.
+func Lint(m *statechartx.Machine) []Diagnostic {
+	return LintNamed(m, nil)
+}
+
+// LintNamed is like Lint but names states and events with names
+// (typically the *statechartx.MachineBuilder that built m).
+func LintNamed(m *statechartx.Machine, names statechartx.Namer) []Diagnostic {
+	l := &linter{m: m, names: names}
+	if m.Root() == nil {
+		return nil
+	}
+	l.checkStructure()
+	l.checkTransitions()
+	l.checkReachability()
+	l.checkEventlessCycles()
+
+	sort.SliceStable(l.diags, func(i, j int) bool {
+		if l.diags[i].State != l.diags[j].State {
+			return l.diags[i].State < l.diags[j].State
+		}
+		return l.diags[i].Transition < l.diags[j].Transition
+	})
+	return l.diags
+}
+
+type linter struct {
+	m     *statechartx.Machine
+	names statechartx.Namer
+	diags []Diagnostic
+}
+
+func (l *linter) report(code Code, sev Severity, s *statechartx.State, transition int, format string, args ...any) {
+	l.diags = append(l.diags, Diagnostic{
+		Code:       code,
+		Severity:   sev,
+		State:      s.ID,
+		Transition: transition,
+		Message:    fmt.Sprintf(format, args...),
+	})
+}
+
+func (l *linter) name(id statechartx.StateID) string {
+	return statechartx.StateNameOf(l.names, id)
+}
+
+// states returns every state in ascending ID order
+func (l *linter) states() []*statechartx.State {
+	var list []*statechartx.State
+	for _, id := range l.m.StateIDs() {
+		list = append(list, l.m.GetState(id))
+	}
+	return list
+}
+
+// checkStructure reports invalid initial children, history defaults and parallel regions
+func (l *linter) checkStructure() {
+	for _, s := range l.states() {
+		switch {
+		case s.IsHistoryState:
+			l.checkHistory(s)
+		case s.IsParallel:
+			if len(s.Children) < 2 {
+				l.report(SingleRegionParallel, Warning, s, -1,
+					"parallel state %s has %d region(s); use a compound state instead", l.name(s.ID), len(s.Children))
+			}
+		case len(s.Children) > 0:
+			if _, isChild := s.Children[s.Initial]; isChild {
+				break
+			}
+			switch initial := l.m.GetState(s.Initial); {
+			case initial == nil || initial == s:
+				l.report(InvalidInitial, Error, s, -1, "compound state %s has no valid initial state", l.name(s.ID))
+			case initial.IsDescendantOf(s):
+				l.report(InvalidInitial, Warning, s, -1,
+					"initial state %s of compound state %s is not a direct child; states between them are skipped on entry",
+					l.name(s.Initial), l.name(s.ID))
+			default:
+				l.report(InvalidInitial, Error, s, -1,
+					"initial state %s of compound state %s is not one of its descendants", l.name(s.Initial), l.name(s.ID))
+			}
+		}
+	}
+}
+
+func (l *linter) checkHistory(h *statechartx.State) {
+	if h.HistoryDefault == 0 {
+		l.report(InvalidHistoryDefault, Warning, h, -1,
+			"history state %s has no default; transitions to it are ignored until history is recorded", l.name(h.ID))
+		return
+	}
+	def := l.m.GetState(h.HistoryDefault)
+	switch {
+	case def == nil:
+		l.report(InvalidHistoryDefault, Error, h, -1, "history state %s has unknown default state ID %d", l.name(h.ID), h.HistoryDefault)
+	case def.IsHistoryState:
+		l.report(InvalidHistoryDefault, Error, h, -1, "default of history state %s is itself a history state", l.name(h.ID))
+	case h.Parent == nil || !def.IsDescendantOf(h.Parent):
+		l.report(InvalidHistoryDefault, Error, h, -1,
+			"default %s of history state %s is not inside its parent", l.name(def.ID), l.name(h.ID))
+	case h.HistoryType != statechartx.HistoryDeep && def.Parent != h.Parent:
+		l.report(InvalidHistoryDefault, Error, h, -1,
+			"default %s of shallow history state %s is not a sibling", l.name(def.ID), l.name(h.ID))
+	}
+}
+
+// checkTransitions reports transitions shadowed by an earlier unguarded transition
+func (l *linter) checkTransitions() {
+	for _, s := range l.states() {
+		taken := make(map[statechartx.EventID]int)
+		for i, t := range s.Transitions {
+			if t == nil {
+				continue
+			}
+			if first, ok := taken[t.Event]; ok {
+				l.report(ShadowedTransition, Warning, s, i,
+					"transition %d on %q from %s never fires: transition %d on the same event has no guard",
+					i, l.eventName(t.Event), l.name(s.ID), first)
+				continue
+			}
+			if t.Guard == nil {
+				taken[t.Event] = i
+			}
+		}
+	}
+}
+
+func (l *linter) eventName(id statechartx.EventID) string {
+	if id == statechartx.NO_EVENT {
+		return "(eventless)"
+	}
+	return statechartx.EventNameOf(l.names, id)
+}
+
+// checkReachability reports states that can never be entered and reachable dead ends.
+// Guards are assumed to pass, so every transition out of an entered state is followed.
+func (l *linter) checkReachability() {
+	root := l.m.Root()
+	reached := map[statechartx.StateID]bool{}
+	var queue []*statechartx.State
+
+	var enter func(s *statechartx.State)
+	enter = func(s *statechartx.State) {
+		if s == nil || reached[s.ID] {
+			return
+		}
+		reached[s.ID] = true
+		queue = append(queue, s)
+		enter(s.Parent)
+		switch {
+		case s.IsHistoryState:
+			enter(l.m.GetState(s.HistoryDefault))
+		case s.IsParallel:
+			for _, child := range s.Children {
+				enter(child)
+			}
+		case len(s.Children) > 0:
+			enter(l.initial(s))
+		}
+	}
+
+	enter(root)
+	for len(queue) > 0 {
+		s := queue[0]
+		queue = queue[1:]
+		for _, t := range s.Transitions {
+			if t != nil && t.Target != 0 {
+				enter(l.m.GetState(t.Target))
+			}
+		}
+	}
+
+	for _, s := range l.states() {
+		if !reached[s.ID] {
+			l.report(UnreachableState, Warning, s, -1, "state %s is never entered", l.name(s.ID))
+			continue
+		}
+		if len(s.Children) > 0 || s.IsHistoryState || s.IsFinal || s.Final || s.Parent == nil {
+			continue
+		}
+		if !hasWayOut(s) {
+			l.report(DeadEndState, Warning, s, -1, "state %s is not final but has no transition out", l.name(s.ID))
+		}
+	}
+}
+
+// hasWayOut reports whether s or an ancestor has an external transition
+func hasWayOut(s *statechartx.State) bool {
+	for cur := s; cur != nil; cur = cur.Parent {
+		for _, t := range cur.Transitions {
+			if t != nil && t.Target != 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// checkEventlessCycles reports configurations that unguarded eventless transitions
+// cycle through forever. Each atomic (or parallel) state is a node; its edge is the
+// eventless transition the runtime always takes from it, if any.
+func (l *linter) checkEventlessCycles() {
+	next := map[statechartx.StateID][]statechartx.StateID{}
+	var nodes []statechartx.StateID
+	for _, s := range l.states() {
+		if s.IsHistoryState || (len(s.Children) > 0 && !s.IsParallel) {
+			continue
+		}
+		nodes = append(nodes, s.ID)
+		t := alwaysEventless(s)
+		if t == nil {
+			continue
+		}
+		if t.Target == 0 {
+			next[s.ID] = append(next[s.ID], s.ID) // internal: the same transition repeats
+			continue
+		}
+		for _, leaf := range l.landing(l.m.GetState(t.Target)) {
+			next[s.ID] = append(next[s.ID], leaf)
+		}
+	}
+
+	for _, scc := range stronglyConnected(nodes, next) {
+		if len(scc) == 1 && !contains(next[scc[0]], scc[0]) {
+			continue
+		}
+		sort.Slice(scc, func(i, j int) bool { return scc[i] < scc[j] })
+		names := make([]string, len(scc))
+		for i, id := range scc {
+			names[i] = l.name(id)
+		}
+		l.report(EventlessCycle, Error, l.m.GetState(scc[0]), -1,
+			"unguarded eventless transitions cycle through %s and will stop at MAX_MICROSTEPS (%d)",
+			strings.Join(names, ", "), statechartx.MAX_MICROSTEPS)
+	}
+}
+
+// alwaysEventless returns the eventless transition the runtime is certain to take
+// from s: the first one found searching outward, provided no guarded eventless
+// transition is found before it.
+func alwaysEventless(s *statechartx.State) *statechartx.Transition {
+	for cur := s; cur != nil; cur = cur.Parent {
+		for _, t := range cur.Transitions {
+			if t == nil || t.Event != statechartx.NO_EVENT {
+				continue
+			}
+			if t.Guard != nil {
+				return nil
+			}
+			return t
+		}
+	}
+	return nil
+}
+
+// landing returns the states the runtime settles in after entering target
+func (l *linter) landing(target *statechartx.State) []statechartx.StateID {
+	for target != nil {
+		switch {
+		case target.IsHistoryState:
+			target = l.m.GetState(target.HistoryDefault)
+		case target.IsParallel || len(target.Children) == 0:
+			return []statechartx.StateID{target.ID}
+		default:
+			target = l.initial(target)
+		}
+	}
+	return nil
+}
+
+// initial returns the initial state of compound state s, or nil if it is not inside s
+func (l *linter) initial(s *statechartx.State) *statechartx.State {
+	if initial := l.m.GetState(s.Initial); initial != nil && initial.IsDescendantOf(s) {
+		return initial
+	}
+	return nil
+}
+
+func contains(ids []statechartx.StateID, id statechartx.StateID) bool {
+	for _, x := range ids {
+		if x == id {
+			return true
+		}
+	}
+	return false
+}
+
+// stronglyConnected returns the strongly connected components of the graph (Tarjan)
+func stronglyConnected(nodes []statechartx.StateID, next map[statechartx.StateID][]statechartx.StateID) [][]statechartx.StateID {
+	index := map[statechartx.StateID]int{}
+	low := map[statechartx.StateID]int{}
+	onStack := map[statechartx.StateID]bool{}
+	var stack []statechartx.StateID
+	var sccs [][]statechartx.StateID
+	counter := 0
+
+	var visit func(v statechartx.StateID)
+	visit = func(v statechartx.StateID) {
+		index[v] = counter
+		low[v] = counter
+		counter++
+		stack = append(stack, v)
+		onStack[v] = true
+
+		for _, w := range next[v] {
+			if _, seen := index[w]; !seen {
+				visit(w)
+				if low[w] < low[v] {
+					low[v] = low[w]
+				}
+			} else if onStack[w] {
+				if index[w] < low[v] {
+					low[v] = index[w]
+				}
+			}
+		}
+
+		if low[v] == index[v] {
+			var scc []statechartx.StateID
+			for {
+				w := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				onStack[w] = false
+				scc = append(scc, w)
+				if w == v {
+					break
+				}
+			}
+			sccs = append(sccs, scc)
+		}
+	}
+
+	for _, v := range nodes {
+		if _, seen := index[v]; !seen {
+			visit(v)
+		}
+	}
+	return sccs
+}
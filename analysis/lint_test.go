@@ -0,0 +1,150 @@
+package analysis_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/comalice/statechartx"
+	"github.com/comalice/statechartx/analysis"
+)
+
+// findings returns the diagnostics with the given code
+func findings(diags []analysis.Diagnostic, code analysis.Code) []analysis.Diagnostic {
+	var out []analysis.Diagnostic
+	for _, d := range diags {
+		if d.Code == code {
+			out = append(out, d)
+		}
+	}
+	return out
+}
+
+func TestLintCleanMachine(t *testing.T) {
+	b := statechartx.NewMachineBuilder("light", "green")
+	b.State("green").On("timer", "yellow", nil, nil)
+	b.State("yellow").On("timer", "red", nil, nil)
+	b.State("red").On("timer", "green", nil, nil).On("off", "done", nil, nil)
+	b.State("done").Final(nil)
+	machine, err := b.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if diags := analysis.LintNamed(machine, b); len(diags) != 0 {
+		t.Errorf("expected no diagnostics, got %v", diags)
+	}
+}
+
+func TestLintBuilderMachine(t *testing.T) {
+	b := statechartx.NewMachineBuilder("app", "idle")
+	b.State("idle").
+		On("go", "work", nil, nil).
+		On("go", "orphan", statechartx.Always(), nil). // shadowed
+		On("loop", "spin.a", nil, nil)
+	b.State("work").Atomic() // dead end
+	b.State("orphan").On("back", "idle", nil, nil)
+	b.State("spin").Compound("spin.a")
+	b.State("spin.a").On("", "spin.b", nil, nil)
+	b.State("spin.b").On("", "spin.a", nil, nil)
+	b.State("guarded").Compound("guarded.a")
+	b.State("guarded.a").On("", "guarded.b", statechartx.Always(), nil)
+	b.State("guarded.b").On("", "guarded.a", nil, nil)
+	b.State("solo").Parallel()
+	b.State("solo.only").Atomic()
+	machine, err := b.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	diags := analysis.LintNamed(machine, b)
+
+	shadowed := findings(diags, analysis.ShadowedTransition)
+	if len(shadowed) != 1 || shadowed[0].State != b.GetID("idle") || shadowed[0].Transition != 1 {
+		t.Errorf("expected transition 1 of idle to be shadowed, got %v", shadowed)
+	}
+
+	deadEnds := findings(diags, analysis.DeadEndState)
+	if len(deadEnds) != 1 || deadEnds[0].State != b.GetID("work") {
+		t.Errorf("expected work to be a dead end, got %v", deadEnds)
+	}
+
+	cycles := findings(diags, analysis.EventlessCycle)
+	if len(cycles) != 1 || cycles[0].Severity != analysis.Error || !strings.Contains(cycles[0].Message, "spin.a, spin.b") {
+		t.Errorf("expected one eventless cycle through spin.a and spin.b, got %v", cycles)
+	}
+
+	unreachable := map[statechartx.StateID]bool{}
+	for _, d := range findings(diags, analysis.UnreachableState) {
+		unreachable[d.State] = true
+	}
+	for _, name := range []string{"guarded", "guarded.a", "guarded.b", "solo", "solo.only"} {
+		if !unreachable[b.GetID(name)] {
+			t.Errorf("expected %s to be unreachable", name)
+		}
+	}
+	if unreachable[b.GetID("orphan")] {
+		t.Error("guarded transitions are assumed to fire, so orphan is reachable")
+	}
+
+	if single := findings(diags, analysis.SingleRegionParallel); len(single) != 1 || single[0].State != b.GetID("solo") {
+		t.Errorf("expected solo to have a single region, got %v", single)
+	}
+	if analysis.HasErrors(diags) != true {
+		t.Error("eventless cycle should be an error")
+	}
+}
+
+func TestLintInternalEventlessLoop(t *testing.T) {
+	b := statechartx.NewMachineBuilder("app", "a")
+	b.State("a").OnInternal("", nil, nil).On("go", "b", nil, nil)
+	b.State("b").Final(nil)
+	machine, err := b.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cycles := findings(analysis.Lint(machine), analysis.EventlessCycle); len(cycles) != 1 {
+		t.Errorf("expected an unguarded internal eventless transition to be reported, got %v", cycles)
+	}
+}
+
+func TestLintStructure(t *testing.T) {
+	// Hand-built hierarchy with problems the builder would not let through
+	leaf1 := &statechartx.State{ID: 2}
+	leaf2 := &statechartx.State{ID: 3}
+	other := &statechartx.State{ID: 5}
+	hist := &statechartx.State{ID: 4, IsHistoryState: true, HistoryDefault: 5}
+	noDefault := &statechartx.State{ID: 6, IsHistoryState: true}
+	compound := &statechartx.State{
+		ID:       1,
+		Initial:  5, // not a child
+		Children: map[statechartx.StateID]*statechartx.State{2: leaf1, 3: leaf2, 4: hist, 6: noDefault},
+	}
+	root := &statechartx.State{
+		ID:       100,
+		Initial:  1,
+		Children: map[statechartx.StateID]*statechartx.State{1: compound, 5: other},
+	}
+	machine, err := statechartx.NewMachine(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	diags := analysis.Lint(machine)
+
+	initial := findings(diags, analysis.InvalidInitial)
+	if len(initial) != 1 || initial[0].State != 1 || initial[0].Severity != analysis.Error {
+		t.Errorf("expected invalid initial on s1, got %v", initial)
+	}
+
+	history := findings(diags, analysis.InvalidHistoryDefault)
+	if len(history) != 2 {
+		t.Fatalf("expected two history diagnostics, got %v", history)
+	}
+	if history[0].State != 4 || history[0].Severity != analysis.Error || !strings.Contains(history[0].Message, "not inside its parent") {
+		t.Errorf("unexpected diagnostic for s4: %v", history[0])
+	}
+	if history[1].State != 6 || history[1].Severity != analysis.Warning {
+		t.Errorf("unexpected diagnostic for s6: %v", history[1])
+	}
+}
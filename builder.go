@@ -2,9 +2,101 @@ package statechartx
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 )
 
+// Special event names understood by MachineBuilder and the machine definition formats.
+const (
+	AnyEventName                = "*"                   // ANY_EVENT
+	ErrorCommunicationEventName = "error.communication" // ERROR_COMMUNICATION
+	DoneStatePrefix             = "done.state."         // done.state.<state name> → DoneEventID
+	TimerEventPrefix            = "after."              // after.<state name>.<count> → TimerEventID
+)
+
+// Namer maps state and event IDs back to names for serialization and visualization.
+// *MachineBuilder implements Namer.
+type Namer interface {
+	GetName(id StateID) string
+	EventName(id EventID) string
+}
+
+// StateNameOf returns n's name for a state, or "s<id>" if n is nil or has no name for it.
+func StateNameOf(n Namer, id StateID) string {
+	if n != nil {
+		if name := n.GetName(id); name != "" {
+			return name
+		}
+	}
+	return fmt.Sprintf("s%d", id)
+}
+
+// EventNameOf returns n's name for an event, or the special event name, or "e<id>"
+// if n is nil or has no name for it. NO_EVENT is named "".
+func EventNameOf(n Namer, id EventID) string {
+	if n != nil {
+		if name := n.EventName(id); name != "" {
+			return name
+		}
+	}
+	if stateID, isDone := doneEventState(id); isDone {
+		return DoneStatePrefix + StateNameOf(n, stateID)
+	}
+	if stateID, count, isTimer := TimerEventState(id); isTimer {
+		return timerEventName(StateNameOf(n, stateID), count)
+	}
+	if name, special := specialEventName(id); special {
+		return name
+	}
+	return fmt.Sprintf("e%d", id)
+}
+
+// specialEventName names the reserved event IDs
+func specialEventName(id EventID) (string, bool) {
+	switch id {
+	case NO_EVENT:
+		return "", true
+	case ANY_EVENT:
+		return AnyEventName, true
+	case ERROR_COMMUNICATION:
+		return ErrorCommunicationEventName, true
+	}
+	if _, isDone := doneEventState(id); isDone {
+		return DoneStatePrefix, true
+	}
+	if _, _, isTimer := TimerEventState(id); isTimer {
+		return TimerEventPrefix, true
+	}
+	return "", false
+}
+
+// doneEventState inverts DoneEventID. Timer event IDs are not done events.
+func doneEventState(id EventID) (StateID, bool) {
+	if id > -1000000 || int64(id) <= TIMER_EVENT_BASE {
+		return 0, false
+	}
+	return StateID(-int(id) - 1000000), true
+}
+
+// timerEventName formats the name of a timer event
+func timerEventName(stateName string, count uint64) string {
+	return TimerEventPrefix + stateName + "." + strconv.FormatUint(count, 10)
+}
+
+// parseTimerEventName splits after.<state name>.<count>
+func parseTimerEventName(name string) (stateName string, count uint64, ok bool) {
+	rest := strings.TrimPrefix(name, TimerEventPrefix)
+	dot := strings.LastIndexByte(rest, '.')
+	if rest == name || dot <= 0 {
+		return "", 0, false
+	}
+	count, err := strconv.ParseUint(rest[dot+1:], 10, 64)
+	if err != nil {
+		return "", 0, false
+	}
+	return rest[:dot], count, true
+}
+
 // MachineBuilder provides a fluent API for constructing state machines using string-based state names
 // instead of manual integer-based State struct creation.
 type MachineBuilder struct {
@@ -14,6 +106,8 @@ type MachineBuilder struct {
 	states   map[StateID]*State
 	root     *State
 	rootName string
+	registry *Registry
+	errs     []error // deferred errors from name lookups, reported by Build
 }
 
 // StateBuilder provides fluent methods for configuring individual states.
@@ -30,22 +124,24 @@ func NewMachineBuilder(rootName, initialStateName string) *MachineBuilder {
 		nextID:   1, // Root gets ID 0
 		nameToID: make(map[string]StateID),
 		idToName: make(map[StateID]string),
-		states:   make(map[StateID]*State),
-		rootName: rootName,
 	}
+	b.initRoot(rootName, initialStateName)
+	return b
+}
 
-	// Create root state
+// initRoot creates the root state with ID 0
+func (b *MachineBuilder) initRoot(rootName, initialStateName string) {
 	rootID := StateID(0)
+	b.rootName = rootName
 	b.nameToID[rootName] = rootID
 	b.idToName[rootID] = rootName
+	b.states = make(map[StateID]*State)
 	b.root = &State{
 		ID:       rootID,
 		Initial:  b.assignID(initialStateName), // Forward ref ok
 		Children: make(map[StateID]*State),
 	}
 	b.states[rootID] = b.root
-
-	return b
 }
 
 // State creates or retrieves a state by name.
@@ -108,6 +204,10 @@ func (b *MachineBuilder) State(name string) *StateBuilder {
 // Build validates the state machine configuration and constructs the Machine.
 // Returns an error if the configuration is invalid.
 func (b *MachineBuilder) Build() (*Machine, error) {
+	if len(b.errs) > 0 {
+		return nil, b.errs[0]
+	}
+
 	// Validate: all referenced states exist
 	if err := b.validate(); err != nil {
 		return nil, err
@@ -117,6 +217,18 @@ func (b *MachineBuilder) Build() (*Machine, error) {
 	return NewMachine(b.root)
 }
 
+// WithRegistry sets the registry used to resolve guard and action names
+// in OnRef, OnInternalRef, EntryRef and ExitRef.
+func (b *MachineBuilder) WithRegistry(r *Registry) *MachineBuilder {
+	b.registry = r
+	return b
+}
+
+// Registry returns the registry set with WithRegistry, or nil.
+func (b *MachineBuilder) Registry() *Registry {
+	return b.registry
+}
+
 // GetID returns the assigned StateID for a given state name.
 // Returns 0 if the name hasn't been registered.
 func (b *MachineBuilder) GetID(name string) StateID {
@@ -129,6 +241,88 @@ func (b *MachineBuilder) GetName(id StateID) string {
 	return b.idToName[id]
 }
 
+// EventID returns the EventID assigned to an event name used in On/OnInternal.
+// Special event names ("", "*", "error.communication", "done.state.<state>",
+// "after.<state>.<count>") map to their reserved IDs. Returns 0 (NO_EVENT) if the
+// event name hasn't been registered.
+func (b *MachineBuilder) EventID(name string) EventID {
+	if stateName, count, isTimer := parseTimerEventName(name); isTimer {
+		stateID, exists := b.nameToID[stateName]
+		if !exists {
+			return NO_EVENT
+		}
+		id, err := TimerEventID(stateID, count)
+		if err != nil {
+			return NO_EVENT
+		}
+		return id
+	}
+	switch {
+	case name == "":
+		return NO_EVENT
+	case name == AnyEventName:
+		return ANY_EVENT
+	case name == ErrorCommunicationEventName:
+		return ERROR_COMMUNICATION
+	case strings.HasPrefix(name, DoneStatePrefix):
+		stateID, exists := b.nameToID[strings.TrimPrefix(name, DoneStatePrefix)]
+		if !exists {
+			return NO_EVENT
+		}
+		return DoneEventID(stateID)
+	}
+	return EventID(b.nameToID["event:"+name])
+}
+
+// EventName returns the event name for an EventID assigned by this builder,
+// including the special event names. Returns empty string if the ID doesn't
+// belong to an event.
+func (b *MachineBuilder) EventName(id EventID) string {
+	if name, special := specialEventName(id); special {
+		if stateID, isDone := doneEventState(id); isDone {
+			if stateName, exists := b.idToName[stateID]; exists {
+				return DoneStatePrefix + stateName
+			}
+			return ""
+		}
+		if stateID, count, isTimer := TimerEventState(id); isTimer {
+			if stateName, exists := b.idToName[stateID]; exists {
+				return timerEventName(stateName, count)
+			}
+			return ""
+		}
+		return name
+	}
+	name := b.idToName[StateID(id)]
+	if !strings.HasPrefix(name, "event:") {
+		return ""
+	}
+	return strings.TrimPrefix(name, "event:")
+}
+
+// eventID returns the EventID for an event name, assigning a new ID if needed.
+func (b *MachineBuilder) eventID(name string) EventID {
+	if strings.HasPrefix(name, DoneStatePrefix) {
+		return DoneEventID(b.assignID(strings.TrimPrefix(name, DoneStatePrefix)))
+	}
+	if stateName, count, isTimer := parseTimerEventName(name); isTimer {
+		id, err := TimerEventID(b.assignID(stateName), count)
+		if err != nil {
+			b.errs = append(b.errs, fmt.Errorf("event %q: %w", name, err))
+		}
+		return id
+	}
+	switch name {
+	case "":
+		return NO_EVENT
+	case AnyEventName:
+		return ANY_EVENT
+	case ErrorCommunicationEventName:
+		return ERROR_COMMUNICATION
+	}
+	return EventID(b.assignID("event:" + name))
+}
+
 // assignID returns the existing ID for a name, or creates a new sequential ID.
 // This ensures deterministic ID assignment.
 func (b *MachineBuilder) assignID(name string) StateID {
@@ -219,45 +413,67 @@ func (sb *StateBuilder) Final(data any) *StateBuilder {
 	return sb
 }
 
+// FinalRef marks this state as a final state whose done event data is resolved
+// by name from the builder's registry. Unknown names are reported by Build.
+func (sb *StateBuilder) FinalRef(dataName string) *StateBuilder {
+	sb.state.IsFinal = true
+	if sb.b.registry == nil {
+		sb.b.errs = append(sb.b.errs, fmt.Errorf("state %s: data %q referenced without a registry", sb.name, dataName))
+		return sb
+	}
+	data, ok := sb.b.registry.Data(dataName)
+	if !ok {
+		sb.b.errs = append(sb.b.errs, fmt.Errorf("state %s: %w: data %q", sb.name, ErrUnknownName, dataName))
+		return sb
+	}
+	sb.state.FinalStateData = data
+	sb.state.FinalDataRef = dataName
+	return sb
+}
+
 // History marks this state as a history pseudo-state.
 // historyType should be HistoryShallow or HistoryDeep.
-// defaultStateName is the state to enter if no history exists.
+// defaultStateName is the state to enter if no history exists (empty for the parent's initial state).
 func (sb *StateBuilder) History(historyType HistoryType, defaultStateName string) *StateBuilder {
 	sb.state.IsHistoryState = true
 	sb.state.HistoryType = historyType
-	sb.state.HistoryDefault = sb.b.assignID(defaultStateName)
+	if defaultStateName != "" {
+		sb.state.HistoryDefault = sb.b.assignID(defaultStateName)
+	}
 	return sb
 }
 
-// Entry sets the entry action for this state.
-// The action will be executed when entering this state.
+// Entry appends an entry action for this state.
+// Entry actions are executed in the order they were added when entering this state.
 func (sb *StateBuilder) Entry(action Action) *StateBuilder {
-	sb.state.EntryAction = action
+	sb.state.EntryActions = append(sb.state.EntryActions, action)
 	return sb
 }
 
-// Exit sets the exit action for this state.
-// The action will be executed when exiting this state.
+// Exit appends an exit action for this state.
+// Exit actions are executed in the order they were added when exiting this state.
 func (sb *StateBuilder) Exit(action Action) *StateBuilder {
-	sb.state.ExitAction = action
+	sb.state.ExitActions = append(sb.state.ExitActions, action)
 	return sb
 }
 
-// InitialAction sets the initial action for this state.
-// The action will be executed when first entering this state.
+// InitialAction appends an initial action for this state.
+// Initial actions are executed in the order they were added when first entering this state.
 func (sb *StateBuilder) InitialAction(action Action) *StateBuilder {
-	sb.state.InitialAction = action
+	sb.state.InitialActions = append(sb.state.InitialActions, action)
 	return sb
 }
 
 // On adds a transition from this state to the target state when the given event occurs.
 // eventName is the string name of the event (will be prefixed with "event:" internally).
+// An empty eventName adds an eventless transition; "*", "error.communication" and
+// "done.state.<state>" refer to the special events.
 // targetName is the name of the target state.
 // guard is an optional guard condition (can be nil).
 // action is an optional transition action (can be nil).
 func (sb *StateBuilder) On(eventName string, targetName string, guard Guard, action Action) *StateBuilder {
 	// Namespace events with "event:" prefix
-	eventID := EventID(sb.b.assignID("event:" + eventName))
+	eventID := sb.b.eventID(eventName)
 	targetID := sb.b.assignID(targetName)
 
 	transition := &Transition{
@@ -272,10 +488,27 @@ func (sb *StateBuilder) On(eventName string, targetName string, guard Guard, act
 	return sb
 }
 
+// OnActions adds a transition like On, with an ordered list of transition actions.
+func (sb *StateBuilder) OnActions(eventName string, targetName string, guard Guard, actions ...Action) *StateBuilder {
+	eventID := sb.b.eventID(eventName)
+	targetID := sb.b.assignID(targetName)
+
+	transition := &Transition{
+		Event:   eventID,
+		Source:  sb.state,
+		Target:  targetID,
+		Guard:   guard,
+		Actions: actions,
+	}
+
+	sb.state.Transitions = append(sb.state.Transitions, transition)
+	return sb
+}
+
 // OnInternal adds an internal transition that doesn't change state.
 // The transition action executes but no exit/entry actions are triggered.
 func (sb *StateBuilder) OnInternal(eventName string, guard Guard, action Action) *StateBuilder {
-	eventID := EventID(sb.b.assignID("event:" + eventName))
+	eventID := sb.b.eventID(eventName)
 
 	transition := &Transition{
 		Event:  eventID,
@@ -288,3 +521,82 @@ func (sb *StateBuilder) OnInternal(eventName string, guard Guard, action Action)
 	sb.state.Transitions = append(sb.state.Transitions, transition)
 	return sb
 }
+
+// OnRef adds a transition like On, resolving the guard and actions by name from the
+// builder's registry. guardName may be empty for an unguarded transition.
+// Unknown names are reported by Build.
+func (sb *StateBuilder) OnRef(eventName string, targetName string, guardName string, actionNames ...string) *StateBuilder {
+	sb.OnActions(eventName, targetName, nil)
+	sb.resolveTransitionRefs(sb.state.Transitions[len(sb.state.Transitions)-1], guardName, actionNames)
+	return sb
+}
+
+// OnInternalRef adds an internal transition like OnInternal, resolving the guard and
+// actions by name from the builder's registry. Unknown names are reported by Build.
+func (sb *StateBuilder) OnInternalRef(eventName string, guardName string, actionNames ...string) *StateBuilder {
+	sb.OnInternal(eventName, nil, nil)
+	sb.resolveTransitionRefs(sb.state.Transitions[len(sb.state.Transitions)-1], guardName, actionNames)
+	return sb
+}
+
+// EntryRef appends entry actions resolved by name from the builder's registry.
+// Unknown names are reported by Build.
+func (sb *StateBuilder) EntryRef(actionNames ...string) *StateBuilder {
+	actions, err := sb.resolveActions(actionNames)
+	if err != nil {
+		return sb
+	}
+	sb.state.EntryActions = append(sb.state.EntryActions, actions...)
+	sb.state.EntryRefs = append(sb.state.EntryRefs, actionNames...)
+	return sb
+}
+
+// ExitRef appends exit actions resolved by name from the builder's registry.
+// Unknown names are reported by Build.
+func (sb *StateBuilder) ExitRef(actionNames ...string) *StateBuilder {
+	actions, err := sb.resolveActions(actionNames)
+	if err != nil {
+		return sb
+	}
+	sb.state.ExitActions = append(sb.state.ExitActions, actions...)
+	sb.state.ExitRefs = append(sb.state.ExitRefs, actionNames...)
+	return sb
+}
+
+// resolveTransitionRefs sets a transition's guard and actions from registry names
+func (sb *StateBuilder) resolveTransitionRefs(t *Transition, guardName string, actionNames []string) {
+	if guardName != "" {
+		if sb.b.registry == nil {
+			sb.b.errs = append(sb.b.errs, fmt.Errorf("state %s: guard %q referenced without a registry", sb.name, guardName))
+		} else if g, err := sb.b.registry.ResolveGuard(guardName); err != nil {
+			sb.b.errs = append(sb.b.errs, fmt.Errorf("state %s: %w", sb.name, err))
+		} else {
+			t.Guard = g
+			t.GuardRef = guardName
+		}
+	}
+
+	if actions, err := sb.resolveActions(actionNames); err == nil {
+		t.Actions = actions
+		t.ActionRefs = append([]string(nil), actionNames...)
+	}
+}
+
+// resolveActions looks up action names, recording any error on the builder
+func (sb *StateBuilder) resolveActions(actionNames []string) ([]Action, error) {
+	if len(actionNames) == 0 {
+		return nil, nil
+	}
+	if sb.b.registry == nil {
+		err := fmt.Errorf("state %s: actions %v referenced without a registry", sb.name, actionNames)
+		sb.b.errs = append(sb.b.errs, err)
+		return nil, err
+	}
+	actions, err := sb.b.registry.ResolveActions(actionNames...)
+	if err != nil {
+		err = fmt.Errorf("state %s: %w", sb.name, err)
+		sb.b.errs = append(sb.b.errs, err)
+		return nil, err
+	}
+	return actions, nil
+}
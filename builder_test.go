@@ -2,6 +2,9 @@ package statechartx_test
 
 import (
 	"context"
+	"fmt"
+	"strings"
+	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -267,6 +270,56 @@ func TestBuilderActions(t *testing.T) {
 	}
 }
 
+func TestBuilderActionLists(t *testing.T) {
+	b := NewMachineBuilder("app", "idle")
+
+	var mu sync.Mutex
+	var trace []string
+	record := func(name string) Action {
+		return func(ctx context.Context, evt *Event, from StateID, to StateID) error {
+			mu.Lock()
+			trace = append(trace, name)
+			mu.Unlock()
+			return nil
+		}
+	}
+
+	b.State("idle").
+		Entry(record("log:enter-idle")).
+		Entry(record("metrics:enter-idle")).
+		Exit(record("log:exit-idle")).
+		Exit(record("metrics:exit-idle")).
+		OnActions("go", "busy", nil, record("log:go"), record("domain:go"))
+	b.State("busy").Entry(record("log:enter-busy"))
+
+	machine, err := b.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rt := NewRuntime(machine, nil)
+	ctx := context.Background()
+	if err := rt.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	defer rt.Stop()
+
+	rt.SendEvent(ctx, Event{ID: b.EventID("go")})
+	time.Sleep(50 * time.Millisecond)
+
+	want := []string{
+		"log:enter-idle", "metrics:enter-idle",
+		"log:exit-idle", "metrics:exit-idle",
+		"log:go", "domain:go",
+		"log:enter-busy",
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if strings.Join(trace, ",") != strings.Join(want, ",") {
+		t.Errorf("expected %v, got %v", want, trace)
+	}
+}
+
 func TestBuilderGuards(t *testing.T) {
 	b := NewMachineBuilder("guarded", "start")
 
@@ -419,6 +472,37 @@ func TestBuilderGetNameReverseLookup(t *testing.T) {
 	}
 }
 
+func TestBuilderEventNameLookup(t *testing.T) {
+	b := NewMachineBuilder("app", "idle")
+	b.State("idle").On("start", "busy", nil, nil)
+	b.State("busy").Atomic()
+
+	id := b.EventID("start")
+	if id != EventID(b.GetID("event:start")) {
+		t.Errorf("EventID should match GetID(\"event:start\"), got %d", id)
+	}
+	if b.EventName(id) != "start" {
+		t.Errorf("EventName should return start, got %q", b.EventName(id))
+	}
+	if b.EventID("missing") != NO_EVENT {
+		t.Error("EventID for unknown name should return NO_EVENT")
+	}
+	if b.EventName(EventID(b.GetID("busy"))) != "" {
+		t.Error("EventName for a state ID should return empty string")
+	}
+
+	timer, err := TimerEventID(b.GetID("busy"), 30)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.EventID("after.busy.30") != timer || b.EventName(timer) != "after.busy.30" {
+		t.Errorf("timer event names should round-trip, got %d and %q", b.EventID("after.busy.30"), b.EventName(timer))
+	}
+	if name := EventNameOf(nil, timer); name != fmt.Sprintf("after.s%d.30", b.GetID("busy")) {
+		t.Errorf("EventNameOf should name timer events after.<state>.<count>, got %q", name)
+	}
+}
+
 func TestBuilderValidationMissingInitial(t *testing.T) {
 	b := NewMachineBuilder("app", "parent")
 	b.State("parent").Compound("child") // Declares initial but doesn't create child
@@ -0,0 +1,200 @@
+package conformance
+
+import (
+	"github.com/comalice/statechartx"
+)
+
+// cases100 are the W3C tests 100-199: event queue ordering and event matching.
+var cases100 = []Case{
+	{
+		ID:          "144",
+		Build:       build144,
+		Description: "should reach pass state",
+	},
+	{
+		ID:          "147",
+		Build:       build147,
+		Description: "should reach pass state",
+	},
+	{
+		ID:          "148",
+		Build:       build148,
+		Description: "should reach fail state (first event foo should match wildcard)",
+	},
+	{
+		ID:          "149",
+		Build:       build149,
+		Description: "should reach pass state (specific event should match before wildcard)",
+	},
+	{
+		ID:          "158",
+		Build:       build158,
+		Description: "should reach pass state (events processed in order)",
+	},
+}
+
+func build144(s *Session) (*statechartx.State, statechartx.StateID) {
+	const (
+		EVENT_FOO statechartx.EventID = 1
+		EVENT_BAR statechartx.EventID = 2
+
+		STATE_ROOT statechartx.StateID = 100
+		STATE_S0   statechartx.StateID = 101
+		STATE_S1   statechartx.StateID = 102
+		STATE_PASS statechartx.StateID = 200
+	)
+
+	root := &statechartx.State{ID: STATE_ROOT}
+	s0 := &statechartx.State{ID: STATE_S0, Parent: root}
+	s1 := &statechartx.State{ID: STATE_S1, Parent: root}
+	pass := &statechartx.State{ID: STATE_PASS, Parent: root}
+
+	root.Children = map[statechartx.StateID]*statechartx.State{
+		STATE_S0:   s0,
+		STATE_S1:   s1,
+		STATE_PASS: pass,
+	}
+	root.Initial = STATE_S0
+
+	s0.Transitions = []*statechartx.Transition{
+		{Event: EVENT_FOO, Target: STATE_S1},
+	}
+	s1.Transitions = []*statechartx.Transition{
+		{Event: EVENT_BAR, Target: STATE_PASS},
+	}
+
+	s0.OnEntry(raise(s, EVENT_FOO))
+	s1.OnEntry(raise(s, EVENT_BAR))
+	return root, STATE_PASS
+}
+
+func build147(s *Session) (*statechartx.State, statechartx.StateID) {
+	const (
+		EVENT_BAR statechartx.EventID = 2
+		EVENT_BAT statechartx.EventID = 4
+
+		STATE_ROOT statechartx.StateID = 100
+		STATE_S0   statechartx.StateID = 101
+		STATE_PASS statechartx.StateID = 200
+		STATE_FAIL statechartx.StateID = 201
+	)
+
+	root := &statechartx.State{ID: STATE_ROOT}
+	s0 := &statechartx.State{ID: STATE_S0, Parent: root}
+	pass := &statechartx.State{ID: STATE_PASS, Parent: root}
+	fail := &statechartx.State{ID: STATE_FAIL, Parent: root}
+
+	root.Children = map[statechartx.StateID]*statechartx.State{
+		STATE_S0:   s0,
+		STATE_PASS: pass,
+		STATE_FAIL: fail,
+	}
+	root.Initial = STATE_S0
+
+	s0.Transitions = []*statechartx.Transition{
+		{Event: EVENT_BAR, Target: STATE_PASS},
+		{Event: statechartx.ANY_EVENT, Target: STATE_FAIL},
+	}
+
+	s0.OnEntry(raise(s, EVENT_BAR, EVENT_BAT))
+	return root, STATE_PASS
+}
+
+func build148(s *Session) (*statechartx.State, statechartx.StateID) {
+	const (
+		EVENT_FOO statechartx.EventID = 1
+		EVENT_BAR statechartx.EventID = 2
+		EVENT_BAZ statechartx.EventID = 3
+
+		STATE_ROOT statechartx.StateID = 100
+		STATE_S0   statechartx.StateID = 101
+		STATE_PASS statechartx.StateID = 200
+		STATE_FAIL statechartx.StateID = 201
+	)
+
+	root := &statechartx.State{ID: STATE_ROOT}
+	s0 := &statechartx.State{ID: STATE_S0, Parent: root}
+	pass := &statechartx.State{ID: STATE_PASS, Parent: root}
+	fail := &statechartx.State{ID: STATE_FAIL, Parent: root}
+
+	root.Children = map[statechartx.StateID]*statechartx.State{
+		STATE_S0:   s0,
+		STATE_PASS: pass,
+		STATE_FAIL: fail,
+	}
+	root.Initial = STATE_S0
+
+	s0.Transitions = []*statechartx.Transition{
+		{Event: EVENT_BAZ, Target: STATE_PASS},
+		{Event: statechartx.ANY_EVENT, Target: STATE_FAIL},
+	}
+
+	s0.OnEntry(raise(s, EVENT_FOO, EVENT_BAR, EVENT_BAZ))
+	return root, STATE_FAIL
+}
+
+func build149(s *Session) (*statechartx.State, statechartx.StateID) {
+	const (
+		EVENT_FOO statechartx.EventID = 1
+
+		STATE_ROOT statechartx.StateID = 100
+		STATE_S0   statechartx.StateID = 101
+		STATE_PASS statechartx.StateID = 200
+		STATE_FAIL statechartx.StateID = 201
+	)
+
+	root := &statechartx.State{ID: STATE_ROOT}
+	s0 := &statechartx.State{ID: STATE_S0, Parent: root}
+	pass := &statechartx.State{ID: STATE_PASS, Parent: root}
+	fail := &statechartx.State{ID: STATE_FAIL, Parent: root}
+
+	root.Children = map[statechartx.StateID]*statechartx.State{
+		STATE_S0:   s0,
+		STATE_PASS: pass,
+		STATE_FAIL: fail,
+	}
+	root.Initial = STATE_S0
+
+	s0.Transitions = []*statechartx.Transition{
+		{Event: EVENT_FOO, Target: STATE_PASS},
+		{Event: statechartx.ANY_EVENT, Target: STATE_FAIL},
+	}
+
+	s0.OnEntry(raise(s, EVENT_FOO))
+	return root, STATE_PASS
+}
+
+func build158(s *Session) (*statechartx.State, statechartx.StateID) {
+	const (
+		EVENT_FOO statechartx.EventID = 1
+		EVENT_BAR statechartx.EventID = 2
+
+		STATE_ROOT statechartx.StateID = 100
+		STATE_S0   statechartx.StateID = 101
+		STATE_S1   statechartx.StateID = 102
+		STATE_PASS statechartx.StateID = 200
+	)
+
+	root := &statechartx.State{ID: STATE_ROOT}
+	s0 := &statechartx.State{ID: STATE_S0, Parent: root}
+	s1 := &statechartx.State{ID: STATE_S1, Parent: root}
+	pass := &statechartx.State{ID: STATE_PASS, Parent: root}
+
+	root.Children = map[statechartx.StateID]*statechartx.State{
+		STATE_S0:   s0,
+		STATE_S1:   s1,
+		STATE_PASS: pass,
+	}
+	root.Initial = STATE_S0
+
+	s0.Transitions = []*statechartx.Transition{
+		{Event: EVENT_FOO, Target: STATE_S1},
+	}
+	s1.Transitions = []*statechartx.Transition{
+		{Event: EVENT_BAR, Target: STATE_PASS},
+	}
+
+	// Queue multiple events
+	s0.OnEntry(raise(s, EVENT_FOO, EVENT_BAR))
+	return root, STATE_PASS
+}
@@ -0,0 +1,47 @@
+package conformance
+
+// cases200 are the W3C tests 200-299.
+var cases200 = []Case{
+	{ID: "200", Unsupported: "requires send type='scxml' default behavior"},
+	{ID: "201", Unsupported: "requires send type='http.request' (optional)"},
+	{ID: "205", Unsupported: "requires send with <param>, event.data validation"},
+	{ID: "207", Unsupported: "requires invoke delay/cancel, parent-child send"},
+	{ID: "208", Unsupported: "requires send delay/cancel with sendid"},
+	{ID: "210", Unsupported: "requires cancel with sendidexpr"},
+	{ID: "215", Unsupported: "requires invoke with typeexpr"},
+	{ID: "216", Unsupported: "requires invoke with srcexpr"},
+	{ID: "220", Unsupported: "requires invoke type='scxml'"},
+	{ID: "223", Unsupported: "requires invoke idlocation"},
+	{ID: "224", Unsupported: "requires invoke auto-ID format validation"},
+	{ID: "225", Unsupported: "requires unique invoke IDs"},
+	{ID: "226", Unsupported: "requires invoke param passing"},
+	{ID: "228", Unsupported: "requires invokeid in events"},
+	{ID: "229", Unsupported: "requires invoke autoforward"},
+	{ID: "230", Unsupported: "requires invoke autoforward, send #_parent, event fields (name/type/sendid/origin/origintype/invokeid/data)"},
+	{ID: "232", Unsupported: "requires invoke, send #_parent from child, done.invoke, delays"},
+	{ID: "233", Unsupported: "requires invoke finalize, datamodel assign from event.data, send params"},
+	{ID: "234", Unsupported: "requires parallel, multiple invoke, finalize, datamodel assign event.data"},
+	{ID: "235", Unsupported: "requires invoke id, done.invoke.{id}, delays"},
+	{ID: "236", Unsupported: "requires invoke onexit send #_parent before done.invoke, delays"},
+	{ID: "237", Unsupported: "requires invoke cancel on exit, no done.invoke"},
+	{ID: "239", Unsupported: "requires invoke src vs content, delays"},
+	{ID: "240", Unsupported: "requires invoke <param>, datamodel parent/child vars, send #_parent"},
+	{ID: "241", Unsupported: "requires invoke namelist+param, datamodel, send #_parent"},
+	{ID: "242", Unsupported: "requires invoke src/content consistency, delays"},
+	{ID: "243", Unsupported: "requires invoke <param>, datamodel, send #_parent"},
+	{ID: "244", Unsupported: "requires invoke namelist, datamodel parent/child, send #_parent"},
+	{ID: "245", Unsupported: "requires invoke namelist, conf:isBound, send #_parent"},
+	{ID: "247", Unsupported: "requires invoke, done.invoke, delays"},
+	{ID: "250", Unsupported: "requires invoke cancel, child onexit"},
+	{ID: "252", Unsupported: "requires invoke cancel on exit, child events ignored"},
+	{ID: "253", Unsupported: "requires invoke type=scxml, send #_parent/#_foo, event.origintype, datamodel assign"},
+	{ID: "276", Unsupported: "requires invoke param passing parent/child"},
+	{ID: "277", Unsupported: "requires datamodel illegal expr, error.execution"},
+	{ID: "278", Unsupported: "requires datamodel var scoping"},
+	{ID: "279", Unsupported: "requires datamodel early binding"},
+	{ID: "280", Unsupported: "requires datamodel late binding"},
+	{ID: "286", Unsupported: "requires datamodel assign invalid loc, error.execution"},
+	{ID: "287", Unsupported: "requires datamodel assign"},
+	{ID: "294", Unsupported: "requires donedata param/content, event.data"},
+	{ID: "298", Unsupported: "requires donedata invalid loc, error.execution, delays"},
+}
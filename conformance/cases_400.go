@@ -0,0 +1,526 @@
+package conformance
+
+import (
+	"context"
+
+	"github.com/comalice/statechartx"
+)
+
+// cases400 are the W3C tests 400-499: optimal enablement, exit/entry order in
+// parallel states, initial transition actions and transition selection.
+var cases400 = []Case{
+	{ID: "401", Unsupported: "requires error events, internal queue priority, assign to invalid location"},
+	{ID: "402", Unsupported: "requires error events, internal queue ordering, assign to invalid location"},
+	{ID: "403a", Build: build403a, Description: "should reach pass state"},
+	{ID: "403b", Unsupported: "requires parallel states and datamodel (optimally enabled set is a set)"},
+	{ID: "403c", Unsupported: "requires parallel states, preemption, and datamodel"},
+	{ID: "404", Build: build404, Description: "exit order should be: event1 (s01p2), event2 (s01p1), event3 (s01p), event4 (transition)", Trace: []statechartx.EventID{1, 2, 3, 4}},
+	{ID: "405", Build: build405, Description: "transition actions should run after exits: event1, event2, event3, event4", Trace: []statechartx.EventID{1, 2, 3, 4}},
+	{ID: "406", Build: build406, Description: "entry order should be: event1 (transition), event2 (s0p2), event3 (s01p21), event4 (s01p22)", Trace: []statechartx.EventID{1, 2, 3, 4}},
+	{ID: "407", Unsupported: "requires eventless transitions (Phase 3)"},
+	{ID: "409", Unsupported: "requires In() predicate in onexit handlers to check active states"},
+	{ID: "411", Unsupported: "requires In() predicate in onentry handlers to check active states"},
+	{ID: "412", Build: build412, Description: "initial transition action should execute after parent entry but before child entry"},
+	// Test that parallel states with multiple initial state specification
+	// This test requires support for specifying initial states in parallel regions
+	// which targets specific states within each region
+	{ID: "413", Unsupported: "requires support for multiple initial state specification in parallel regions (advanced feature)"},
+	{ID: "415", Unsupported: "manual test - requires final state halting behavior verification"},
+	{ID: "416", Unsupported: "requires final states and done.state.id event generation"},
+	{ID: "417", Unsupported: "requires parallel states, final states, and done.state.id events"},
+	{ID: "419", Build: build419, Description: "eventless transition should take precedence over event-driven transitions"},
+	{ID: "421", Build: build421, Description: "first transition with passing guard should win (document order)"},
+	{ID: "422", Unsupported: "requires invoke functionality and parent-child session communication"},
+	{ID: "423", Unsupported: "requires separate internal/external event queues (Phase 4)"},
+	{ID: "436", Unsupported: "requires parallel in() predicate"},
+	{ID: "444", Unsupported: "requires datamodel <data> expr"},
+	{ID: "445", Unsupported: "requires datamodel undefined"},
+	{ID: "446", Unsupported: "requires datamodel JSON/src"},
+	{ID: "448", Unsupported: "requires datamodel global parallel"},
+	{ID: "449", Unsupported: "requires datamodel truthy cond"},
+	{ID: "451", Unsupported: "requires parallel in()"},
+	{ID: "452", Unsupported: "requires datamodel assign substruct"},
+	{ID: "453", Unsupported: "requires datamodel function expr"},
+	{ID: "456", Unsupported: "requires <script> datamodel"},
+	{ID: "457", Unsupported: "requires foreach arrays error"},
+	{ID: "459", Unsupported: "requires foreach index order"},
+	{ID: "460", Unsupported: "requires foreach shallow copy"},
+	{ID: "487", Unsupported: "requires datamodel assign illegal expr error"},
+	{ID: "488", Unsupported: "requires donedata param error, done.state data"},
+	{ID: "495", Unsupported: "requires send type internal/external queue"},
+	{ID: "496", Unsupported: "requires send unreachable target error"},
+}
+
+func build403a(s *Session) (*statechartx.State, statechartx.StateID) {
+	// Optimal enablement: child transitions take precedence over parent,
+	// document order breaks ties, and parent transitions fire if no child match
+	const (
+		STATE_ROOT statechartx.StateID = 1
+		STATE_S0   statechartx.StateID = 2
+		STATE_S01  statechartx.StateID = 3
+		STATE_S02  statechartx.StateID = 4
+		STATE_PASS statechartx.StateID = 5
+		STATE_FAIL statechartx.StateID = 6
+		EVENT_1    statechartx.EventID = 1
+		EVENT_2    statechartx.EventID = 2
+	)
+
+	root := &statechartx.State{ID: STATE_ROOT}
+	s0 := &statechartx.State{ID: STATE_S0}
+	s01 := &statechartx.State{ID: STATE_S01}
+	s02 := &statechartx.State{ID: STATE_S02}
+	pass := &statechartx.State{ID: STATE_PASS}
+	fail := &statechartx.State{ID: STATE_FAIL}
+
+	root.Children = map[statechartx.StateID]*statechartx.State{
+		STATE_S0:   s0,
+		STATE_PASS: pass,
+		STATE_FAIL: fail,
+	}
+	root.Initial = STATE_S0
+
+	s0.Children = map[statechartx.StateID]*statechartx.State{
+		STATE_S01: s01,
+		STATE_S02: s02,
+	}
+	s0.Initial = STATE_S01
+
+	// Parent s0 transitions (should not fire if child matches)
+	s0.Transitions = []*statechartx.Transition{
+		{Event: EVENT_1, Target: STATE_FAIL},
+		{Event: EVENT_2, Target: STATE_PASS},
+	}
+
+	// s01 transitions: first one should fire (document order)
+	s01.Transitions = []*statechartx.Transition{
+		{Event: EVENT_1, Target: STATE_S02},
+		{Event: statechartx.ANY_EVENT, Target: STATE_FAIL},
+	}
+
+	// s02 has local transition with false guard, so parent should fire
+	s02.Transitions = []*statechartx.Transition{
+		{Event: EVENT_1, Target: STATE_FAIL},
+		{Event: EVENT_2, Target: STATE_FAIL, Guard: func(ctx context.Context, event *statechartx.Event, from, to statechartx.StateID) (bool, error) {
+			return false, nil // This guard fails, so transition shouldn't fire
+		}},
+	}
+
+	s01.EntryAction = raise(s, EVENT_1)
+	s02.EntryAction = raise(s, EVENT_2)
+	return root, STATE_PASS
+}
+
+func build404(s *Session) (*statechartx.State, statechartx.StateID) {
+	// States in parallel regions exit children before parents, siblings in
+	// reverse document order, and the transition action runs after the exits.
+	// Expected: event1 (s01p2 exit) -> event2 (s01p1 exit) -> event3 (s01p exit) -> event4 (transition action)
+	const (
+		STATE_ROOT  statechartx.StateID = 1
+		STATE_S0    statechartx.StateID = 2
+		STATE_S01P  statechartx.StateID = 3
+		STATE_S01P1 statechartx.StateID = 4
+		STATE_S01P2 statechartx.StateID = 5
+		STATE_S02   statechartx.StateID = 6
+		STATE_S03   statechartx.StateID = 7
+		STATE_S04   statechartx.StateID = 8
+		STATE_S05   statechartx.StateID = 9
+		STATE_PASS  statechartx.StateID = 10
+		STATE_FAIL  statechartx.StateID = 11
+
+		EVENT_EVENT1 statechartx.EventID = 1
+		EVENT_EVENT2 statechartx.EventID = 2
+		EVENT_EVENT3 statechartx.EventID = 3
+		EVENT_EVENT4 statechartx.EventID = 4
+	)
+
+	root := &statechartx.State{ID: STATE_ROOT}
+	s0 := &statechartx.State{ID: STATE_S0, Parent: root}
+	s01p := &statechartx.State{ID: STATE_S01P, Parent: s0, IsParallel: true}
+	s01p1 := &statechartx.State{ID: STATE_S01P1, Parent: s01p}
+	s01p2 := &statechartx.State{ID: STATE_S01P2, Parent: s01p}
+	s02 := &statechartx.State{ID: STATE_S02, Parent: s0}
+	s03 := &statechartx.State{ID: STATE_S03, Parent: s0}
+	s04 := &statechartx.State{ID: STATE_S04, Parent: s0}
+	s05 := &statechartx.State{ID: STATE_S05, Parent: s0}
+	pass := &statechartx.State{ID: STATE_PASS, IsFinal: true, Parent: root}
+	fail := &statechartx.State{ID: STATE_FAIL, IsFinal: true, Parent: root}
+
+	root.Children = map[statechartx.StateID]*statechartx.State{
+		STATE_S0:   s0,
+		STATE_PASS: pass,
+		STATE_FAIL: fail,
+	}
+	root.Initial = STATE_S0
+
+	s0.Children = map[statechartx.StateID]*statechartx.State{
+		STATE_S01P: s01p,
+		STATE_S02:  s02,
+		STATE_S03:  s03,
+		STATE_S04:  s04,
+		STATE_S05:  s05,
+	}
+	s0.Initial = STATE_S01P
+
+	s01p.Children = map[statechartx.StateID]*statechartx.State{
+		STATE_S01P1: s01p1,
+		STATE_S01P2: s01p2,
+	}
+
+	// Verification chain
+	s02.Transitions = []*statechartx.Transition{
+		{Event: EVENT_EVENT1, Target: STATE_S03},
+		{Event: statechartx.NO_EVENT, Target: STATE_FAIL},
+	}
+	s03.Transitions = []*statechartx.Transition{
+		{Event: EVENT_EVENT2, Target: STATE_S04},
+		{Event: statechartx.NO_EVENT, Target: STATE_FAIL},
+	}
+	s04.Transitions = []*statechartx.Transition{
+		{Event: EVENT_EVENT3, Target: STATE_S05},
+		{Event: statechartx.NO_EVENT, Target: STATE_FAIL},
+	}
+	s05.Transitions = []*statechartx.Transition{
+		{Event: EVENT_EVENT4, Target: STATE_PASS},
+		{Event: statechartx.NO_EVENT, Target: STATE_FAIL},
+	}
+
+	s01p2.ExitAction = raise(s, EVENT_EVENT1)
+	s01p1.ExitAction = raise(s, EVENT_EVENT2)
+	s01p.ExitAction = raise(s, EVENT_EVENT3)
+
+	// Transition from parallel state to s02, raising event4 in action
+	s01p.Transitions = []*statechartx.Transition{
+		{Event: statechartx.NO_EVENT, Target: STATE_S02, Action: raise(s, EVENT_EVENT4)},
+	}
+	return root, STATE_PASS
+}
+
+func build405(s *Session) (*statechartx.State, statechartx.StateID) {
+	// Transition actions of parallel regions run in document order after all
+	// exits. Expected: event1 (s01p21 exit) -> event2 (s01p11 exit) ->
+	// event3 (s01p11 -> s01p12 action) -> event4 (s01p21 -> s01p22 action)
+	const (
+		STATE_ROOT   statechartx.StateID = 1
+		STATE_S0     statechartx.StateID = 2
+		STATE_S01P   statechartx.StateID = 3
+		STATE_S01P1  statechartx.StateID = 4
+		STATE_S01P11 statechartx.StateID = 5
+		STATE_S01P12 statechartx.StateID = 6
+		STATE_S01P2  statechartx.StateID = 7
+		STATE_S01P21 statechartx.StateID = 8
+		STATE_S01P22 statechartx.StateID = 9
+		STATE_S02    statechartx.StateID = 10
+		STATE_S03    statechartx.StateID = 11
+		STATE_S04    statechartx.StateID = 12
+		STATE_PASS   statechartx.StateID = 13
+		STATE_FAIL   statechartx.StateID = 14
+
+		EVENT_EVENT1 statechartx.EventID = 1
+		EVENT_EVENT2 statechartx.EventID = 2
+		EVENT_EVENT3 statechartx.EventID = 3
+		EVENT_EVENT4 statechartx.EventID = 4
+	)
+
+	root := &statechartx.State{ID: STATE_ROOT}
+	s0 := &statechartx.State{ID: STATE_S0, Parent: root}
+	s01p := &statechartx.State{ID: STATE_S01P, Parent: s0, IsParallel: true}
+	s01p1 := &statechartx.State{ID: STATE_S01P1, Parent: s01p}
+	s01p11 := &statechartx.State{ID: STATE_S01P11, Parent: s01p1}
+	s01p12 := &statechartx.State{ID: STATE_S01P12, Parent: s01p1}
+	s01p2 := &statechartx.State{ID: STATE_S01P2, Parent: s01p}
+	s01p21 := &statechartx.State{ID: STATE_S01P21, Parent: s01p2}
+	s01p22 := &statechartx.State{ID: STATE_S01P22, Parent: s01p2}
+	s02 := &statechartx.State{ID: STATE_S02, Parent: s0}
+	s03 := &statechartx.State{ID: STATE_S03, Parent: s0}
+	s04 := &statechartx.State{ID: STATE_S04, Parent: s0}
+	pass := &statechartx.State{ID: STATE_PASS, IsFinal: true, Parent: root}
+	fail := &statechartx.State{ID: STATE_FAIL, IsFinal: true, Parent: root}
+
+	root.Children = map[statechartx.StateID]*statechartx.State{
+		STATE_S0:   s0,
+		STATE_PASS: pass,
+		STATE_FAIL: fail,
+	}
+	root.Initial = STATE_S0
+
+	s0.Children = map[statechartx.StateID]*statechartx.State{
+		STATE_S01P: s01p,
+		STATE_S02:  s02,
+		STATE_S03:  s03,
+		STATE_S04:  s04,
+	}
+	s0.Initial = STATE_S01P
+
+	s01p.Children = map[statechartx.StateID]*statechartx.State{
+		STATE_S01P1: s01p1,
+		STATE_S01P2: s01p2,
+	}
+
+	s01p1.Children = map[statechartx.StateID]*statechartx.State{
+		STATE_S01P11: s01p11,
+		STATE_S01P12: s01p12,
+	}
+	s01p1.Initial = STATE_S01P11
+
+	s01p2.Children = map[statechartx.StateID]*statechartx.State{
+		STATE_S01P21: s01p21,
+		STATE_S01P22: s01p22,
+	}
+	s01p2.Initial = STATE_S01P21
+
+	// Verification chain
+	s02.Transitions = []*statechartx.Transition{
+		{Event: EVENT_EVENT2, Target: STATE_S03},
+		{Event: statechartx.NO_EVENT, Target: STATE_FAIL},
+	}
+	s03.Transitions = []*statechartx.Transition{
+		{Event: EVENT_EVENT3, Target: STATE_S04},
+		{Event: statechartx.NO_EVENT, Target: STATE_FAIL},
+	}
+	s04.Transitions = []*statechartx.Transition{
+		{Event: EVENT_EVENT4, Target: STATE_PASS},
+		{Event: statechartx.NO_EVENT, Target: STATE_FAIL},
+	}
+
+	// Parallel state transition when event1 is received
+	s01p.Transitions = []*statechartx.Transition{
+		{Event: EVENT_EVENT1, Target: STATE_S02},
+	}
+
+	s01p11.ExitAction = raise(s, EVENT_EVENT2)
+	s01p21.ExitAction = raise(s, EVENT_EVENT1)
+
+	s01p11.Transitions = []*statechartx.Transition{
+		{Event: statechartx.NO_EVENT, Target: STATE_S01P12, Action: raise(s, EVENT_EVENT3)},
+	}
+	s01p21.Transitions = []*statechartx.Transition{
+		{Event: statechartx.NO_EVENT, Target: STATE_S01P22, Action: raise(s, EVENT_EVENT4)},
+	}
+	return root, STATE_PASS
+}
+
+func build406(s *Session) (*statechartx.State, statechartx.StateID) {
+	// States are entered parents before children, document order breaking ties.
+	// Expected: event1 (transition action) -> event2 (s0p2 entry) ->
+	// event3 (s01p21 entry) -> event4 (s01p22 entry)
+	const (
+		STATE_ROOT   statechartx.StateID = 1
+		STATE_S0     statechartx.StateID = 2
+		STATE_S01    statechartx.StateID = 3
+		STATE_S0P2   statechartx.StateID = 4
+		STATE_S01P21 statechartx.StateID = 5
+		STATE_S01P22 statechartx.StateID = 6
+		STATE_S03    statechartx.StateID = 7
+		STATE_S04    statechartx.StateID = 8
+		STATE_S05    statechartx.StateID = 9
+		STATE_PASS   statechartx.StateID = 10
+		STATE_FAIL   statechartx.StateID = 11
+
+		EVENT_EVENT1 statechartx.EventID = 1
+		EVENT_EVENT2 statechartx.EventID = 2
+		EVENT_EVENT3 statechartx.EventID = 3
+		EVENT_EVENT4 statechartx.EventID = 4
+	)
+
+	root := &statechartx.State{ID: STATE_ROOT}
+	s0 := &statechartx.State{ID: STATE_S0, Parent: root}
+	s01 := &statechartx.State{ID: STATE_S01, Parent: s0}
+	s0p2 := &statechartx.State{ID: STATE_S0P2, Parent: s0, IsParallel: true}
+	s01p21 := &statechartx.State{ID: STATE_S01P21, Parent: s0p2}
+	s01p22 := &statechartx.State{ID: STATE_S01P22, Parent: s0p2}
+	s03 := &statechartx.State{ID: STATE_S03, Parent: s0}
+	s04 := &statechartx.State{ID: STATE_S04, Parent: s0}
+	s05 := &statechartx.State{ID: STATE_S05, Parent: s0}
+	pass := &statechartx.State{ID: STATE_PASS, IsFinal: true, Parent: root}
+	fail := &statechartx.State{ID: STATE_FAIL, IsFinal: true, Parent: root}
+
+	root.Children = map[statechartx.StateID]*statechartx.State{
+		STATE_S0:   s0,
+		STATE_PASS: pass,
+		STATE_FAIL: fail,
+	}
+	root.Initial = STATE_S0
+
+	s0.Children = map[statechartx.StateID]*statechartx.State{
+		STATE_S01:  s01,
+		STATE_S0P2: s0p2,
+		STATE_S03:  s03,
+		STATE_S04:  s04,
+		STATE_S05:  s05,
+	}
+	s0.Initial = STATE_S01
+
+	s0p2.Children = map[statechartx.StateID]*statechartx.State{
+		STATE_S01P21: s01p21,
+		STATE_S01P22: s01p22,
+	}
+
+	// Verification chain
+	s03.Transitions = []*statechartx.Transition{
+		{Event: EVENT_EVENT2, Target: STATE_S04},
+		{Event: statechartx.NO_EVENT, Target: STATE_FAIL},
+	}
+	s04.Transitions = []*statechartx.Transition{
+		{Event: EVENT_EVENT3, Target: STATE_S05},
+		{Event: statechartx.NO_EVENT, Target: STATE_FAIL},
+	}
+	s05.Transitions = []*statechartx.Transition{
+		{Event: EVENT_EVENT4, Target: STATE_PASS},
+		{Event: statechartx.NO_EVENT, Target: STATE_FAIL},
+	}
+
+	// Transition from parallel state when event1 is received
+	s0p2.Transitions = []*statechartx.Transition{
+		{Event: EVENT_EVENT1, Target: STATE_S03},
+	}
+
+	s01.Transitions = []*statechartx.Transition{
+		{Event: statechartx.NO_EVENT, Target: STATE_S0P2, Action: raise(s, EVENT_EVENT1)},
+	}
+	s0p2.EntryAction = raise(s, EVENT_EVENT2)
+	s01p21.EntryAction = raise(s, EVENT_EVENT3)
+	s01p22.EntryAction = raise(s, EVENT_EVENT4)
+	return root, STATE_PASS
+}
+
+func build412(s *Session) (*statechartx.State, statechartx.StateID) {
+	// Initial transition actions run after the parent's onentry and before
+	// the child's: parent OnEntry → initial transition action → child OnEntry
+	const (
+		STATE_ROOT statechartx.StateID = 1
+		STATE_S1   statechartx.StateID = 2
+		STATE_S11  statechartx.StateID = 3
+		STATE_PASS statechartx.StateID = 4
+		STATE_FAIL statechartx.StateID = 5
+	)
+
+	root := &statechartx.State{ID: STATE_ROOT}
+	s1 := &statechartx.State{ID: STATE_S1}
+	s11 := &statechartx.State{ID: STATE_S11}
+	pass := &statechartx.State{ID: STATE_PASS, IsFinal: true}
+	fail := &statechartx.State{ID: STATE_FAIL, IsFinal: true}
+
+	root.Children = map[statechartx.StateID]*statechartx.State{
+		STATE_S1:   s1,
+		STATE_PASS: pass,
+		STATE_FAIL: fail,
+	}
+	root.Initial = STATE_S1
+
+	s1.Children = map[statechartx.StateID]*statechartx.State{
+		STATE_S11: s11,
+	}
+	s1.Initial = STATE_S11
+
+	var executionOrder []string
+	record := func(step string) statechartx.Action {
+		return func(ctx context.Context, event *statechartx.Event, from, to statechartx.StateID) error {
+			executionOrder = append(executionOrder, step)
+			return nil
+		}
+	}
+	s1.EntryAction = record("s1_entry")
+	s1.InitialAction = record("s1_initial_action")
+	s11.EntryAction = record("s11_entry")
+
+	// s11 checks the execution order with an eventless transition
+	s11.Transitions = []*statechartx.Transition{
+		{
+			Event:  statechartx.NO_EVENT,
+			Target: STATE_PASS,
+			Guard: func(ctx context.Context, event *statechartx.Event, from, to statechartx.StateID) (bool, error) {
+				expected := []string{"s1_entry", "s1_initial_action", "s11_entry"}
+				if len(executionOrder) != len(expected) {
+					return false, nil
+				}
+				for i, v := range expected {
+					if executionOrder[i] != v {
+						return false, nil
+					}
+				}
+				return true, nil
+			},
+		},
+		{Event: statechartx.NO_EVENT, Target: STATE_FAIL}, // Fallback if order is wrong
+	}
+	return root, STATE_PASS
+}
+
+func build419(s *Session) (*statechartx.State, statechartx.StateID) {
+	// Eventless transitions take precedence over event-driven ones
+	const (
+		STATE_ROOT           statechartx.StateID = 1
+		STATE_S1             statechartx.StateID = 2
+		STATE_PASS           statechartx.StateID = 3
+		STATE_FAIL           statechartx.StateID = 4
+		EVENT_INTERNAL_EVENT statechartx.EventID = 1
+		EVENT_EXTERNAL_EVENT statechartx.EventID = 2
+	)
+
+	root := &statechartx.State{ID: STATE_ROOT}
+	s1 := &statechartx.State{ID: STATE_S1}
+	pass := &statechartx.State{ID: STATE_PASS, Final: true}
+	fail := &statechartx.State{ID: STATE_FAIL, Final: true}
+
+	root.Children = map[statechartx.StateID]*statechartx.State{
+		STATE_S1:   s1,
+		STATE_PASS: pass,
+		STATE_FAIL: fail,
+	}
+	root.Initial = STATE_S1
+
+	// s1 onentry raises internal and external events
+	s1.EntryAction = raise(s, EVENT_INTERNAL_EVENT, EVENT_EXTERNAL_EVENT)
+	s1.Transitions = []*statechartx.Transition{
+		{Event: statechartx.ANY_EVENT, Target: STATE_FAIL}, // Event-driven (wildcard)
+		{Event: statechartx.NO_EVENT, Target: STATE_PASS},  // Eventless (should take precedence)
+	}
+	return root, STATE_PASS
+}
+
+func build421(s *Session) (*statechartx.State, statechartx.StateID) {
+	// The first transition in document order whose guard passes wins
+	const (
+		STATE_ROOT statechartx.StateID = 1
+		STATE_S0   statechartx.StateID = 2
+		STATE_S1   statechartx.StateID = 3
+		STATE_S2   statechartx.StateID = 4
+		STATE_PASS statechartx.StateID = 5
+		STATE_FAIL statechartx.StateID = 6
+		EVENT_E1   statechartx.EventID = 1
+	)
+
+	root := &statechartx.State{ID: STATE_ROOT}
+	s0 := &statechartx.State{ID: STATE_S0}
+	s1 := &statechartx.State{ID: STATE_S1}
+	s2 := &statechartx.State{ID: STATE_S2}
+	pass := &statechartx.State{ID: STATE_PASS, IsFinal: true}
+	fail := &statechartx.State{ID: STATE_FAIL, IsFinal: true}
+
+	root.Children = map[statechartx.StateID]*statechartx.State{
+		STATE_S0:   s0,
+		STATE_S1:   s1,
+		STATE_S2:   s2,
+		STATE_PASS: pass,
+		STATE_FAIL: fail,
+	}
+	root.Initial = STATE_S0
+
+	s0.Transitions = []*statechartx.Transition{
+		{
+			Event:  EVENT_E1,
+			Target: STATE_FAIL,
+			Guard: func(ctx context.Context, event *statechartx.Event, from, to statechartx.StateID) (bool, error) {
+				return false, nil // Guard fails, should try next
+			},
+		},
+		{Event: EVENT_E1, Target: STATE_S1},   // This should fire (first with passing guard)
+		{Event: EVENT_E1, Target: STATE_FAIL}, // Should not fire (comes after)
+	}
+	s1.Transitions = []*statechartx.Transition{
+		{Event: statechartx.NO_EVENT, Target: STATE_PASS}, // Immediate transition to pass
+	}
+
+	s0.EntryAction = raise(s, EVENT_E1)
+	return root, STATE_PASS
+}
@@ -0,0 +1,45 @@
+package conformance
+
+// cases500 are the W3C tests 500-599.
+var cases500 = []Case{
+	{ID: "500", Unsupported: "requires datamodel (scxmlEventIOLocation variable)"},
+	{ID: "501", Unsupported: "requires send with targetVar, delays, external event queues"},
+	{ID: "503", Unsupported: "requires internal transitions and eventless transitions (Phase 3)"},
+	{ID: "504", Unsupported: "requires parallel states (not supported)"},
+	{ID: "505", Unsupported: "requires self-transitions and eventless transitions (Phase 3)"},
+	{ID: "506", Unsupported: "requires compound state self-transitions and eventless transitions (Phase 3)"},
+	{ID: "509", Unsupported: "requires datamodel (variables, expr), <donedata> with content"},
+	{ID: "510", Unsupported: "requires datamodel (variables, expr), <donedata> with param"},
+	{ID: "518", Unsupported: "requires datamodel (variables, assign)"},
+	{ID: "519", Unsupported: "requires datamodel (assign), error.execution event for invalid location"},
+	{ID: "520", Unsupported: "requires datamodel (assign with expr)"},
+	{ID: "521", Unsupported: "requires datamodel (assign), illegal assignment detection, error.execution events"},
+	{ID: "522", Unsupported: "requires datamodel (variables, assign), invalid expr detection, error.execution events"},
+	{ID: "525", Unsupported: "requires datamodel (variables, _event.name, assign)"},
+	{ID: "527", Unsupported: "requires datamodel (variables, _event.data access)"},
+	{ID: "528", Unsupported: "requires datamodel (variables, _event.sendid access), send with idlocation"},
+	{ID: "529", Unsupported: "requires datamodel (variables, _event.origin access), send mechanics"},
+	{ID: "530", Unsupported: "requires datamodel (variables, _event.origintype access), send type detection"},
+	{ID: "531", Unsupported: "requires datamodel (variables, _event.invokeid access), invoke mechanics"},
+	{ID: "532", Unsupported: "requires datamodel (variables, _event.type access), internal/external/platform event type detection"},
+	{ID: "533", Unsupported: "requires datamodel (variables, assign), _event variable overwrite protection"},
+	{ID: "534", Unsupported: "requires datamodel (variables, assign), _sessionid variable, overwrite protection"},
+	{ID: "550", Unsupported: "requires datamodel (variables, expr, idVal guards), early binding"},
+	{ID: "551", Unsupported: "requires datamodel (variables, expr, idVal guards), late binding"},
+	{ID: "552", Unsupported: "requires datamodel (variables, assign, expr)"},
+	{ID: "553", Unsupported: "requires datamodel (variables, cond guards with expressions)"},
+	{ID: "554", Unsupported: "requires datamodel (variables, In() predicate function)"},
+	{ID: "557", Unsupported: "requires datamodel (variables, _name system variable)"},
+	{ID: "558", Unsupported: "requires invoke with type, src, idlocation, autoforward"},
+	{ID: "560", Unsupported: "requires invoke, done.invoke events"},
+	{ID: "561", Unsupported: "requires invoke with srcexpr, datamodel (variables)"},
+	{ID: "562", Unsupported: "requires invoke with src attribute"},
+	{ID: "567", Unsupported: "requires invoke, error.execution event for invalid src"},
+	{ID: "569", Unsupported: "requires invoke, done.invoke events with event data"},
+	{ID: "570", Unsupported: "requires invoke, <finalize> block for processing done.invoke data"},
+	{ID: "576", Unsupported: "requires parallel states, initial attribute with multiple states"},
+	{ID: "577", Unsupported: "requires parallel states with multiple child states, entry order"},
+	{ID: "578", Unsupported: "requires parallel states, exit order verification"},
+	{ID: "579", Unsupported: "requires parallel states, initial attribute, simultaneous child activation"},
+	{ID: "580", Unsupported: "requires parallel states, transitions from parallel regions"},
+}
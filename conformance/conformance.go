@@ -0,0 +1,172 @@
+// Package conformance is a runtime-agnostic kit of W3C SCXML conformance tests.
+//
+// Every test from the W3C IRP suite that StatechartX tracks is a Case: either a
+// builder for the translated chart plus the state the runtime must reach, or a
+// note explaining which unsupported feature (datamodel, invoke, ...) it needs.
+// Run executes all cases against a runtime created through a testutil.RuntimeAdapter
+// factory, so the event-driven runtime, the realtime runtime and third-party
+// runtimes are held to the same suite:
+//
+//	func TestSCXMLConformance(t *testing.T) {
+//		conformance.Run(t, func(m *statechartx.Machine) testutil.RuntimeAdapter {
+//			return myruntime.NewAdapter(m)
+//		}, conformance.Options{
+//			KnownFailures: map[string]string{"404": "parallel exit order"},
+//		})
+//	}
+//
+// Known failures are still executed. A failing known failure is reported as
+// skipped with its reason; one that passes is logged so the list can be pruned.
+package conformance
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/comalice/statechartx"
+	"github.com/comalice/statechartx/testutil"
+)
+
+// DefaultTimeout is how long Run waits for a case's runtime to become idle.
+const DefaultTimeout = time.Second
+
+// Factory creates the runtime under test for a machine.
+type Factory func(machine *statechartx.Machine) testutil.RuntimeAdapter
+
+// Case is one W3C SCXML test translated to StatechartX.
+type Case struct {
+	// ID is the W3C test number, e.g. "144" or "403a".
+	ID string
+	// Unsupported explains why the test cannot be expressed yet. Such cases
+	// have no Build and are always skipped.
+	Unsupported string
+	// Build constructs the test chart and returns its root and the state the
+	// runtime must reach (usually pass). Actions raise events through s, which
+	// is bound to the runtime under test before it starts.
+	Build func(s *Session) (root *statechartx.State, expect statechartx.StateID)
+	// Description explains what the case checks; it is included in failures.
+	Description string
+	// Trace, if set, is the order in which the case's actions must raise
+	// events. Tests of exit and entry order use it so that a runtime reaching
+	// the expected state by another route still fails.
+	Trace []statechartx.EventID
+}
+
+// Session gives case actions access to the runtime under test.
+type Session struct {
+	adapter testutil.RuntimeAdapter
+
+	mu     sync.Mutex
+	raised []statechartx.EventID
+}
+
+// Raise sends event id to the runtime, as <raise> or <send> would in SCXML.
+func (s *Session) Raise(id statechartx.EventID) {
+	s.mu.Lock()
+	s.raised = append(s.raised, id)
+	s.mu.Unlock()
+	_ = s.adapter.SendEvent(statechartx.Event{ID: id})
+}
+
+// Raised returns the events raised through s so far, in order.
+func (s *Session) Raised() []statechartx.EventID {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]statechartx.EventID(nil), s.raised...)
+}
+
+// Options configures Run.
+type Options struct {
+	// KnownFailures maps case IDs to the reason the runtime fails them.
+	KnownFailures map[string]string
+	// Timeout bounds how long each case may take to become idle.
+	// Zero means DefaultTimeout.
+	Timeout time.Duration
+}
+
+// Run executes every case as a subtest named after its ID on a fresh runtime
+// from newRuntime.
+func Run(t *testing.T, newRuntime Factory, opts Options) {
+	t.Helper()
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+
+	for _, c := range Cases() {
+		c := c
+		t.Run(c.ID, func(t *testing.T) {
+			if c.Unsupported != "" {
+				t.Skipf("SCXML%s: %s", c.ID, c.Unsupported)
+			}
+			err := c.Run(newRuntime, timeout)
+			reason, known := opts.KnownFailures[c.ID]
+			switch {
+			case err != nil && known:
+				t.Skipf("SCXML%s: known failure (%s): %v", c.ID, reason, err)
+			case err != nil:
+				t.Errorf("SCXML%s: %v", c.ID, err)
+			case known:
+				t.Logf("SCXML%s passes but is listed as a known failure (%s)", c.ID, reason)
+			}
+		})
+	}
+}
+
+// Run builds the case on a runtime from newRuntime, waits up to timeout for it
+// to become idle and checks the expected state and, if set, the trace. The
+// runtime is stopped before Run returns.
+func (c Case) Run(newRuntime Factory, timeout time.Duration) error {
+	if c.Build == nil {
+		return fmt.Errorf("unsupported: %s", c.Unsupported)
+	}
+
+	s := &Session{}
+	root, expect := c.Build(s)
+	machine, err := statechartx.NewMachine(root)
+	if err != nil {
+		return fmt.Errorf("failed to create machine: %w", err)
+	}
+	s.adapter = newRuntime(machine)
+
+	if err := s.adapter.Start(context.Background()); err != nil {
+		return fmt.Errorf("failed to start runtime: %w", err)
+	}
+	defer s.adapter.Stop()
+
+	if err := s.adapter.WaitForStability(timeout); err != nil {
+		return err
+	}
+	if !s.adapter.IsInState(expect) {
+		return fmt.Errorf("%s: expected state %d, got %d", c.Description, expect, s.adapter.GetCurrentState())
+	}
+	if c.Trace != nil {
+		if raised := s.Raised(); !reflect.DeepEqual(raised, c.Trace) {
+			return fmt.Errorf("%s: expected events raised in order %v, got %v", c.Description, c.Trace, raised)
+		}
+	}
+	return nil
+}
+
+// Cases returns all cases ordered by test number.
+func Cases() []Case {
+	var all []Case
+	for _, group := range [][]Case{cases100, cases200, cases300, cases400, cases500} {
+		all = append(all, group...)
+	}
+	return all
+}
+
+// raise returns an action that raises events in order through s.
+func raise(s *Session, events ...statechartx.EventID) statechartx.Action {
+	return func(ctx context.Context, evt *statechartx.Event, from, to statechartx.StateID) error {
+		for _, id := range events {
+			s.Raise(id)
+		}
+		return nil
+	}
+}
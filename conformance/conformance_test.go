@@ -0,0 +1,65 @@
+package conformance_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/comalice/statechartx"
+	"github.com/comalice/statechartx/conformance"
+	"github.com/comalice/statechartx/testutil"
+)
+
+func TestCases(t *testing.T) {
+	seen := map[string]bool{}
+	supported := 0
+	for _, c := range conformance.Cases() {
+		if seen[c.ID] {
+			t.Errorf("duplicate case %s", c.ID)
+		}
+		seen[c.ID] = true
+
+		if (c.Build == nil) == (c.Unsupported == "") {
+			t.Errorf("case %s must have exactly one of Build and Unsupported", c.ID)
+		}
+		if c.Build != nil {
+			supported++
+		}
+	}
+	if len(seen) != 175 {
+		t.Errorf("expected 175 cases, got %d", len(seen))
+	}
+	if supported != 16 {
+		t.Errorf("expected 16 supported cases, got %d", supported)
+	}
+}
+
+func TestTrace(t *testing.T) {
+	const (
+		EVENT_A statechartx.EventID = 1
+		EVENT_B statechartx.EventID = 2
+	)
+	build := func(s *conformance.Session) (*statechartx.State, statechartx.StateID) {
+		root := &statechartx.State{ID: 1}
+		idle := &statechartx.State{ID: 2, Parent: root}
+		root.Children = map[statechartx.StateID]*statechartx.State{2: idle}
+		root.Initial = 2
+		idle.EntryAction = func(ctx context.Context, evt *statechartx.Event, from, to statechartx.StateID) error {
+			s.Raise(EVENT_A)
+			s.Raise(EVENT_B)
+			return nil
+		}
+		return root, 2
+	}
+	newRuntime := func(m *statechartx.Machine) testutil.RuntimeAdapter {
+		return testutil.NewEventDrivenAdapter(m)
+	}
+
+	ok := conformance.Case{ID: "t", Build: build, Trace: []statechartx.EventID{EVENT_A, EVENT_B}}
+	if err := ok.Run(newRuntime, conformance.DefaultTimeout); err != nil {
+		t.Errorf("expected matching trace to pass, got %v", err)
+	}
+	reordered := conformance.Case{ID: "t", Build: build, Trace: []statechartx.EventID{EVENT_B, EVENT_A}}
+	if err := reordered.Run(newRuntime, conformance.DefaultTimeout); err == nil {
+		t.Error("expected reordered trace to fail")
+	}
+}
@@ -0,0 +1,76 @@
+package statechartx
+
+import "context"
+
+// CoverageRecorder receives execution events from a Runtime so tests can measure
+// which parts of a chart were exercised. Set Runtime.Coverage before Start; the
+// realtime runtime reports through the same field. Methods may be called
+// concurrently from parallel regions. See package coverage for a collector.
+type CoverageRecorder interface {
+	// StateEntered is called before a state's entry actions run.
+	StateEntered(state *State)
+
+	// TransitionTaken is called before a transition's actions run.
+	TransitionTaken(t *Transition)
+
+	// GuardEvaluated is called after a guard is evaluated (errors count as false).
+	GuardEvaluated(t *Transition, result bool)
+
+	// HistoryRestored is called when a transition targets a history state.
+	// recorded is false when the default (or parent initial) state was used.
+	HistoryRestored(history *State, recorded bool)
+}
+
+// runEntryActions records the entry and runs the state's entry actions
+func (rt *Runtime) runEntryActions(ctx context.Context, state *State, evt *Event, from, to StateID) error {
+	if rt.Coverage != nil {
+		rt.Coverage.StateEntered(state)
+	}
+	if rt.ParallelHooks != nil && rt.ParallelHooks.OnStateEntered != nil {
+		rt.ParallelHooks.OnStateEntered(state)
+	}
+	return state.RunEntryActions(ctx, evt, from, to)
+}
+
+// runTransitionActions records the transition and runs its actions
+func (rt *Runtime) runTransitionActions(ctx context.Context, t *Transition, evt *Event, from, to StateID) error {
+	if rt.Coverage != nil {
+		rt.Coverage.TransitionTaken(t)
+	}
+	if rt.ParallelHooks != nil && rt.ParallelHooks.OnTransitionTaken != nil {
+		rt.ParallelHooks.OnTransitionTaken(t)
+	}
+	return t.RunActions(ctx, evt, from, to)
+}
+
+// checkGuard evaluates the transition's guard (true if it has none)
+func (rt *Runtime) checkGuard(t *Transition, evt *Event) bool {
+	if t.Guard == nil {
+		return true
+	}
+	pass, err := t.Guard(rt.ctx, evt, rt.current, t.Target)
+	pass = pass && err == nil
+	if rt.Coverage != nil {
+		rt.Coverage.GuardEvaluated(t, pass)
+	}
+	return pass
+}
+
+// coverHistory reports a history restoration
+func (rt *Runtime) coverHistory(historyState *State) {
+	if rt.Coverage == nil || historyState.Parent == nil {
+		return
+	}
+	parentID := historyState.Parent.ID
+	var recorded bool
+	if historyState.HistoryType == HistoryDeep {
+		rt.deepHistoryMu.RLock()
+		recorded = len(rt.deepHistory[parentID]) > 0
+		rt.deepHistoryMu.RUnlock()
+	} else {
+		rt.historyMu.RLock()
+		recorded = rt.history[parentID] != 0
+		rt.historyMu.RUnlock()
+	}
+	rt.Coverage.HistoryRestored(historyState, recorded)
+}
@@ -0,0 +1,183 @@
+// Package coverage measures which parts of a statechart a test run exercised.
+//
+// A Collector implements statechartx.CoverageRecorder. Attach it to a runtime
+// (event-driven or realtime) before Start:
+//
+//	c := coverage.New(machine)
+//	rt := statechartx.NewRuntime(machine, nil)
+//	rt.Coverage = c
+//
+// It counts state entries, transitions taken, guard outcomes (true and false per
+// guarded transition) and history restorations from recorded history. Collected
+// counts are a Profile keyed by state ID and transition position, so profiles from
+// different tests (and different builds of the same chart) can be merged, saved as
+// JSON and turned into a Report:
+//
+//	total := coverage.Profile{}
+//	total.Merge(c.Profile())
+//	report := coverage.NewReport(machine, b, &total)
+//	report.WriteText(os.Stdout)
+package coverage
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/comalice/statechartx"
+)
+
+// TransitionKey identifies a transition by its source state and its index in the
+// source's Transitions slice, which is stable across builds of the same chart.
+type TransitionKey struct {
+	Source statechartx.StateID
+	Index  int
+}
+
+// String returns "source/index".
+func (k TransitionKey) String() string {
+	return fmt.Sprintf("%d/%d", k.Source, k.Index)
+}
+
+// MarshalText encodes the key as "source/index" (used for JSON map keys).
+func (k TransitionKey) MarshalText() ([]byte, error) {
+	return []byte(k.String()), nil
+}
+
+// UnmarshalText decodes a key written by MarshalText.
+func (k *TransitionKey) UnmarshalText(text []byte) error {
+	if _, err := fmt.Sscanf(string(text), "%d/%d", &k.Source, &k.Index); err != nil {
+		return fmt.Errorf("invalid transition key %q", text)
+	}
+	return nil
+}
+
+// Profile holds execution counts. The zero value is an empty profile.
+type Profile struct {
+	States      map[statechartx.StateID]int `json:"states,omitempty"`      // entries per state
+	Transitions map[TransitionKey]int       `json:"transitions,omitempty"` // times taken
+	GuardTrue   map[TransitionKey]int       `json:"guardTrue,omitempty"`   // guard evaluated true
+	GuardFalse  map[TransitionKey]int       `json:"guardFalse,omitempty"`  // guard evaluated false (or failed)
+	History     map[statechartx.StateID]int `json:"history,omitempty"`     // restorations from recorded history
+	Defaults    map[statechartx.StateID]int `json:"defaults,omitempty"`    // history targeted with nothing recorded
+}
+
+// Merge adds other's counts to p.
+func (p *Profile) Merge(other *Profile) {
+	p.States = mergeCounts(p.States, other.States)
+	p.Transitions = mergeCounts(p.Transitions, other.Transitions)
+	p.GuardTrue = mergeCounts(p.GuardTrue, other.GuardTrue)
+	p.GuardFalse = mergeCounts(p.GuardFalse, other.GuardFalse)
+	p.History = mergeCounts(p.History, other.History)
+	p.Defaults = mergeCounts(p.Defaults, other.Defaults)
+}
+
+// Write encodes p as JSON.
+func (p *Profile) Write(w io.Writer) error {
+	return json.NewEncoder(w).Encode(p)
+}
+
+// ReadProfile decodes a profile written by Profile.Write.
+func ReadProfile(r io.Reader) (*Profile, error) {
+	var p Profile
+	if err := json.NewDecoder(r).Decode(&p); err != nil {
+		return nil, err
+	}
+	return &p, nil
+}
+
+func mergeCounts[K comparable](dst, src map[K]int) map[K]int {
+	if len(src) == 0 {
+		return dst
+	}
+	if dst == nil {
+		dst = make(map[K]int, len(src))
+	}
+	for k, n := range src {
+		dst[k] += n
+	}
+	return dst
+}
+
+func increment[K comparable](m *map[K]int, k K) {
+	if *m == nil {
+		*m = make(map[K]int)
+	}
+	(*m)[k]++
+}
+
+// Collector records coverage for one machine. It is safe for concurrent use and
+// may be shared by several runtimes of the same machine.
+type Collector struct {
+	mu      sync.Mutex
+	keys    map[*statechartx.Transition]TransitionKey
+	profile Profile
+}
+
+// New returns a collector for m.
+func New(m *statechartx.Machine) *Collector {
+	c := &Collector{keys: make(map[*statechartx.Transition]TransitionKey)}
+	for _, id := range m.StateIDs() {
+		for i, t := range m.GetState(id).Transitions {
+			c.keys[t] = TransitionKey{Source: id, Index: i}
+		}
+	}
+	return c
+}
+
+// StateEntered implements statechartx.CoverageRecorder.
+func (c *Collector) StateEntered(state *statechartx.State) {
+	c.mu.Lock()
+	increment(&c.profile.States, state.ID)
+	c.mu.Unlock()
+}
+
+// TransitionTaken implements statechartx.CoverageRecorder.
+func (c *Collector) TransitionTaken(t *statechartx.Transition) {
+	c.mu.Lock()
+	if k, ok := c.keys[t]; ok {
+		increment(&c.profile.Transitions, k)
+	}
+	c.mu.Unlock()
+}
+
+// GuardEvaluated implements statechartx.CoverageRecorder.
+func (c *Collector) GuardEvaluated(t *statechartx.Transition, result bool) {
+	c.mu.Lock()
+	if k, ok := c.keys[t]; ok {
+		if result {
+			increment(&c.profile.GuardTrue, k)
+		} else {
+			increment(&c.profile.GuardFalse, k)
+		}
+	}
+	c.mu.Unlock()
+}
+
+// HistoryRestored implements statechartx.CoverageRecorder.
+func (c *Collector) HistoryRestored(history *statechartx.State, recorded bool) {
+	c.mu.Lock()
+	if recorded {
+		increment(&c.profile.History, history.ID)
+	} else {
+		increment(&c.profile.Defaults, history.ID)
+	}
+	c.mu.Unlock()
+}
+
+// Profile returns a copy of the counts collected so far.
+func (c *Collector) Profile() *Profile {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	p := &Profile{}
+	p.Merge(&c.profile)
+	return p
+}
+
+// Reset discards the counts collected so far.
+func (c *Collector) Reset() {
+	c.mu.Lock()
+	c.profile = Profile{}
+	c.mu.Unlock()
+}
@@ -0,0 +1,199 @@
+package coverage_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/comalice/statechartx"
+	"github.com/comalice/statechartx/coverage"
+	"github.com/comalice/statechartx/realtime"
+)
+
+func newDevice(ready *atomic.Bool) *statechartx.MachineBuilder {
+	reg := statechartx.NewRegistry()
+	reg.RegisterGuard("ready", func(ctx context.Context, evt *statechartx.Event, from, to statechartx.StateID) (bool, error) {
+		return ready.Load(), nil
+	})
+	b := statechartx.NewMachineBuilder("device", "off").WithRegistry(reg)
+	b.State("off").On("power", "on", nil, nil).On("resume", "on.hist", nil, nil)
+	b.State("on").Compound("on.idle").On("power", "off", nil, nil)
+	b.State("on.hist").History(statechartx.HistoryShallow, "")
+	b.State("on.idle").OnRef("work", "on.busy", "ready")
+	b.State("on.busy").On("fail", "broken", nil, nil)
+	b.State("broken").Atomic()
+	return b
+}
+
+var session = []string{"power", "work", "work", "power", "resume"}
+
+// play sends the session's events, making the guard pass from the second "work" on
+func play(t *testing.T, ready *atomic.Bool, send func(name string), wait time.Duration) {
+	t.Helper()
+	ready.Store(false)
+	for i, name := range session {
+		if i == 2 {
+			ready.Store(true)
+		}
+		send(name)
+		time.Sleep(wait)
+	}
+}
+
+func TestEventDrivenRuntime(t *testing.T) {
+	var ready atomic.Bool
+	b := newDevice(&ready)
+	machine, err := b.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := coverage.New(machine)
+
+	rt := statechartx.NewRuntime(machine, nil)
+	rt.Coverage = c
+	ctx := context.Background()
+	if err := rt.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	play(t, &ready, func(name string) {
+		rt.SendEvent(ctx, statechartx.Event{ID: b.EventID(name)})
+	}, 20*time.Millisecond)
+	rt.Stop()
+
+	if !rt.IsInState(b.GetID("on.busy")) {
+		t.Fatalf("expected history to restore on.busy, got %s", b.GetName(rt.GetCurrentState()))
+	}
+
+	p := c.Profile()
+	tests := []struct {
+		name string
+		got  int
+		want int
+	}{
+		{"off entries", p.States[b.GetID("off")], 2},
+		{"on entries", p.States[b.GetID("on")], 2},
+		{"on.busy entries", p.States[b.GetID("on.busy")], 2},
+		{"broken entries", p.States[b.GetID("broken")], 0},
+		{"history restorations", p.History[b.GetID("on.hist")], 1},
+		{"history defaults", p.Defaults[b.GetID("on.hist")], 0},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s: expected %d, got %d", tt.name, tt.want, tt.got)
+		}
+	}
+
+	work := coverage.TransitionKey{Source: b.GetID("on.idle"), Index: 0}
+	if p.GuardTrue[work] != 1 || p.GuardFalse[work] != 1 || p.Transitions[work] != 1 {
+		t.Errorf("expected guard true 1, false 1, taken 1; got %d, %d, %d",
+			p.GuardTrue[work], p.GuardFalse[work], p.Transitions[work])
+	}
+
+	report := coverage.NewReport(machine, b, p)
+	if report.States.Total != 6 || report.States.Covered != 5 {
+		t.Errorf("expected states 5/6, got %s", report.States)
+	}
+	if report.Guards.Covered != 2 || report.History.Covered != 1 {
+		t.Errorf("expected all guard outcomes and history covered, got %s and %s", report.Guards, report.History)
+	}
+	uncovered := strings.Join(report.Uncovered(), "\n")
+	for _, want := range []string{"state broken never entered", "transition on.busy: fail -> broken never taken"} {
+		if !strings.Contains(uncovered, want) {
+			t.Errorf("expected uncovered to contain %q, got\n%s", want, uncovered)
+		}
+	}
+}
+
+func TestRealtimeRuntime(t *testing.T) {
+	var ready atomic.Bool
+	b := newDevice(&ready)
+	machine, err := b.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := coverage.New(machine)
+
+	rt := realtime.NewRuntime(machine, realtime.Config{TickRate: 5 * time.Millisecond})
+	rt.Coverage = c
+	if err := rt.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	play(t, &ready, func(name string) {
+		rt.SendEvent(statechartx.Event{ID: b.EventID(name)})
+	}, 20*time.Millisecond)
+	rt.Stop()
+
+	p := c.Profile()
+	if p.States[b.GetID("on.busy")] != 2 || p.History[b.GetID("on.hist")] != 1 {
+		t.Errorf("expected on.busy entered twice and one history restoration, got %d and %d",
+			p.States[b.GetID("on.busy")], p.History[b.GetID("on.hist")])
+	}
+}
+
+func TestMergeAndSave(t *testing.T) {
+	var ready atomic.Bool
+	b := newDevice(&ready)
+	first, _ := b.Build()
+	second, _ := newDevice(&ready).Build()
+
+	// Collectors for separate builds of the same chart merge by position
+	c1, c2 := coverage.New(first), coverage.New(second)
+	c1.TransitionTaken(first.GetState(b.GetID("on.busy")).Transitions[0])
+	c2.TransitionTaken(second.GetState(b.GetID("on.busy")).Transitions[0])
+	c2.StateEntered(second.GetState(b.GetID("broken")))
+
+	var total coverage.Profile
+	total.Merge(c1.Profile())
+	total.Merge(c2.Profile())
+
+	var buf bytes.Buffer
+	if err := total.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := coverage.ReadProfile(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fail := coverage.TransitionKey{Source: b.GetID("on.busy"), Index: 0}
+	if loaded.Transitions[fail] != 2 || loaded.States[b.GetID("broken")] != 1 {
+		t.Errorf("expected merged counts 2 and 1, got %d and %d", loaded.Transitions[fail], loaded.States[b.GetID("broken")])
+	}
+
+	c1.Reset()
+	if len(c1.Profile().Transitions) != 0 {
+		t.Error("expected Reset to clear counts")
+	}
+}
+
+func TestReportOutput(t *testing.T) {
+	var ready atomic.Bool
+	b := newDevice(&ready)
+	machine, _ := b.Build()
+	c := coverage.New(machine)
+	c.StateEntered(machine.Root())
+	c.StateEntered(machine.GetState(b.GetID("off")))
+	report := coverage.NewReport(machine, b, c.Profile())
+
+	var text bytes.Buffer
+	if err := report.WriteText(&text); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"coverage of device", "states", "2/6 (33.3%)", "power -> on", "work -> on.busy [ready]", "uncovered:", "history on.hist never restored"} {
+		if !strings.Contains(text.String(), want) {
+			t.Errorf("expected text report to contain %q\n%s", want, text.String())
+		}
+	}
+
+	var html bytes.Buffer
+	if err := report.WriteHTML(&html); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"<title>Coverage of device</title>", `class="miss"`, "work -&gt; on.busy [ready]", "padding-left: 1.5em"} {
+		if !strings.Contains(html.String(), want) {
+			t.Errorf("expected HTML report to contain %q\n%s", want, html.String())
+		}
+	}
+}
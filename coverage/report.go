@@ -0,0 +1,275 @@
+package coverage
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/comalice/statechartx"
+)
+
+// Summary counts covered items out of the total.
+type Summary struct {
+	Covered int
+	Total   int
+}
+
+// Percent returns the covered percentage (100 when there is nothing to cover).
+func (s Summary) Percent() float64 {
+	if s.Total == 0 {
+		return 100
+	}
+	return 100 * float64(s.Covered) / float64(s.Total)
+}
+
+func (s Summary) String() string {
+	return fmt.Sprintf("%d/%d (%.1f%%)", s.Covered, s.Total, s.Percent())
+}
+
+// TransitionLine is the coverage of one transition.
+type TransitionLine struct {
+	Key     TransitionKey
+	Label   string // "event -> target [guard]"
+	Taken   int
+	Guarded bool
+	True    int // guard evaluations that passed
+	False   int // guard evaluations that failed
+}
+
+// StateLine is the coverage of one state and its transitions. For history states
+// Entries counts restorations from recorded history and Defaults the times the
+// default was used instead.
+type StateLine struct {
+	ID          statechartx.StateID
+	Name        string
+	Kind        string // "state", "parallel", "final" or "history"
+	Depth       int
+	Entries     int
+	Defaults    int
+	Transitions []TransitionLine
+}
+
+// Report is a profile laid out over a machine's states in document order.
+type Report struct {
+	Machine     string
+	States      Summary // states entered at least once (history states excluded)
+	Transitions Summary // transitions taken at least once
+	Guards      Summary // guard outcomes seen (two per guarded transition)
+	History     Summary // history states restored from recorded history
+	Lines       []StateLine
+}
+
+// NewReport lays p out over m, naming states, events and guards with names
+// (typically the *statechartx.MachineBuilder that built m; nil uses IDs).
+func NewReport(m *statechartx.Machine, names statechartx.Namer, p *Profile) *Report {
+	r := &Report{Machine: statechartx.StateNameOf(names, m.Root().ID)}
+	r.walk(m.Root(), 0, names, p)
+	return r
+}
+
+func (r *Report) walk(s *statechartx.State, depth int, names statechartx.Namer, p *Profile) {
+	line := StateLine{ID: s.ID, Name: statechartx.StateNameOf(names, s.ID), Kind: "state", Depth: depth}
+	switch {
+	case s.IsHistoryState:
+		line.Kind = "history"
+		line.Entries = p.History[s.ID]
+		line.Defaults = p.Defaults[s.ID]
+		r.History.Total++
+		if line.Entries > 0 {
+			r.History.Covered++
+		}
+	default:
+		if s.IsParallel {
+			line.Kind = "parallel"
+		} else if s.IsFinal || s.Final {
+			line.Kind = "final"
+		}
+		line.Entries = p.States[s.ID]
+		r.States.Total++
+		if line.Entries > 0 {
+			r.States.Covered++
+		}
+	}
+
+	for i, t := range s.Transitions {
+		if t == nil {
+			continue
+		}
+		k := TransitionKey{Source: s.ID, Index: i}
+		tl := TransitionLine{Key: k, Label: transitionLabel(t, names), Taken: p.Transitions[k], Guarded: t.Guard != nil}
+		r.Transitions.Total++
+		if tl.Taken > 0 {
+			r.Transitions.Covered++
+		}
+		if tl.Guarded {
+			tl.True, tl.False = p.GuardTrue[k], p.GuardFalse[k]
+			r.Guards.Total += 2
+			if tl.True > 0 {
+				r.Guards.Covered++
+			}
+			if tl.False > 0 {
+				r.Guards.Covered++
+			}
+		}
+		line.Transitions = append(line.Transitions, tl)
+	}
+	r.Lines = append(r.Lines, line)
+
+	ids := make([]statechartx.StateID, 0, len(s.Children))
+	for id := range s.Children {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	for _, id := range ids {
+		r.walk(s.Children[id], depth+1, names, p)
+	}
+}
+
+// transitionLabel formats a transition as "event -> target [guard]"
+func transitionLabel(t *statechartx.Transition, names statechartx.Namer) string {
+	event := "(eventless)"
+	if t.Event != statechartx.NO_EVENT {
+		event = statechartx.EventNameOf(names, t.Event)
+	}
+	target := "(internal)"
+	if t.Target != 0 {
+		target = statechartx.StateNameOf(names, t.Target)
+	}
+	label := event + " -> " + target
+	if t.Guard != nil {
+		guard := t.GuardRef
+		if guard == "" {
+			guard = "guard"
+		}
+		label += " [" + guard + "]"
+	}
+	return label
+}
+
+// Uncovered lists the states, transitions, guard outcomes and history states
+// that were never exercised, in document order.
+func (r *Report) Uncovered() []string {
+	var out []string
+	for _, l := range r.Lines {
+		if l.Entries == 0 {
+			if l.Kind == "history" {
+				out = append(out, "history "+l.Name+" never restored")
+			} else {
+				out = append(out, "state "+l.Name+" never entered")
+			}
+		}
+		for _, t := range l.Transitions {
+			if t.Taken == 0 {
+				out = append(out, fmt.Sprintf("transition %s: %s never taken", l.Name, t.Label))
+			}
+			if t.Guarded && t.True == 0 {
+				out = append(out, fmt.Sprintf("guard %s: %s never true", l.Name, t.Label))
+			}
+			if t.Guarded && t.False == 0 {
+				out = append(out, fmt.Sprintf("guard %s: %s never false", l.Name, t.Label))
+			}
+		}
+	}
+	return out
+}
+
+// WriteText writes a plain-text report: the summary, the annotated state tree and
+// the uncovered items.
+func (r *Report) WriteText(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(tw, "coverage of %s\n", r.Machine)
+	fmt.Fprintf(tw, "  states\t%s\n", r.States)
+	fmt.Fprintf(tw, "  transitions\t%s\n", r.Transitions)
+	fmt.Fprintf(tw, "  guard outcomes\t%s\n", r.Guards)
+	fmt.Fprintf(tw, "  history\t%s\n", r.History)
+	fmt.Fprintln(tw)
+
+	for _, l := range r.Lines {
+		indent := strings.Repeat("  ", l.Depth)
+		if l.Kind == "history" {
+			fmt.Fprintf(tw, "%s%s\trestored %d, default %d\n", indent, l.Name, l.Entries, l.Defaults)
+		} else {
+			fmt.Fprintf(tw, "%s%s\tentered %d\n", indent, l.Name, l.Entries)
+		}
+		for _, t := range l.Transitions {
+			counts := fmt.Sprintf("taken %d", t.Taken)
+			if t.Guarded {
+				counts += fmt.Sprintf(", guard true %d false %d", t.True, t.False)
+			}
+			fmt.Fprintf(tw, "%s  %s\t%s\n", indent, t.Label, counts)
+		}
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	uncovered := r.Uncovered()
+	if len(uncovered) == 0 {
+		return nil
+	}
+	if _, err := fmt.Fprintln(w, "\nuncovered:"); err != nil {
+		return err
+	}
+	for _, u := range uncovered {
+		if _, err := fmt.Fprintf(w, "  %s\n", u); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteHTML writes a self-contained HTML report with uncovered items highlighted.
+func (r *Report) WriteHTML(w io.Writer) error {
+	return htmlReport.Execute(w, r)
+}
+
+var htmlReport = template.Must(template.New("coverage").Funcs(template.FuncMap{
+	"indent": func(depth int) string { return fmt.Sprintf("%.1fem", 1.5*float64(depth)) },
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Coverage of {{.Machine}}</title>
+<style>
+body { font-family: sans-serif; }
+table { border-collapse: collapse; }
+td, th { padding: 2px 12px; text-align: left; }
+.hit { background: #dff0d8; }
+.miss { background: #f2dede; }
+.kind { color: #777; }
+</style>
+</head>
+<body>
+<h1>Coverage of {{.Machine}}</h1>
+<table>
+<tr><th>states</th><td>{{.States}}</td></tr>
+<tr><th>transitions</th><td>{{.Transitions}}</td></tr>
+<tr><th>guard outcomes</th><td>{{.Guards}}</td></tr>
+<tr><th>history</th><td>{{.History}}</td></tr>
+</table>
+<h2>States and transitions</h2>
+<table>
+<tr><th>element</th><th>count</th><th>guard true</th><th>guard false</th></tr>
+{{- range .Lines}}
+<tr class="{{if .Entries}}hit{{else}}miss{{end}}"><td style="padding-left: {{indent .Depth}}">{{.Name}} <span class="kind">{{.Kind}}</span></td><td>{{.Entries}}</td><td></td><td></td></tr>
+{{- $depth := .Depth}}
+{{- range .Transitions}}
+<tr class="{{if .Taken}}hit{{else}}miss{{end}}"><td style="padding-left: {{indent $depth}}">&nbsp;&nbsp;{{.Label}}</td><td>{{.Taken}}</td>
+{{- if .Guarded}}<td class="{{if .True}}hit{{else}}miss{{end}}">{{.True}}</td><td class="{{if .False}}hit{{else}}miss{{end}}">{{.False}}</td>{{else}}<td></td><td></td>{{end}}</tr>
+{{- end}}
+{{- end}}
+</table>
+{{- with .Uncovered}}
+<h2>Uncovered</h2>
+<ul>
+{{- range .}}
+<li>{{.}}</li>
+{{- end}}
+</ul>
+{{- end}}
+</body>
+</html>
+`))
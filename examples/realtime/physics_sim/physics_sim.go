@@ -23,8 +23,11 @@ const (
 	EVENT_STOP      statechartx.EventID = 2
 	EVENT_COLLISION statechartx.EventID = 3
 	EVENT_RESET     statechartx.EventID = 4
+	EVENT_STEP      statechartx.EventID = 5 // sent by OnTickStart every tick
 )
 
+const wallPosition = 50.0
+
 type PhysicsState struct {
 	Position float64
 	Velocity float64
@@ -65,6 +68,21 @@ func createPhysicsStateMachine() (*statechartx.Machine, *PhysicsState) {
 				Event:  EVENT_COLLISION,
 				Target: STATE_COLLIDED,
 			},
+			{
+				// Internal transition: integrate one tick of motion
+				Event: EVENT_STEP,
+				Action: func(ctx context.Context, evt *statechartx.Event, from, to statechartx.StateID) error {
+					info, _ := realtime.TickFromContext(ctx)
+					dt := info.Delta.Seconds()
+					physicsState.Time += dt
+					physicsState.Position += physicsState.Velocity * dt
+					if info.Tick%500 == 0 {
+						fmt.Printf("Tick %d: Position = %.2f, Velocity = %.2f\n",
+							info.Tick, physicsState.Position, physicsState.Velocity)
+					}
+					return nil
+				},
+			},
 		},
 	}
 	stateRunning.OnEntry(func(ctx context.Context, evt *statechartx.Event, from, to statechartx.StateID) error {
@@ -112,11 +130,24 @@ func main() {
 	fmt.Println("=== 1000 Hz Physics Simulation Example ===")
 	fmt.Println("Simulating object movement with collision detection")
 
-	// Create tick-based runtime (1000 Hz = 1ms per tick)
+	// Create tick-based runtime (1000 Hz = 1ms per tick). At the start of every
+	// tick, before the state machine runs, feed it either a collision or a
+	// physics step; the step's action integrates using the tick delta.
 	machine, physicsState := createPhysicsStateMachine()
-	rt := realtime.NewRuntime(machine, realtime.Config{
+	var rt *realtime.RealtimeRuntime
+	rt = realtime.NewRuntime(machine, realtime.Config{
 		TickRate:         1 * time.Millisecond, // 1000 Hz
 		MaxEventsPerTick: 10,
+		OnTickStart: func(tick uint64) {
+			if !rt.IsInState(STATE_RUNNING) {
+				return
+			}
+			if physicsState.Position >= wallPosition {
+				rt.SendEvent(statechartx.Event{ID: EVENT_COLLISION})
+				return
+			}
+			rt.SendEvent(statechartx.Event{ID: EVENT_STEP})
+		},
 	})
 
 	// Start runtime
@@ -131,34 +162,12 @@ func main() {
 	rt.SendEvent(statechartx.Event{ID: EVENT_START})
 	fmt.Printf("Tick %d: Started simulation\n\n", rt.GetTickNumber())
 
-	// Simulate physics updates every tick
-	const dt = 0.001 // 1ms = 0.001 seconds
-	const wallPosition = 50.0
-
-	// Run simulation for 100ms
-	ticker := time.NewTicker(10 * time.Millisecond)
-	defer ticker.Stop()
-
-	for i := 0; i < 10; i++ {
-		<-ticker.C
-
-		if rt.GetCurrentState() == STATE_RUNNING {
-			// Update physics (simplified Euler integration)
-			physicsState.Time += dt * 10 // Approximate for 10ms
-			physicsState.Position += physicsState.Velocity * dt * 10
-
-			fmt.Printf("Tick %d: Position = %.2f, Velocity = %.2f\n",
-				rt.GetTickNumber(), physicsState.Position, physicsState.Velocity)
-
-			// Check for collision
-			if physicsState.Position >= wallPosition {
-				rt.SendEvent(statechartx.Event{ID: EVENT_COLLISION})
-			}
-		}
+	// Wait for the collision (50 units at 10 units/s)
+	deadline := time.Now().Add(10 * time.Second)
+	for !rt.IsInState(STATE_COLLIDED) && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
 	}
 
-	time.Sleep(100 * time.Millisecond)
-
 	fmt.Printf("\nFinal tick: %d\n", rt.GetTickNumber())
 	fmt.Printf("Final position: %.2f\n", physicsState.Position)
 	fmt.Printf("Simulation time: %.3f seconds\n", physicsState.Time)
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"time"
@@ -10,7 +11,7 @@ import (
 )
 
 // Example: Deterministic Replay
-// Record events with sequence numbers, then replay them to get identical results
+// Record a session to a replay file, then play it back tick for tick
 
 const (
 	// States
@@ -25,12 +26,6 @@ const (
 	EVENT_C_TO_A statechartx.EventID = 3
 )
 
-// EventRecord records events for replay
-type EventRecord struct {
-	TickNumber uint64
-	Event      statechartx.Event
-}
-
 func createReplayStateMachine() *statechartx.Machine {
 	stateA := &statechartx.State{
 		ID: STATE_A,
@@ -83,7 +78,7 @@ func createReplayStateMachine() *statechartx.Machine {
 	return machine
 }
 
-func recordRun() []EventRecord {
+func recordRun() []byte {
 	fmt.Println("\n=== Recording Session ===")
 
 	machine := createReplayStateMachine()
@@ -92,53 +87,46 @@ func recordRun() []EventRecord {
 		MaxEventsPerTick: 100,
 	})
 
+	// Record every tick's events, with a configuration checkpoint each tick
+	var file bytes.Buffer
+	recorder, err := rt.Record(&file, realtime.RecorderOptions{})
+	if err != nil {
+		panic(err)
+	}
+
 	ctx := context.Background()
 	if err := rt.Start(ctx); err != nil {
 		panic(err)
 	}
 	defer rt.Stop()
 
-	var recording []EventRecord
-
-	// Record event 1
-	time.Sleep(20 * time.Millisecond)
-	event1 := statechartx.Event{ID: EVENT_A_TO_B}
-	rt.SendEvent(event1)
-	ticksnapshot1 := rt.GetTickNumber()
-	recording = append(recording, EventRecord{TickNumber: ticksnapshot1, Event: event1})
-	fmt.Printf("Recorded: Tick %d, Event %d\n", ticksnapshot1, event1.ID)
-
-	// Record event 2
-	time.Sleep(30 * time.Millisecond)
-	event2 := statechartx.Event{ID: EVENT_B_TO_C}
-	rt.SendEvent(event2)
-	ticksnapshot2 := rt.GetTickNumber()
-	recording = append(recording, EventRecord{TickNumber: ticksnapshot2, Event: event2})
-	fmt.Printf("Recorded: Tick %d, Event %d\n", ticksnapshot2, event2.ID)
-
-	// Record event 3
-	time.Sleep(25 * time.Millisecond)
-	event3 := statechartx.Event{ID: EVENT_C_TO_A}
-	rt.SendEvent(event3)
-	ticksnapshot3 := rt.GetTickNumber()
-	recording = append(recording, EventRecord{TickNumber: ticksnapshot3, Event: event3})
-	fmt.Printf("Recorded: Tick %d, Event %d\n", ticksnapshot3, event3.ID)
-
+	for _, id := range []statechartx.EventID{EVENT_A_TO_B, EVENT_B_TO_C, EVENT_C_TO_A} {
+		time.Sleep(25 * time.Millisecond)
+		rt.SendEvent(statechartx.Event{ID: id})
+		fmt.Printf("Sent event %d before tick %d\n", id, rt.GetTickNumber())
+	}
 	time.Sleep(20 * time.Millisecond)
 
-	finalState := rt.GetCurrentState()
-	fmt.Printf("Final state: %d\n", finalState)
-
-	return recording
+	if err := recorder.Close(); err != nil {
+		panic(err)
+	}
+	fmt.Printf("Final state: %d\n", rt.GetCurrentState())
+	return file.Bytes()
 }
 
-func replayRun(recording []EventRecord) {
+func replayRun(file []byte) {
 	fmt.Println("\n=== Replay Session ===")
 
+	player, err := realtime.NewPlayer(bytes.NewReader(file), nil)
+	if err != nil {
+		panic(err)
+	}
+
+	// The player steps the runtime itself, so no ticker is needed
 	machine := createReplayStateMachine()
 	rt := realtime.NewRuntime(machine, realtime.Config{
-		TickRate:         10 * time.Millisecond,
-		MaxEventsPerTick: 100,
+		TickRate: player.Header().TickRate,
+		Manual:   true,
 	})
 
 	ctx := context.Background()
@@ -147,23 +135,45 @@ func replayRun(recording []EventRecord) {
 	}
 	defer rt.Stop()
 
-	// Replay events at the same tick numbers
-	for _, record := range recording {
-		// Wait until we reach the recorded tick
-		for rt.GetTickNumber() < record.TickNumber {
-			time.Sleep(1 * time.Millisecond)
-		}
+	// Play fails if a tick ends in a different configuration than recorded
+	if err := player.Play(rt); err != nil {
+		panic(err)
+	}
+	fmt.Printf("Replayed %d ticks\n", rt.GetTickNumber())
+	fmt.Printf("Final state: %d\n", rt.GetCurrentState())
+}
+
+func rollbackRun() {
+	fmt.Println("\n=== Rollback Session ===")
+
+	machine := createReplayStateMachine()
+	rt := realtime.NewRuntime(machine, realtime.Config{
+		Manual:          true, // step ticks by hand, as a netcode loop would
+		SnapshotHistory: 16,
+	})
 
-		// Send event
-		rt.SendEvent(record.Event)
-		fmt.Printf("Replayed: Tick %d, Event %d\n", rt.GetTickNumber(), record.Event.ID)
+	ctx := context.Background()
+	if err := rt.Start(ctx); err != nil {
+		panic(err)
 	}
+	defer rt.Stop()
 
-	// Wait for final event to process
-	time.Sleep(20 * time.Millisecond)
+	rt.SendEvent(statechartx.Event{ID: EVENT_A_TO_B})
+	rt.StepN(5)
+	fmt.Printf("Tick %d: state %d\n", rt.GetTickNumber(), rt.GetCurrentState())
 
-	finalState := rt.GetCurrentState()
-	fmt.Printf("Final state: %d\n", finalState)
+	// A remote event stamped with tick 2 arrives late
+	if err := rt.InsertEventAt(2, statechartx.Event{ID: EVENT_B_TO_C}); err != nil {
+		panic(err)
+	}
+	if err := rt.Rollback(2); err != nil {
+		panic(err)
+	}
+	fmt.Printf("Rolled back to tick %d: state %d\n", rt.GetTickNumber(), rt.GetCurrentState())
+	if err := rt.Resimulate(); err != nil {
+		panic(err)
+	}
+	fmt.Printf("Re-simulated to tick %d: state %d\n", rt.GetTickNumber(), rt.GetCurrentState())
 }
 
 func main() {
@@ -177,6 +187,9 @@ func main() {
 	// Replay the session
 	replayRun(recording)
 
+	// Correct the past with a late event
+	rollbackRun()
+
 	fmt.Println("\n=== Replay Complete ===")
 	fmt.Println("Both runs should have identical state transitions!")
 }
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/comalice/statechartx"
+	"github.com/comalice/statechartx/realtime"
+)
+
+// counter is extended state: how often STATE_B was entered
+type counter struct{ visits int }
+
+func (c *counter) Snapshot() any        { return c.visits }
+func (c *counter) Restore(snapshot any) { c.visits = snapshot.(int) }
+
+// startPeer starts a manually stepped runtime of the replay machine that
+// snapshots and hashes every tick, with c as its extended state
+func startPeer(t *testing.T, c *counter) *realtime.RealtimeRuntime {
+	t.Helper()
+	machine := createReplayStateMachine()
+	b := machine.GetState(STATE_B)
+	b.EntryActions = append(b.EntryActions, func(ctx context.Context, evt *statechartx.Event, from, to statechartx.StateID) error {
+		c.visits++
+		return nil
+	})
+
+	rt := realtime.NewRuntime(machine, realtime.Config{
+		Manual:          true,
+		SnapshotHistory: 32,
+		HashHistory:     32,
+		Cloner:          c,
+		HashExtendedState: func(w io.Writer) {
+			fmt.Fprintf(w, "visits=%d", c.visits)
+		},
+	})
+	if err := rt.Start(context.Background()); err != nil {
+		t.Fatalf("Failed to start runtime: %v", err)
+	}
+	return rt
+}
+
+// runTicks steps rt to tick n, sending each scheduled event before its tick
+func runTicks(rt *realtime.RealtimeRuntime, schedule map[uint64]statechartx.EventID, n uint64) {
+	for rt.GetTickNumber() < n {
+		if id, ok := schedule[rt.GetTickNumber()]; ok {
+			rt.SendEvent(statechartx.Event{ID: id})
+		}
+		rt.Step()
+	}
+}
+
+// TestRollbackMatchesReplay tests that a peer receiving an event late, then
+// rolling back and re-simulating, ends up tick for tick identical to a peer
+// that received every event on time
+func TestRollbackMatchesReplay(t *testing.T) {
+	schedule := map[uint64]statechartx.EventID{
+		2: EVENT_A_TO_B,
+		5: EVENT_B_TO_C,
+		8: EVENT_C_TO_A,
+	}
+
+	refState := &counter{}
+	ref := startPeer(t, refState)
+	defer ref.Stop()
+	runTicks(ref, schedule, 12)
+
+	// The late peer misses tick 5's event, so tick 8's event is ignored in B
+	lateState := &counter{}
+	late := startPeer(t, lateState)
+	defer late.Stop()
+	runTicks(late, map[uint64]statechartx.EventID{2: EVENT_A_TO_B, 8: EVENT_C_TO_A}, 12)
+	d := realtime.FindDivergence(ref.TickRecords(), late.TickRecords())
+	if d == nil || d.Tick != 5 {
+		t.Fatalf("expected divergence at tick 5 before rollback, got %v", d)
+	}
+
+	// An event sent for the present survives the rollback
+	ref.SendEvent(statechartx.Event{ID: EVENT_A_TO_B})
+	late.SendEvent(statechartx.Event{ID: EVENT_A_TO_B})
+
+	if err := late.InsertEventAt(5, statechartx.Event{ID: EVENT_B_TO_C}); err != nil {
+		t.Fatalf("InsertEventAt failed: %v", err)
+	}
+	if err := late.Rollback(5); err != nil {
+		t.Fatalf("Rollback failed: %v", err)
+	}
+	if late.GetTickNumber() != 5 || late.GetCurrentState() != STATE_B {
+		t.Fatalf("expected tick 5 in STATE_B after rollback, got tick %d in %d", late.GetTickNumber(), late.GetCurrentState())
+	}
+	if err := late.Resimulate(); err != nil {
+		t.Fatalf("Resimulate failed: %v", err)
+	}
+	if late.GetTickNumber() != 12 {
+		t.Errorf("expected tick 12 after Resimulate, got %d", late.GetTickNumber())
+	}
+	if d := realtime.FindDivergence(ref.TickRecords(), late.TickRecords()); d != nil {
+		t.Fatalf("expected no divergence after re-simulation, got %s", d)
+	}
+
+	ref.Step()
+	late.Step()
+	if ref.StateHash() != late.StateHash() || late.GetCurrentState() != STATE_B {
+		t.Errorf("expected both peers in STATE_B with equal hashes, got %d and %d", ref.GetCurrentState(), late.GetCurrentState())
+	}
+	if refState.visits != 2 || lateState.visits != 2 {
+		t.Errorf("expected 2 visits to STATE_B on both peers, got %d and %d", refState.visits, lateState.visits)
+	}
+}
+
+// TestRollbackRepeatable tests that rolling back to the same tick twice and
+// re-simulating reproduces the same hashes
+func TestRollbackRepeatable(t *testing.T) {
+	schedule := map[uint64]statechartx.EventID{1: EVENT_A_TO_B, 3: EVENT_B_TO_C, 4: EVENT_C_TO_A}
+	rt := startPeer(t, &counter{})
+	defer rt.Stop()
+	runTicks(rt, schedule, 8)
+	want := rt.TickRecords()
+
+	for i := 0; i < 2; i++ {
+		if err := rt.Rollback(2); err != nil {
+			t.Fatalf("Rollback failed: %v", err)
+		}
+		if err := rt.Resimulate(); err != nil {
+			t.Fatalf("Resimulate failed: %v", err)
+		}
+		if d := realtime.FindDivergence(want, rt.TickRecords()); d != nil {
+			t.Fatalf("run %d: expected identical re-simulation, got %s", i, d)
+		}
+	}
+}
@@ -0,0 +1,607 @@
+// Package formal exports statechartx machines to the input languages of external
+// model checkers: a TLA+ module (for TLC/Apalache) and a Promela model (for SPIN).
+//
+// Both exports translate the structure of the chart rather than its state space, so
+// the generated model grows with the number of states and transitions:
+//
+//   - every compound state has a variable holding its active child ("" / NONE while
+//     the state is inactive); a parallel state's variable holds its own name while it
+//     is active, so its regions are active exactly when it is
+//   - every history state has a recorded copy of the variables below its parent
+//     (only the parent's for shallow history), saved when the parent exits and
+//     restored when the history state is targeted
+//   - every transition is an effect that records history and sets the variables below
+//     its domain; one is generated per distinct domain the transition can have
+//   - a microstep selects, for each active atomic state in document order, the first
+//     enabled transition searching outward through its ancestors (exact events before
+//     "*"), dropping transitions that conflict with one already selected
+//   - an external event is followed by eventless transitions until none is enabled,
+//     then by queued done.state events one at a time, each followed by eventless
+//     transitions again
+//   - an external event queue of bounded length is filled nondeterministically by the
+//     environment and consumed one event per macrostep (ignored events are dropped)
+//   - guards are free boolean variables, named by their registry name (or
+//     "<state>[<index>]" for anonymous guards) and re-chosen before every microstep
+//
+// This is the semantics package modelcheck explores. Actions are not executed and
+// event data is not modelled. An unguarded eventless cycle never settles; check for
+// it with a liveness property or analysis.Lint.
+//
+// Example:
+//
+//	spec, _ := formal.TLA(machine, formal.Options{Names: b, QueueBound: 3})
+//	os.WriteFile("Device.tla", spec, 0o644)
+//	model, _ := formal.Promela(machine, formal.Options{Names: b})
+//	os.WriteFile("device.pml", model, 0o644)
+package formal
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/comalice/statechartx"
+	"github.com/comalice/statechartx/modelcheck"
+)
+
+// DefaultQueueBound is the event queue capacity used when Options.QueueBound is zero.
+const DefaultQueueBound = 2
+
+// ErrNoRoot is returned for machines without a root state.
+var ErrNoRoot = errors.New("machine has no root state")
+
+// Options control export.
+type Options struct {
+	// Names provides state and event names (typically the *statechartx.MachineBuilder).
+	Names statechartx.Namer
+
+	// Module names the TLA+ module and Promela comment header.
+	// If empty, the root state's name is used.
+	Module string
+
+	// QueueBound is the external event queue capacity (default DefaultQueueBound).
+	QueueBound int
+
+	// Events is the external event alphabet sent by the environment. If empty, every
+	// event used by a transition is sent (except eventless, "*" and done.state events).
+	Events []statechartx.EventID
+
+	// Guards fixes guards, by name, to a constant instead of a free variable.
+	// Transitions whose guard is fixed to false are left out.
+	Guards map[string]bool
+}
+
+// model is the chart structure in the shape both exporters need
+type model struct {
+	m    *statechartx.Machine
+	opts Options
+	root *statechartx.State
+
+	states     []*statechartx.State // non-history states, including the root, in document order
+	composites []*statechartx.State // states with a variable: compound and parallel states
+	atomics    []*statechartx.State // states without children, in document order
+	history    []*statechartx.State
+	choices    []*choice
+	candidates map[statechartx.StateID][]*choice // per atomic state, in priority order
+	events     []string                          // external event names
+	guards     []string                          // free guard variables, sorted
+	doneOrder  []*statechartx.State              // composites raising done.state events, innermost first
+
+	initial     map[statechartx.StateID]value // initial value of every composite
+	initialDone []*statechartx.State          // done.state events raised by the initial configuration
+}
+
+// choice is a transition taken with one particular domain. Most transitions have a
+// single domain; a transition whose target is below its source can have one per
+// branch of the source its selecting atomic state is in.
+type choice struct {
+	index  int // 1-based
+	t      *statechartx.Transition
+	event  string // "" for eventless, "*" for any event
+	guard  string // free guard name, "" if none
+	domain *statechartx.State
+	covers []*statechartx.State // atomic states below the domain
+	exits  []*statechartx.State // composites strictly below the domain
+	scope  []*statechartx.State // composites the transition sets: the domain and exits
+	effect map[statechartx.StateID]value
+}
+
+// value is what a composite variable is set to: constant, recorded or conditional
+type value interface{}
+
+// constant is a state name, or none for nil
+type constant struct{ s *statechartx.State }
+
+// recorded is the value history state h recorded for composite c
+type recorded struct{ h, c *statechartx.State }
+
+// conditional is then if history h has recorded anything (is == nil) or has recorded
+// is as its parent's active child, else els
+type conditional struct {
+	h, is     *statechartx.State
+	then, els value
+}
+
+func build(m *statechartx.Machine, opts Options) (*model, error) {
+	root := m.Root()
+	if root == nil {
+		return nil, ErrNoRoot
+	}
+	if opts.QueueBound <= 0 {
+		opts.QueueBound = DefaultQueueBound
+	}
+	if opts.Module == "" {
+		opts.Module = statechartx.StateNameOf(opts.Names, root.ID)
+	}
+
+	md := &model{m: m, opts: opts, root: root, candidates: map[statechartx.StateID][]*choice{}}
+	for _, id := range m.StateIDs() {
+		s := m.GetState(id)
+		switch {
+		case s.IsHistoryState:
+			md.history = append(md.history, s)
+		case isComposite(s):
+			md.states = append(md.states, s)
+			md.composites = append(md.composites, s)
+		default:
+			md.states = append(md.states, s)
+			md.atomics = append(md.atomics, s)
+		}
+	}
+
+	md.events = md.alphabet()
+	md.buildChoices()
+
+	guards := map[string]bool{}
+	for _, c := range md.choices {
+		if c.guard != "" {
+			guards[c.guard] = true
+		}
+	}
+	for name := range guards {
+		md.guards = append(md.guards, name)
+	}
+	sort.Strings(md.guards)
+
+	for _, s := range md.composites {
+		if s != root {
+			md.doneOrder = append(md.doneOrder, s)
+		}
+	}
+	sort.SliceStable(md.doneOrder, func(i, j int) bool {
+		return depth(md.doneOrder[i]) > depth(md.doneOrder[j])
+	})
+
+	md.initial = map[statechartx.StateID]value{}
+	md.fill(root, map[statechartx.StateID]bool{}, md.initial)
+	initial := map[statechartx.StateID]*statechartx.State{}
+	for _, s := range md.composites {
+		if v, ok := md.initial[s.ID].(constant); ok {
+			initial[s.ID] = v.s
+		}
+	}
+	for _, s := range md.doneOrder {
+		if initial[s.ID] != nil && md.complete(s, initial) {
+			md.initialDone = append(md.initialDone, s)
+		}
+	}
+	return md, nil
+}
+
+// alphabet returns the external event names, in ascending event ID order
+func (md *model) alphabet() []string {
+	ids := md.opts.Events
+	if len(ids) == 0 {
+		seen := map[statechartx.EventID]bool{}
+		for _, s := range md.states {
+			for _, t := range s.Transitions {
+				if t == nil || t.Event == statechartx.NO_EVENT || t.Event == statechartx.ANY_EVENT ||
+					isRaisedEvent(t.Event) || seen[t.Event] {
+					continue
+				}
+				seen[t.Event] = true
+				ids = append(ids, t.Event)
+			}
+		}
+		sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	}
+	names := make([]string, len(ids))
+	for i, id := range ids {
+		names[i] = statechartx.EventNameOf(md.opts.Names, id)
+	}
+	return names
+}
+
+// buildChoices lists the candidate transitions of every atomic state and creates one
+// choice per distinct (transition, domain) pair
+func (md *model) buildChoices() {
+	type key struct {
+		t      *statechartx.Transition
+		domain *statechartx.State
+	}
+	byKey := map[key]*choice{}
+
+	for _, leaf := range md.atomics {
+		for s := leaf; s != nil; s = s.Parent {
+			for pass := 0; pass < 2; pass++ {
+				for _, t := range s.Transitions {
+					if t == nil || (t.Event == statechartx.ANY_EVENT) != (pass == 1) {
+						continue
+					}
+					guard := ""
+					if t.Guard != nil {
+						guard = modelcheck.GuardName(t, md.opts.Names)
+						if value, fixed := md.opts.Guards[guard]; fixed {
+							if !value {
+								continue
+							}
+							guard = ""
+						}
+					}
+					k := key{t, md.domain(t, leaf)}
+					c, ok := byKey[k]
+					if !ok {
+						c = &choice{index: len(md.choices) + 1, t: t, guard: guard, domain: k.domain}
+						c.event = statechartx.EventNameOf(md.opts.Names, t.Event)
+						if t.Event == statechartx.ANY_EVENT {
+							c.event = statechartx.AnyEventName
+						}
+						md.describe(c)
+						byKey[k] = c
+						md.choices = append(md.choices, c)
+					}
+					md.candidates[leaf.ID] = append(md.candidates[leaf.ID], c)
+				}
+			}
+		}
+	}
+}
+
+// domain returns the state whose active descendants a transition taken by leaf exits
+// (nil for internal transitions)
+func (md *model) domain(t *statechartx.Transition, leaf *statechartx.State) *statechartx.State {
+	if t.Target == 0 {
+		return nil
+	}
+	target := md.m.GetState(t.Target)
+	if target == nil {
+		return nil
+	}
+	if target == leaf {
+		if leaf.Parent != nil {
+			return leaf.Parent
+		}
+		return leaf
+	}
+	return lca(leaf, target)
+}
+
+// describe computes what a choice covers, exits and sets
+func (md *model) describe(c *choice) {
+	if c.domain == nil {
+		return
+	}
+	for _, s := range md.atomics {
+		if s.IsDescendantOf(c.domain) {
+			c.covers = append(c.covers, s)
+		}
+	}
+	for _, s := range md.composites {
+		if s.IsDescendantOf(c.domain) {
+			c.exits = append(c.exits, s)
+		}
+	}
+	if isComposite(c.domain) {
+		c.scope = append(c.scope, c.domain)
+	}
+	c.scope = append(c.scope, c.exits...)
+
+	c.effect = map[statechartx.StateID]value{}
+	for _, s := range c.scope {
+		c.effect[s.ID] = constant{}
+	}
+	target := md.m.GetState(c.t.Target)
+	if !target.IsHistoryState {
+		md.fill(c.domain, md.path(target, c.domain), c.effect)
+		return
+	}
+
+	// Enter down to the history state's parent, then restore or take the default
+	parent := target.Parent
+	md.fillUntil(c.domain, md.path(parent, c.domain), c.effect, parent)
+
+	def := map[statechartx.StateID]value{}
+	md.fill(parent, md.path(md.historyDefault(target), parent), def)
+	restore := md.restore(target)
+	for _, s := range md.composites {
+		if s == parent || s.IsDescendantOf(parent) {
+			c.effect[s.ID] = conditionalValue(target, nil, valueOr(restore, s), valueOr(def, s))
+		}
+	}
+}
+
+// restore returns the values entering history state h sets once it has recorded
+func (md *model) restore(h *statechartx.State) map[statechartx.StateID]value {
+	parent := h.Parent
+	out := map[statechartx.StateID]value{}
+	if h.HistoryType == statechartx.HistoryDeep {
+		for _, s := range md.composites {
+			if s == parent || s.IsDescendantOf(parent) {
+				out[s.ID] = recorded{h: h, c: s}
+			}
+		}
+		return out
+	}
+	if parent.IsParallel {
+		md.fill(parent, map[statechartx.StateID]bool{}, out)
+		return out
+	}
+
+	// Shallow: the recorded child, entered by its initial states
+	out[parent.ID] = recorded{h: h, c: parent}
+	for _, child := range parent.SortedChildren() {
+		if child.IsHistoryState {
+			continue
+		}
+		entered := map[statechartx.StateID]value{}
+		md.fill(child, map[statechartx.StateID]bool{}, entered)
+		for _, s := range md.composites {
+			if s == child || s.IsDescendantOf(child) {
+				out[s.ID] = conditionalValue(h, child, valueOr(entered, s), constant{})
+			}
+		}
+	}
+	return out
+}
+
+// historyDefault returns the state entered through h before it has recorded anything
+func (md *model) historyDefault(h *statechartx.State) *statechartx.State {
+	if def := md.m.GetState(h.HistoryDefault); def != nil && h.HistoryDefault != 0 {
+		return def
+	}
+	if initial := md.m.GetState(h.Parent.Initial); initial != nil {
+		return initial
+	}
+	return h.Parent
+}
+
+// path marks the states from target up to (not including) top
+func (md *model) path(target, top *statechartx.State) map[statechartx.StateID]bool {
+	in := map[statechartx.StateID]bool{}
+	for s := target; s != nil && s != top; s = s.Parent {
+		in[s.ID] = true
+	}
+	return in
+}
+
+// fill sets the variables of s and the states entered below it, preferring the
+// states marked in in and otherwise following initial states
+func (md *model) fill(s *statechartx.State, in map[statechartx.StateID]bool, out map[statechartx.StateID]value) {
+	md.fillUntil(s, in, out, nil)
+}
+
+// fillUntil is fill that leaves the states below stop to the caller
+func (md *model) fillUntil(s *statechartx.State, in map[statechartx.StateID]bool, out map[statechartx.StateID]value, stop *statechartx.State) {
+	if s == stop || !isComposite(s) {
+		return
+	}
+	children := s.SortedChildren()
+	if s.IsParallel {
+		out[s.ID] = constant{s}
+		for _, child := range children {
+			if !child.IsHistoryState {
+				md.fillUntil(child, in, out, stop)
+			}
+		}
+		return
+	}
+
+	next := md.chosen(s, children, in)
+	if next == nil {
+		initial := md.m.GetState(s.Initial)
+		if initial == nil || !initial.IsDescendantOf(s) {
+			initial = children[0]
+			for _, child := range children {
+				if !child.IsHistoryState {
+					initial = child
+					break
+				}
+			}
+		}
+		for p := initial; p != nil && p != s; p = p.Parent {
+			in[p.ID] = true
+		}
+		next = md.chosen(s, children, in)
+	}
+	if next == nil {
+		return
+	}
+	out[s.ID] = constant{next}
+	md.fillUntil(next, in, out, stop)
+}
+
+func (md *model) chosen(s *statechartx.State, children []*statechartx.State, in map[statechartx.StateID]bool) *statechartx.State {
+	for _, child := range children {
+		if in[child.ID] {
+			return child
+		}
+	}
+	return nil
+}
+
+// complete reports whether s has reached a final state when composites have the given children
+func (md *model) complete(s *statechartx.State, active map[statechartx.StateID]*statechartx.State) bool {
+	switch {
+	case !isComposite(s):
+		return isFinal(s)
+	case s.IsParallel:
+		for _, child := range s.Children {
+			if !child.IsHistoryState && !md.complete(child, active) {
+				return false
+			}
+		}
+		return true
+	}
+	child := active[s.ID]
+	return child != nil && !isComposite(child) && isFinal(child)
+}
+
+// finalChildren returns the atomic final children of compound s
+func finalChildren(s *statechartx.State) []*statechartx.State {
+	var finals []*statechartx.State
+	for _, child := range s.SortedChildren() {
+		if !child.IsHistoryState && !isComposite(child) && isFinal(child) {
+			finals = append(finals, child)
+		}
+	}
+	return finals
+}
+
+// historyScope returns the composites history state h records
+func (md *model) historyScope(h *statechartx.State) []*statechartx.State {
+	var scope []*statechartx.State
+	for _, s := range md.composites {
+		if s == h.Parent || (h.HistoryType == statechartx.HistoryDeep && s.IsDescendantOf(h.Parent)) {
+			scope = append(scope, s)
+		}
+	}
+	return scope
+}
+
+// exiting returns the choices whose domain strictly contains composite s: they exit
+// s if it is active (recording its history) and may enter it again
+func (md *model) exiting(s *statechartx.State) []*choice {
+	var list []*choice
+	for _, c := range md.choices {
+		for _, exited := range c.exits {
+			if exited == s {
+				list = append(list, c)
+				break
+			}
+		}
+	}
+	return list
+}
+
+// conflicts reports whether a and b cannot be selected in the same microstep
+func conflicts(a, b *choice) bool {
+	if a.t == b.t {
+		return true
+	}
+	if a.domain == nil || b.domain == nil {
+		return false
+	}
+	return a.domain == b.domain || a.domain.IsDescendantOf(b.domain) || b.domain.IsDescendantOf(a.domain)
+}
+
+// conflicting returns the choices that conflict with c, including c itself
+func (md *model) conflicting(c *choice) []*choice {
+	var list []*choice
+	for _, other := range md.choices {
+		if conflicts(c, other) {
+			list = append(list, other)
+		}
+	}
+	return list
+}
+
+// covering returns the choices whose domain contains atomic state s
+func (md *model) covering(s *statechartx.State) []*choice {
+	var list []*choice
+	for _, c := range md.choices {
+		for _, covered := range c.covers {
+			if covered == s {
+				list = append(list, c)
+				break
+			}
+		}
+	}
+	return list
+}
+
+func conditionalValue(h, is *statechartx.State, then, els value) value {
+	if then == els {
+		return then
+	}
+	return conditional{h: h, is: is, then: then, els: els}
+}
+
+func valueOr(values map[statechartx.StateID]value, s *statechartx.State) value {
+	if v, ok := values[s.ID]; ok {
+		return v
+	}
+	return constant{}
+}
+
+func (md *model) name(s *statechartx.State) string {
+	return statechartx.StateNameOf(md.opts.Names, s.ID)
+}
+
+func (md *model) doneEvent(s *statechartx.State) string {
+	return statechartx.EventNameOf(md.opts.Names, statechartx.DoneEventID(s.ID))
+}
+
+// label describes a choice as "source --event [guard]--> target"
+func (md *model) label(c *choice) string {
+	text := "state"
+	if c.t.Source != nil {
+		text = md.name(c.t.Source)
+	}
+	text += " --" + c.event
+	if c.guard != "" {
+		text += " [" + c.guard + "]"
+	}
+	text += "--> "
+	if target := md.m.GetState(c.t.Target); target != nil && c.t.Target != 0 {
+		text += md.name(target)
+		if c.domain != nil {
+			text += " (domain " + md.name(c.domain) + ")"
+		}
+	} else {
+		text += "(internal)"
+	}
+	return text
+}
+
+func isComposite(s *statechartx.State) bool {
+	if s.IsHistoryState {
+		return false
+	}
+	for _, child := range s.Children {
+		if !child.IsHistoryState {
+			return true
+		}
+	}
+	return false
+}
+
+func isFinal(s *statechartx.State) bool {
+	return s.IsFinal || s.Final
+}
+
+// isRaisedEvent reports whether id is raised by a runtime rather than sent:
+// done events, and timer events such as realtime.AfterTicks (which lie below them)
+func isRaisedEvent(id statechartx.EventID) bool {
+	return id <= statechartx.DoneEventID(0)
+}
+
+// lca returns the deepest state that is an ancestor-or-self of both a and b
+func lca(a, b *statechartx.State) *statechartx.State {
+	ancestors := map[*statechartx.State]bool{}
+	for s := a; s != nil; s = s.Parent {
+		ancestors[s] = true
+	}
+	for s := b; s != nil; s = s.Parent {
+		if ancestors[s] {
+			return s
+		}
+	}
+	return nil
+}
+
+func depth(s *statechartx.State) int {
+	d := 0
+	for p := s.Parent; p != nil; p = p.Parent {
+		d++
+	}
+	return d
+}
@@ -0,0 +1,155 @@
+package formal_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/comalice/statechartx"
+	"github.com/comalice/statechartx/formal"
+)
+
+func buildChart(t *testing.T) (*statechartx.MachineBuilder, *statechartx.Machine) {
+	t.Helper()
+	reg := statechartx.NewRegistry()
+	reg.RegisterGuard("hasPower", statechartx.Always())
+
+	b := statechartx.NewMachineBuilder("device", "off").WithRegistry(reg)
+	b.State("off").OnRef("power", "on.hist", "hasPower")
+	b.State("on").Compound("on.idle").OnRef("power", "off", "")
+	b.State("on.idle").OnRef("work", "on.busy", "")
+	b.State("on.busy").OnRef("finish", "on.idle", "").OnRef("fail", "broken", "")
+	b.State("on.hist").History(statechartx.HistoryShallow, "on.idle")
+	b.State("broken").Final(nil)
+
+	machine, err := b.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b, machine
+}
+
+func assertContains(t *testing.T, format, out string, want ...string) {
+	t.Helper()
+	for _, w := range want {
+		if !strings.Contains(out, w) {
+			t.Errorf("%s output should contain %q:\n%s", format, w, out)
+		}
+	}
+}
+
+func TestTLA(t *testing.T) {
+	b, machine := buildChart(t)
+	spec, err := formal.TLA(machine, formal.Options{Names: b, QueueBound: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assertContains(t, "TLA+", string(spec),
+		" MODULE device ",
+		"QueueBound == 3",
+		`States == {"device", "off", "on", "on.idle", "on.busy", "broken"}`,
+		`Composites == {"device", "on"}`,
+		`("on.idle" :> "on")`,
+		`HistoryStates == {"on.hist"}`,
+		`HistScope == ("on.hist" :> {"on"})`,
+		`Events == {"power", "work", "finish", "fail"}`,
+		`DoneEvents == {"done.state.on"}`,
+		`Guards == {"hasPower"}`,
+		`\* 1: off --power [hasPower]--> on.hist (domain device)`,
+		`Trigger == <<"power", "work", "power", "finish", "fail">>`,
+		`GuardOf == <<"hasPower", "", "", "", "">>`,
+		`Candidates == ("off" :> <<1>>) @@ ("on.idle" :> <<2, 3>>) @@ ("on.busy" :> <<4, 5, 3>>)`,
+		`T1(h) == ("device" :> "on") @@ ("on" :> (IF h["on.hist"]["on"] # None THEN h["on.hist"]["on"] ELSE "on.idle"))`,
+		`T3(h) == ("device" :> "off") @@ ("on" :> None)`,
+		"VARIABLES cfg, history, queue, internal, stable, guard",
+		`/\ cfg = ("device" :> "off") @@ ("on" :> None)`,
+		"Spec == Init /\\ [][Next]_vars",
+	)
+	if strings.Contains(string(spec), "C0 ==") {
+		t.Error("configurations should not be enumerated")
+	}
+	if !strings.HasSuffix(strings.TrimSpace(string(spec)), "=====") {
+		t.Error("module should end with a ==== line")
+	}
+}
+
+func TestPromela(t *testing.T) {
+	b, machine := buildChart(t)
+	model, err := formal.Promela(machine, formal.Options{Names: b})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assertContains(t, "Promela", string(model),
+		"#define QUEUE_BOUND 2",
+		"mtype = { ev_none, ev_power, ev_work, ev_finish, ev_fail, ev_done_state_on };",
+		"byte c_device = st_off;",
+		"byte c_on = NONE;",
+		"byte h_on_hist_on = NONE;",
+		"#define in_on_idle (c_on == st_on_idle)",
+		"#define terminated complete_device",
+		"bool g_hasPower; /* hasPower */",
+		"if :: g_hasPower = true :: g_hasPower = false fi;",
+		":: !picked && (ev == ev_power && g_hasPower) ->",
+		"h_on_hist_on = c_on;",
+		"c_on = (h_on_hist_on != NONE -> h_on_hist_on : st_on_idle);",
+		":: queue!ev_power",
+	)
+}
+
+func TestParallelRegions(t *testing.T) {
+	b := statechartx.NewMachineBuilder("job", "work")
+	b.State("work").Parallel().On("done.state.work", "finished", nil, nil)
+	b.State("work.left").Compound("work.left.a")
+	b.State("work.left.a").On("x", "work.left.done", nil, nil)
+	b.State("work.left.done").Final(nil)
+	b.State("work.right").Compound("work.right.a")
+	b.State("work.right.a").On("y", "work.right.done", nil, nil)
+	b.State("work.right.done").Final(nil)
+	b.State("work.hist").History(statechartx.HistoryDeep, "work.left")
+	b.State("finished").Final(nil)
+	machine, err := b.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	spec, err := formal.TLA(machine, formal.Options{Names: b})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertContains(t, "TLA+", string(spec),
+		`Parallel == {"work"}`,
+		`HistScope == ("work.hist" :> {"work", "work.left", "work.right"})`,
+		`DoneOrder == <<"work.left", "work.right", "work">>`,
+		`/\ cfg = ("job" :> "work") @@ ("work" :> "work") @@ ("work.left" :> "work.left.a") @@ ("work.right" :> "work.right.a")`,
+	)
+
+	model, err := formal.Promela(machine, formal.Options{Names: b})
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertContains(t, "Promela", string(model),
+		"#define in_work_left (c_work != NONE)",
+		"#define complete_work (complete_work_left && complete_work_right)",
+		"internal!ev_done_state_work",
+	)
+}
+
+func TestFixedGuards(t *testing.T) {
+	b, machine := buildChart(t)
+	spec, err := formal.TLA(machine, formal.Options{Names: b, Guards: map[string]bool{"hasPower": true}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(spec), `guard["`) || !strings.Contains(string(spec), "Guards == {}") {
+		t.Errorf("fixed guards should not be free variables:\n%s", spec)
+	}
+
+	model, err := formal.Promela(machine, formal.Options{Names: b, Guards: map[string]bool{"hasPower": false}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(model), "off --power") {
+		t.Errorf("transitions with a guard fixed to false should be left out:\n%s", model)
+	}
+}
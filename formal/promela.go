@@ -0,0 +1,438 @@
+package formal
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/comalice/statechartx"
+)
+
+// Promela returns a SPIN model of m.
+//
+// Every compound and parallel state is a variable c_<state> holding the number of
+// its active child (st_<state>), or NONE while it is inactive; every history state
+// records copies h_<history>_<state> of the variables it restores. Two processes
+// run: environment, which nondeterministically sends every external event into the
+// bounded channel queue, and machine, which takes one event at a time and runs the
+// microstep inline: select the transitions (sel[n]), record history, apply each
+// transition's effect and queue done.state events on the channel internal. Free
+// guards are bool variables g_<name>, re-chosen before every microstep. A macro
+// in_<state> is defined for every state, so properties can be written as
+//
+//	ltl safe { [] !in_broken }
+func Promela(m *statechartx.Machine, opts Options) ([]byte, error) {
+	md, err := build(m, opts)
+	if err != nil {
+		return nil, err
+	}
+	p := &promela{md: md, ids: newIdentifiers()}
+	return p.render(), nil
+}
+
+// promela holds the identifiers chosen for one export
+type promela struct {
+	md  *model
+	ids *identifiers
+	sb  strings.Builder
+}
+
+func (p *promela) w(format string, args ...any) {
+	fmt.Fprintf(&p.sb, format+"\n", args...)
+}
+
+func (p *promela) render() []byte {
+	md := p.md
+	p.w("/* Generated by statechartx/formal from machine %s. Do not edit. */", promelaComment(md.opts.Module))
+	p.w("")
+	p.w("#define QUEUE_BOUND %d", md.opts.QueueBound)
+	p.w("#define INTERNAL_BOUND %d", max(1, len(md.doneOrder)))
+	p.w("#define NONE 0")
+	p.w("")
+
+	p.w("/* States */")
+	for i, s := range md.states {
+		p.w("#define %s %d", p.state(s), i+1)
+	}
+	p.w("")
+
+	mtypes := []string{"ev_none"}
+	for _, name := range md.events {
+		mtypes = append(mtypes, p.event(name))
+	}
+	for _, s := range md.doneOrder {
+		mtypes = append(mtypes, p.event(md.doneEvent(s)))
+	}
+	p.w("mtype = { %s };", strings.Join(mtypes, ", "))
+	p.w("")
+
+	varType := "byte"
+	if len(md.states) > 255 {
+		varType = "short"
+	}
+	p.w("chan queue = [QUEUE_BOUND] of { mtype };")
+	p.w("chan internal = [INTERNAL_BOUND] of { mtype };")
+	p.w("")
+	p.w("/* Active child of every compound state (the state itself for parallel states) */")
+	for _, s := range md.composites {
+		p.w("%s %s = %s;", varType, p.variable(s), p.value(valueOr(md.initial, s)))
+	}
+	if len(md.history) > 0 {
+		p.w("")
+		p.w("/* Recorded history */")
+		for _, h := range md.history {
+			for _, s := range md.historyScope(h) {
+				p.w("%s %s = NONE; /* %s */", varType, p.history(h, s), promelaComment(md.name(h)+" records "+md.name(s)))
+			}
+		}
+	}
+	if len(md.guards) > 0 {
+		p.w("")
+		for _, name := range md.guards {
+			p.w("bool %s; /* %s */", p.guard(name), promelaComment(name))
+		}
+	}
+	p.w("")
+	p.w("/* Transitions selected by the current microstep */")
+	for _, c := range md.choices {
+		p.w("/* %d: %s */", c.index, promelaComment(md.label(c)))
+	}
+	p.w("bool sel[%d];", len(md.choices)+1)
+	p.w("bool fired;")
+	for _, s := range md.doneOrder {
+		p.w("bool %s;", p.was(s))
+	}
+	p.w("")
+
+	for _, s := range md.states {
+		if s == md.root {
+			continue
+		}
+		p.w("#define %s (%s)", p.in(s), p.inState(s))
+	}
+	for _, s := range md.composites {
+		p.w("#define %s (%s)", p.complete(s), p.completeExpr(s))
+	}
+	p.w("#define terminated %s", p.completeRef(md.root))
+	p.w("")
+
+	p.w("inline sample_guards() {")
+	for _, name := range md.guards {
+		g := p.guard(name)
+		p.w("  if :: %s = true :: %s = false fi;", g, g)
+	}
+	p.w("  skip")
+	p.w("}")
+	p.w("")
+
+	p.renderMicrostep()
+
+	p.w("inline settle() {")
+	p.w("  do")
+	p.w("  :: microstep(ev_none);")
+	p.w("     if")
+	p.w("     :: fired -> skip")
+	p.w("     :: else ->")
+	p.w("        if")
+	p.w("        :: nempty(internal) -> internal?e; microstep(e)")
+	p.w("        :: empty(internal) -> break")
+	p.w("        fi")
+	p.w("     fi")
+	p.w("  od")
+	p.w("}")
+	p.w("")
+
+	p.w("active proctype environment() {")
+	p.w("end:")
+	p.w("  do")
+	p.w("  :: terminated -> break")
+	for _, name := range md.events {
+		p.w("  :: queue!%s", p.event(name))
+	}
+	p.w("  od")
+	p.w("}")
+	p.w("")
+
+	p.w("active proctype machine() {")
+	p.w("  mtype e;")
+	p.w("  bool picked;")
+	for _, s := range md.initialDone {
+		p.w("  internal!%s;", p.event(md.doneEvent(s)))
+	}
+	p.w("  settle();")
+	p.w("end:")
+	p.w("  do")
+	p.w("  :: terminated -> break")
+	p.w("  :: queue?e ->")
+	p.w("     microstep(e);")
+	p.w("     if")
+	p.w("     :: fired -> settle()")
+	p.w("     :: else -> skip")
+	p.w("     fi")
+	p.w("  od")
+	p.w("}")
+	return []byte(p.sb.String())
+}
+
+// renderMicrostep writes the microstep inline: selection, history, effects, done events
+func (p *promela) renderMicrostep() {
+	md := p.md
+	p.w("inline microstep(ev) {")
+	p.w("  sample_guards();")
+	p.w("  fired = false;")
+	for _, c := range md.choices {
+		p.w("  sel[%d] = false;", c.index)
+	}
+
+	for _, a := range md.atomics {
+		candidates := md.candidates[a.ID]
+		if len(candidates) == 0 {
+			continue
+		}
+		p.w("  /* %s */", promelaComment(md.name(a)))
+		cond := "true"
+		if a != md.root {
+			cond = p.in(a)
+		}
+		if covering := md.covering(a); len(covering) > 0 {
+			cond += " && !" + p.anySelected(covering)
+		}
+		p.w("  picked = false;")
+		p.w("  if")
+		p.w("  :: %s ->", cond)
+		for _, c := range candidates {
+			p.w("     if")
+			p.w("     :: !picked && %s ->", p.enabled(c))
+			p.w("        picked = true;")
+			p.w("        if")
+			p.w("        :: !%s -> sel[%d] = true; fired = true", p.anySelected(md.conflicting(c)), c.index)
+			p.w("        :: else -> skip")
+			p.w("        fi")
+			p.w("     :: else -> skip")
+			p.w("     fi;")
+		}
+		p.w("     skip")
+		p.w("  :: else -> skip")
+		p.w("  fi;")
+	}
+
+	if len(md.doneOrder) > 0 {
+		p.w("  /* states complete before the step */")
+		for _, s := range md.doneOrder {
+			p.w("  %s = (%s != NONE && %s);", p.was(s), p.variable(s), p.completeRef(s))
+		}
+	}
+
+	for _, h := range md.history {
+		recorders := md.exiting(h.Parent)
+		if len(recorders) == 0 {
+			continue
+		}
+		p.w("  /* record %s */", promelaComment(md.name(h)))
+		p.w("  if")
+		p.w("  :: %s != NONE && %s ->", p.variable(h.Parent), p.anySelected(recorders))
+		for _, s := range md.historyScope(h) {
+			p.w("     %s = %s;", p.history(h, s), p.variable(s))
+		}
+		p.w("     skip")
+		p.w("  :: else -> skip")
+		p.w("  fi;")
+	}
+
+	for _, c := range md.choices {
+		if len(c.scope) == 0 {
+			continue
+		}
+		p.w("  if")
+		p.w("  :: sel[%d] ->", c.index)
+		for _, s := range c.scope {
+			p.w("     %s = %s;", p.variable(s), p.value(c.effect[s.ID]))
+		}
+		p.w("     skip")
+		p.w("  :: else -> skip")
+		p.w("  fi;")
+	}
+
+	for _, s := range md.doneOrder {
+		entered := "false"
+		if list := md.exiting(s); len(list) > 0 {
+			entered = p.anySelected(list)
+		}
+		p.w("  if")
+		p.w("  :: %s != NONE && %s && !(%s && !%s) -> internal!%s",
+			p.variable(s), p.completeRef(s), p.was(s), entered, p.event(md.doneEvent(s)))
+		p.w("  :: else -> skip")
+		p.w("  fi;")
+	}
+	p.w("  skip")
+	p.w("}")
+	p.w("")
+}
+
+// enabled renders the trigger and guard test of a choice for event variable ev
+func (p *promela) enabled(c *choice) string {
+	var cond string
+	switch c.event {
+	case "":
+		cond = "ev == ev_none"
+	case statechartx.AnyEventName:
+		cond = "ev != ev_none"
+	default:
+		cond = "ev == " + p.event(c.event)
+	}
+	if c.guard != "" {
+		cond += " && " + p.guard(c.guard)
+	}
+	return "(" + cond + ")"
+}
+
+// anySelected renders "(sel[a] || sel[b] ...)"
+func (p *promela) anySelected(list []*choice) string {
+	terms := make([]string, len(list))
+	for i, c := range list {
+		terms[i] = fmt.Sprintf("sel[%d]", c.index)
+	}
+	return "(" + strings.Join(terms, " || ") + ")"
+}
+
+// inState renders the test for s being active
+func (p *promela) inState(s *statechartx.State) string {
+	if s.Parent.IsParallel {
+		return p.variable(s.Parent) + " != NONE"
+	}
+	return p.variable(s.Parent) + " == " + p.state(s)
+}
+
+// completeExpr renders the test for composite s having reached a final state
+func (p *promela) completeExpr(s *statechartx.State) string {
+	if s.IsParallel {
+		var terms []string
+		for _, child := range s.SortedChildren() {
+			if !child.IsHistoryState {
+				terms = append(terms, p.completeRef(child))
+			}
+		}
+		return strings.Join(terms, " && ")
+	}
+	var terms []string
+	for _, f := range finalChildren(s) {
+		terms = append(terms, p.variable(s)+" == "+p.state(f))
+	}
+	return orFalse(terms)
+}
+
+// completeRef names the completion test of s: a macro for composites, a constant otherwise
+func (p *promela) completeRef(s *statechartx.State) string {
+	if isComposite(s) {
+		return p.complete(s)
+	}
+	if isFinal(s) {
+		return "true"
+	}
+	return "false"
+}
+
+func (p *promela) value(v value) string {
+	switch v := v.(type) {
+	case constant:
+		if v.s == nil {
+			return "NONE"
+		}
+		return p.state(v.s)
+	case recorded:
+		return p.history(v.h, v.c)
+	case conditional:
+		cond := p.history(v.h, v.h.Parent) + " != NONE"
+		if v.is != nil {
+			cond = p.history(v.h, v.h.Parent) + " == " + p.state(v.is)
+		}
+		return fmt.Sprintf("(%s -> %s : %s)", cond, p.value(v.then), p.value(v.els))
+	}
+	return "NONE"
+}
+
+func (p *promela) state(s *statechartx.State) string {
+	return p.ids.get("st_", p.md.name(s))
+}
+
+func (p *promela) in(s *statechartx.State) string {
+	return p.ids.get("in_", p.md.name(s))
+}
+
+func (p *promela) variable(s *statechartx.State) string {
+	return p.ids.get("c_", p.md.name(s))
+}
+
+func (p *promela) history(h, s *statechartx.State) string {
+	return p.ids.get("h_", p.md.name(h)+"\x00"+p.md.name(s))
+}
+
+func (p *promela) complete(s *statechartx.State) string {
+	return p.ids.get("complete_", p.md.name(s))
+}
+
+func (p *promela) was(s *statechartx.State) string {
+	return p.ids.get("was_", p.md.name(s))
+}
+
+func (p *promela) guard(name string) string {
+	return p.ids.get("g_", name)
+}
+
+func (p *promela) event(name string) string {
+	return p.ids.get("ev_", name)
+}
+
+func orFalse(terms []string) string {
+	if len(terms) == 0 {
+		return "false"
+	}
+	return strings.Join(terms, " || ")
+}
+
+func promelaComment(s string) string {
+	return strings.ReplaceAll(s, "*/", "* /")
+}
+
+// identifiers maps names to unique C-style identifiers
+type identifiers struct {
+	byName map[string]string
+	used   map[string]bool
+}
+
+func newIdentifiers() *identifiers {
+	return &identifiers{byName: map[string]string{}, used: map[string]bool{"ev_none": true}}
+}
+
+func (ids *identifiers) get(prefix, name string) string {
+	key := prefix + "\x00" + name
+	if id, ok := ids.byName[key]; ok {
+		return id
+	}
+	base := prefix + identifier(name)
+	id := base
+	for i := 2; ids.used[id]; i++ {
+		id = fmt.Sprintf("%s_%d", base, i)
+	}
+	ids.byName[key] = id
+	ids.used[id] = true
+	return id
+}
+
+// identifier converts a name into [A-Za-z0-9_]+
+func identifier(name string) string {
+	var sb strings.Builder
+	for _, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_':
+			sb.WriteRune(r)
+		case r == '*':
+			sb.WriteString("any")
+		default:
+			sb.WriteRune('_')
+		}
+	}
+	if sb.Len() == 0 {
+		return "x"
+	}
+	return sb.String()
+}
@@ -0,0 +1,355 @@
+package formal
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/comalice/statechartx"
+)
+
+// TLA returns a TLA+ module specifying m.
+//
+// The chart structure is declared as constants: States, Parent, Parallel, Finals,
+// HistoryStates with their parent and recorded scope, and one row per transition
+// (Trigger, GuardOf, Covers, Exits, Scope, Conflicts and the effect operator T<n>).
+// The variables are cfg (the active child of every compound and parallel state),
+// history (what each history state recorded), queue (pending external events),
+// internal (pending done.state events), stable (no eventless transition or done
+// event is pending) and guard (the free guard variables). InState(s) holds while
+// state s is active, so properties can be written over state names:
+//
+//	Safety == ~InState("broken")
+func TLA(m *statechartx.Machine, opts Options) ([]byte, error) {
+	md, err := build(m, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var sb strings.Builder
+	w := func(format string, args ...any) { fmt.Fprintf(&sb, format+"\n", args...) }
+
+	module := identifier(md.opts.Module)
+	header := fmt.Sprintf(" MODULE %s ", module)
+	dashes := strings.Repeat("-", max(4, (72-len(header))/2))
+	w("%s%s%s", dashes, header, dashes)
+	w(`\* Generated by statechartx/formal from machine %s. Do not edit.`, tlaString(md.opts.Module))
+	w("EXTENDS Naturals, Sequences, TLC")
+	w("")
+	w("QueueBound == %d", md.opts.QueueBound)
+	w(`None == ""    \* value of an inactive state's variable and of unrecorded history`)
+	w(`NoEvent == "" \* trigger of eventless transitions`)
+	w("")
+
+	w(`\* Hierarchy`)
+	w("Root == %s", tlaString(md.name(md.root)))
+	w("States == %s", tlaSet(md.names(md.states)))
+	w("Composites == %s", tlaSet(md.names(md.composites)))
+	var parallel []*statechartx.State
+	for _, s := range md.composites {
+		if s.IsParallel {
+			parallel = append(parallel, s)
+		}
+	}
+	w("Parallel == %s", tlaSet(md.names(parallel)))
+	var finals []*statechartx.State
+	for _, s := range md.atomics {
+		if isFinal(s) {
+			finals = append(finals, s)
+		}
+	}
+	w("Finals == %s", tlaSet(md.names(finals)))
+	var parents []string
+	for _, s := range md.states {
+		if s.Parent != nil {
+			parents = append(parents, fmt.Sprintf("(%s :> %s)", tlaString(md.name(s)), tlaString(md.name(s.Parent))))
+		}
+	}
+	w("Parent == %s", tlaFunction(parents))
+	w("")
+
+	w(`\* History states, their parent and the variables they record`)
+	w("HistoryStates == %s", tlaSet(md.names(md.history)))
+	var histParent, histScope []string
+	for _, h := range md.history {
+		histParent = append(histParent, fmt.Sprintf("(%s :> %s)", tlaString(md.name(h)), tlaString(md.name(h.Parent))))
+		histScope = append(histScope, fmt.Sprintf("(%s :> %s)", tlaString(md.name(h)), tlaSet(md.names(md.historyScope(h)))))
+	}
+	w("HistParent == %s", tlaFunction(histParent))
+	w("HistScope == %s", tlaFunction(histScope))
+	w("")
+
+	w("Events == %s", tlaSet(md.events))
+	var done []string
+	for _, s := range md.doneOrder {
+		done = append(done, md.doneEvent(s))
+	}
+	w("DoneEvents == %s", tlaSet(done))
+	w("Guards == %s", tlaSet(md.guards))
+	w("")
+
+	w(`\* Transitions, one per domain: trigger ("*" matches any event), free guard,`)
+	w(`\* atomic states covered, composites exited, composites set`)
+	for _, c := range md.choices {
+		w(`\* %d: %s`, c.index, md.label(c))
+	}
+	var trigger, guards, covers, exits, scope []string
+	var conflict []string
+	for _, c := range md.choices {
+		trigger = append(trigger, tlaString(c.event))
+		guards = append(guards, tlaString(c.guard))
+		covers = append(covers, tlaSet(md.names(c.covers)))
+		exits = append(exits, tlaSet(md.names(c.exits)))
+		scope = append(scope, tlaSet(md.names(c.scope)))
+		for _, other := range md.conflicting(c) {
+			conflict = append(conflict, fmt.Sprintf("<<%d, %d>>", c.index, other.index))
+		}
+	}
+	w("Trigger == <<%s>>", strings.Join(trigger, ", "))
+	w("GuardOf == <<%s>>", strings.Join(guards, ", "))
+	w("Covers == <<%s>>", strings.Join(covers, ", "))
+	w("Exits == <<%s>>", strings.Join(exits, ", "))
+	w("Scope == <<%s>>", strings.Join(scope, ", "))
+	w("Conflicts == {%s}", strings.Join(conflict, ", "))
+	w("")
+
+	w(`\* Atomic states in document order and their candidate transitions in priority order`)
+	w("Atomics == <<%s>>", strings.Join(quoteAll(md.names(md.atomics)), ", "))
+	var candidates []string
+	for _, s := range md.atomics {
+		var list []string
+		for _, c := range md.candidates[s.ID] {
+			list = append(list, fmt.Sprint(c.index))
+		}
+		candidates = append(candidates, fmt.Sprintf("(%s :> <<%s>>)", tlaString(md.name(s)), strings.Join(list, ", ")))
+	}
+	w("Candidates == %s", tlaFunction(candidates))
+	w("")
+
+	w(`\* done.state events in the order they are raised: innermost states first`)
+	w("DoneOrder == <<%s>>", strings.Join(quoteAll(md.names(md.doneOrder)), ", "))
+	var doneEvent []string
+	for _, s := range md.doneOrder {
+		doneEvent = append(doneEvent, fmt.Sprintf("(%s :> %s)", tlaString(md.name(s)), tlaString(md.doneEvent(s))))
+	}
+	w("DoneEvent == %s", tlaFunction(doneEvent))
+	w("")
+
+	w("VARIABLES cfg, history, queue, internal, stable, guard")
+	w("vars == <<cfg, history, queue, internal, stable, guard>>")
+	w("")
+
+	w("TypeOK ==")
+	w(`    /\ cfg \in [Composites -> States \cup {None}]`)
+	w(`    /\ history \in [HistoryStates -> [Composites -> States \cup {None}]]`)
+	w(`    /\ queue \in Seq(Events) /\ Len(queue) <= QueueBound`)
+	w(`    /\ internal \in Seq(DoneEvents)`)
+	w(`    /\ stable \in BOOLEAN`)
+	w(`    /\ guard \in [Guards -> BOOLEAN]`)
+	w("")
+
+	w(`\* A state is active when its parent's variable names it (or the parallel parent itself)`)
+	w("InState(s) == s = Root \\/ cfg[Parent[s]] \\in {s, Parent[s]}")
+	w("")
+	w("RECURSIVE Complete(_, _)")
+	w(`\* State s has reached a final state when composites have the children c`)
+	w("Complete(c, s) ==")
+	w(`    IF s \notin Composites THEN s \in Finals`)
+	w(`    ELSE IF s \in Parallel THEN \A r \in {x \in DOMAIN Parent : Parent[x] = s} : Complete(c, r)`)
+	w(`    ELSE c[s] \in Finals`)
+	w("")
+	w("Terminated == Complete(cfg, Root)")
+	w("")
+
+	w(`\* Effects: the value of every composite a transition sets, given the new history h`)
+	for _, c := range md.choices {
+		if len(c.scope) == 0 {
+			continue
+		}
+		var parts []string
+		for _, s := range c.scope {
+			parts = append(parts, fmt.Sprintf("(%s :> %s)", tlaString(md.name(s)), md.tlaValue(c.effect[s.ID])))
+		}
+		w("T%d(h) == %s", c.index, strings.Join(parts, " @@ "))
+	}
+	var effects []string
+	for _, c := range md.choices {
+		if len(c.scope) > 0 {
+			effects = append(effects, fmt.Sprintf("k = %d -> T%d(h)", c.index, c.index))
+		}
+	}
+	if len(effects) == 0 {
+		w("Effect(k, h) == <<>>")
+	} else {
+		w("Effect(k, h) == CASE %s", strings.Join(effects, "\n                   [] "))
+	}
+	w("")
+
+	w("Enabled(k, e) ==")
+	w(`    /\ Trigger[k] = e \/ (Trigger[k] = "*" /\ e # NoEvent)`)
+	w(`    /\ GuardOf[k] = "" \/ guard[GuardOf[k]]`)
+	w("")
+	w("RECURSIVE FirstEnabled(_, _, _)")
+	w("FirstEnabled(ks, e, i) ==")
+	w(`    IF i > Len(ks) THEN 0`)
+	w(`    ELSE IF Enabled(ks[i], e) THEN ks[i]`)
+	w(`    ELSE FirstEnabled(ks, e, i + 1)`)
+	w("")
+	w("RECURSIVE SelectFrom(_, _, _)")
+	w(`\* Each active atomic state not exited by a selected transition adds its first`)
+	w(`\* enabled transition unless it conflicts with one already selected`)
+	w("SelectFrom(e, i, sel) ==")
+	w(`    IF i > Len(Atomics) THEN sel`)
+	w(`    ELSE LET a == Atomics[i]`)
+	w(`             k == FirstEnabled(Candidates[a], e, 1)`)
+	w(`         IN IF /\ InState(a)`)
+	w(`               /\ \A j \in sel : a \notin Covers[j]`)
+	w(`               /\ k # 0`)
+	w(`               /\ \A j \in sel : <<k, j>> \notin Conflicts`)
+	w(`            THEN SelectFrom(e, i + 1, sel \cup {k})`)
+	w(`            ELSE SelectFrom(e, i + 1, sel)`)
+	w("")
+	w("Selection(e) == SelectFrom(e, 1, {})")
+	w("")
+
+	w(`\* History states whose parent is active and exited record their scope`)
+	w("Recorded(sel) ==")
+	w(`    [h \in HistoryStates |->`)
+	w(`        IF cfg[HistParent[h]] # None /\ \E k \in sel : HistParent[h] \in Exits[k]`)
+	w(`        THEN [c \in Composites |-> IF c \in HistScope[h] THEN cfg[c] ELSE history[h][c]]`)
+	w(`        ELSE history[h]]`)
+	w("")
+	w("NextCfg(sel, h) ==")
+	w(`    [c \in Composites |-> IF \E k \in sel : c \in Scope[k] THEN Effect(CHOOSE k \in sel : c \in Scope[k], h)[c] ELSE cfg[c]]`)
+	w("")
+	w(`\* done.state events for states that completed or were re-entered complete`)
+	w("Done(sel, new) ==")
+	w(`    LET completed == SelectSeq(DoneOrder, LAMBDA s :`)
+	w(`            /\ new[s] # None /\ Complete(new, s)`)
+	w(`            /\ ~(cfg[s] # None /\ Complete(cfg, s) /\ ~\E k \in sel : s \in Exits[k]))`)
+	w(`    IN [i \in 1..Len(completed) |-> DoneEvent[completed[i]]]`)
+	w("")
+	w(`\* Take the selected transitions; pending are the done events still queued`)
+	w("Apply(sel, pending) ==")
+	w(`    LET h == Recorded(sel)`)
+	w(`        new == NextCfg(sel, h)`)
+	w(`    IN /\ cfg' = new`)
+	w(`       /\ history' = h`)
+	w(`       /\ internal' = pending \o Done(sel, new)`)
+	w("")
+
+	var initial []string
+	for _, s := range md.composites {
+		initial = append(initial, fmt.Sprintf("(%s :> %s)", tlaString(md.name(s)), md.tlaValue(valueOr(md.initial, s))))
+	}
+	var initialDone []string
+	for _, s := range md.initialDone {
+		initialDone = append(initialDone, tlaString(md.doneEvent(s)))
+	}
+	w("Init ==")
+	w(`    /\ cfg = %s`, tlaFunction(initial))
+	w(`    /\ history = [h \in HistoryStates |-> [c \in Composites |-> None]]`)
+	w(`    /\ queue = <<>>`)
+	w(`    /\ internal = <<%s>>`, strings.Join(initialDone, ", "))
+	w(`    /\ stable = FALSE`)
+	w(`    /\ guard \in [Guards -> BOOLEAN]`)
+	w("")
+
+	w("Send(e) ==")
+	w(`    /\ ~Terminated`)
+	w(`    /\ Len(queue) < QueueBound`)
+	w(`    /\ queue' = Append(queue, e)`)
+	w(`    /\ UNCHANGED <<cfg, history, internal, stable, guard>>`)
+	w("")
+
+	w(`\* Consume one external event once the configuration is stable`)
+	w("External ==")
+	w(`    /\ stable /\ ~Terminated /\ queue # <<>>`)
+	w(`    /\ queue' = Tail(queue)`)
+	w(`    /\ LET sel == Selection(Head(queue)) IN`)
+	w(`       IF sel = {} THEN UNCHANGED <<cfg, history, internal, stable>>`)
+	w(`       ELSE Apply(sel, internal) /\ stable' = FALSE`)
+	w(`    /\ guard' \in [Guards -> BOOLEAN]`)
+	w("")
+
+	w(`\* Eventless transitions, then one done event at a time, until nothing is enabled`)
+	w("Settle ==")
+	w(`    /\ ~stable`)
+	w(`    /\ LET sel == Selection(NoEvent) IN`)
+	w(`       IF sel # {} THEN Apply(sel, internal) /\ UNCHANGED <<queue, stable>>`)
+	w(`       ELSE IF internal # <<>>`)
+	w(`            THEN (LET done == Selection(Head(internal)) IN`)
+	w(`                  IF done # {} THEN Apply(done, Tail(internal)) /\ UNCHANGED <<queue, stable>>`)
+	w(`                  ELSE internal' = Tail(internal) /\ UNCHANGED <<cfg, history, queue, stable>>)`)
+	w(`            ELSE stable' = TRUE /\ UNCHANGED <<cfg, history, queue, internal>>`)
+	w(`    /\ guard' \in [Guards -> BOOLEAN]`)
+	w("")
+
+	w("Next ==")
+	w(`    \/ \E e \in Events : Send(e)`)
+	w(`    \/ External`)
+	w(`    \/ Settle`)
+	w(`    \/ stable /\ Terminated /\ UNCHANGED vars`)
+	w("")
+	w("Spec == Init /\\ [][Next]_vars")
+	w("")
+	w(strings.Repeat("=", 2*len(dashes)+len(header)))
+	return []byte(sb.String()), nil
+}
+
+// tlaValue renders a composite value; h is the new history
+func (md *model) tlaValue(v value) string {
+	switch v := v.(type) {
+	case constant:
+		if v.s == nil {
+			return "None"
+		}
+		return tlaString(md.name(v.s))
+	case recorded:
+		return fmt.Sprintf("h[%s][%s]", tlaString(md.name(v.h)), tlaString(md.name(v.c)))
+	case conditional:
+		cond := fmt.Sprintf("h[%s][%s] # None", tlaString(md.name(v.h)), tlaString(md.name(v.h.Parent)))
+		if v.is != nil {
+			cond = fmt.Sprintf("h[%s][%s] = %s", tlaString(md.name(v.h)), tlaString(md.name(v.h.Parent)), tlaString(md.name(v.is)))
+		}
+		return fmt.Sprintf("(IF %s THEN %s ELSE %s)", cond, md.tlaValue(v.then), md.tlaValue(v.els))
+	}
+	return "None"
+}
+
+func (md *model) names(states []*statechartx.State) []string {
+	names := make([]string, len(states))
+	for i, s := range states {
+		names[i] = md.name(s)
+	}
+	return names
+}
+
+func tlaFunction(parts []string) string {
+	if len(parts) == 0 {
+		return "<<>>"
+	}
+	return strings.Join(parts, " @@ ")
+}
+
+func tlaSet(items []string) string {
+	return "{" + strings.Join(quoteAll(items), ", ") + "}"
+}
+
+func quoteAll(items []string) []string {
+	quoted := make([]string, len(items))
+	for i, item := range items {
+		quoted[i] = tlaString(item)
+	}
+	return quoted
+}
+
+func tlaString(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
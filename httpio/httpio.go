@@ -0,0 +1,271 @@
+// Package httpio implements the SCXML BasicHTTP Event I/O Processor for StatechartX
+// using only net/http.
+//
+// A Handler accepts POSTed events and routes them into named runtimes; a Processor
+// is registered with a runtime and POSTs events sent with
+// SendRequest{Type: httpio.BasicHTTPEventProcessorType, Target: url}.
+//
+// # Wire Format
+//
+// The event name travels in the _scxmleventname parameter, either as a form/query
+// value or as a top-level JSON field. The remaining form values or JSON fields become
+// the event Data (map[string]string for forms, map[string]any for JSON). A POST without
+// an event name is delivered as the event named "HTTP.POST", as the SCXML specification
+// requires.
+//
+// # Example
+//
+//	h := httpio.NewHandler(builder)
+//	h.Register("orders", rt)
+//	http.Handle("/scxml/", http.StripPrefix("/scxml/", h))
+//
+//	rt.RegisterIOProcessor(httpio.NewProcessor(builder))
+//	rt.Send(ctx, statechartx.SendRequest{
+//		Type:   httpio.BasicHTTPEventProcessorType,
+//		Target: "http://billing.local/scxml/invoices",
+//		Event:  statechartx.Event{ID: builder.EventID("paid")},
+//	})
+package httpio
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/comalice/statechartx"
+)
+
+// BasicHTTPEventProcessorType is the SCXML type URI of the BasicHTTP Event I/O Processor.
+const BasicHTTPEventProcessorType = "http://www.w3.org/TR/scxml/#BasicHTTPEventProcessor"
+
+const (
+	// EventNameParam carries the event name in a POST body or query string.
+	EventNameParam = "_scxmleventname"
+
+	// DefaultEventName is used when a POST carries no event name.
+	DefaultEventName = "HTTP.POST"
+
+	// maxBodyBytes limits accepted request bodies.
+	maxBodyBytes = 1 << 20
+)
+
+// Errors returned by the Processor.
+var (
+	ErrUnknownEvent = errors.New("event has no name")
+	ErrBadStatus    = errors.New("unexpected HTTP status")
+)
+
+// EventNames maps wire event names to EventIDs and back.
+// *statechartx.MachineBuilder satisfies this interface.
+type EventNames interface {
+	EventID(name string) statechartx.EventID
+	EventName(id statechartx.EventID) string
+}
+
+// Handler is an http.Handler that delivers POSTed events into registered runtimes.
+// The runtime is selected by the request path (after any prefix stripping), e.g. POST /orders.
+type Handler struct {
+	names    EventNames
+	mu       sync.RWMutex
+	sessions map[string]*statechartx.Runtime
+}
+
+// NewHandler creates a handler that resolves event names with names.
+func NewHandler(names EventNames) *Handler {
+	return &Handler{
+		names:    names,
+		sessions: make(map[string]*statechartx.Runtime),
+	}
+}
+
+// Register routes POST /<name> to rt.
+func (h *Handler) Register(name string, rt *statechartx.Runtime) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.sessions[name] = rt
+}
+
+// Unregister removes the route for name.
+func (h *Handler) Unregister(name string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.sessions, name)
+}
+
+// ServeHTTP implements http.Handler.
+// Responds 204 on delivery, 404 for unknown runtimes, 400 for malformed bodies or
+// unknown event names, 405 for non-POST methods, 413 for bodies over 1 MiB and
+// 503 if delivery fails.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	name := strings.Trim(r.URL.Path, "/")
+	h.mu.RLock()
+	rt, ok := h.sessions[name]
+	h.mu.RUnlock()
+	if !ok {
+		http.Error(w, fmt.Sprintf("no runtime registered as %q", name), http.StatusNotFound)
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxBodyBytes)
+	eventName, data, err := decodeRequest(r)
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		http.Error(w, err.Error(), http.StatusRequestEntityTooLarge)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	eventID := h.names.EventID(eventName)
+	if eventID == statechartx.NO_EVENT {
+		http.Error(w, fmt.Sprintf("unknown event %q", eventName), http.StatusBadRequest)
+		return
+	}
+
+	if err := rt.Deliver(r.Context(), statechartx.Event{ID: eventID, Data: data}); err != nil {
+		http.Error(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// decodeRequest extracts the event name and data from a form or JSON POST
+func decodeRequest(r *http.Request) (string, any, error) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return "", nil, err
+	}
+	eventName := r.URL.Query().Get(EventNameParam)
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType == "application/json" {
+		fields := map[string]any{}
+		if len(bytes.TrimSpace(body)) > 0 {
+			if err := json.Unmarshal(body, &fields); err != nil {
+				return "", nil, fmt.Errorf("invalid JSON body: %w", err)
+			}
+		}
+		if name, ok := fields[EventNameParam].(string); ok {
+			eventName = name
+		}
+		delete(fields, EventNameParam)
+		if eventName == "" {
+			eventName = DefaultEventName
+		}
+		return eventName, fields, nil
+	}
+
+	values, err := url.ParseQuery(string(body))
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid form body: %w", err)
+	}
+	if name := values.Get(EventNameParam); name != "" {
+		eventName = name
+	}
+	values.Del(EventNameParam)
+	if eventName == "" {
+		eventName = DefaultEventName
+	}
+
+	data := make(map[string]string, len(values))
+	for k := range values {
+		data[k] = values.Get(k)
+	}
+	return eventName, data, nil
+}
+
+// Processor is the sending half of the BasicHTTP Event I/O Processor.
+// Register it with a runtime via RegisterIOProcessor; SendRequest.Target is the URL to POST to.
+//
+// Send blocks for the duration of the HTTP request. Use SendRequest.Delay or send from
+// outside actions when the target may be slow.
+type Processor struct {
+	names  EventNames
+	Client *http.Client // defaults to http.DefaultClient
+}
+
+// NewProcessor creates a processor that names events with names.
+func NewProcessor(names EventNames) *Processor {
+	return &Processor{names: names}
+}
+
+// Type implements statechartx.IOProcessor.
+func (p *Processor) Type() string {
+	return BasicHTTPEventProcessorType
+}
+
+// Send implements statechartx.IOProcessor by POSTing the event as a form.
+// Event.Data of type map[string]string, map[string]any or url.Values is sent as form fields.
+func (p *Processor) Send(ctx context.Context, source *statechartx.Runtime, req statechartx.SendRequest) error {
+	eventName := p.names.EventName(req.Event.ID)
+	if eventName == "" {
+		return fmt.Errorf("%w: event %d", ErrUnknownEvent, req.Event.ID)
+	}
+
+	form := encodeData(req.Event.Data)
+	form.Set(EventNameParam, eventName)
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, req.Target, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	httpReq.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	client := p.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(httpReq)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("%w: %s", ErrBadStatus, resp.Status)
+	}
+	return nil
+}
+
+// encodeData converts event data into form fields
+func encodeData(data any) url.Values {
+	form := url.Values{}
+	switch d := data.(type) {
+	case url.Values:
+		for k, vs := range d {
+			form[k] = append([]string(nil), vs...)
+		}
+	case map[string]string:
+		for k, v := range d {
+			form.Set(k, v)
+		}
+	case map[string]any:
+		keys := make([]string, 0, len(d))
+		for k := range d {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			form.Set(k, fmt.Sprint(d[k]))
+		}
+	}
+	return form
+}
@@ -0,0 +1,185 @@
+package httpio
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/comalice/statechartx"
+)
+
+// newOrderRuntime builds pending --paid--> done and records the event data
+func newOrderRuntime(t *testing.T) (*statechartx.Runtime, *statechartx.MachineBuilder, chan any) {
+	t.Helper()
+	received := make(chan any, 1)
+
+	b := statechartx.NewMachineBuilder("order", "pending")
+	b.State("pending").On("paid", "done", nil, func(ctx context.Context, evt *statechartx.Event, from, to statechartx.StateID) error {
+		received <- evt.Data
+		return nil
+	})
+	b.State("done").Atomic()
+
+	machine, err := b.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rt := statechartx.NewRuntime(machine, nil)
+	if err := rt.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { rt.Stop() })
+	return rt, b, received
+}
+
+func waitData(t *testing.T, received chan any) any {
+	t.Helper()
+	select {
+	case data := <-received:
+		return data
+	case <-time.After(time.Second):
+		t.Fatal("event was not delivered")
+		return nil
+	}
+}
+
+func TestHandlerForm(t *testing.T) {
+	rt, b, received := newOrderRuntime(t)
+	h := NewHandler(b)
+	h.Register("orders", rt)
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+
+	resp, err := http.PostForm(srv.URL+"/orders", url.Values{
+		EventNameParam: {"paid"},
+		"amount":       {"42"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("expected 204, got %s", resp.Status)
+	}
+
+	data, ok := waitData(t, received).(map[string]string)
+	if !ok || data["amount"] != "42" {
+		t.Errorf("expected form data amount=42, got %#v", data)
+	}
+	if _, leaked := data[EventNameParam]; leaked {
+		t.Error("event name should not be part of the event data")
+	}
+}
+
+func TestHandlerJSON(t *testing.T) {
+	rt, b, received := newOrderRuntime(t)
+	h := NewHandler(b)
+	h.Register("orders", rt)
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+
+	body := `{"_scxmleventname": "paid", "amount": 42}`
+	resp, err := http.Post(srv.URL+"/orders", "application/json", strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("expected 204, got %s", resp.Status)
+	}
+
+	data, ok := waitData(t, received).(map[string]any)
+	if !ok || data["amount"] != float64(42) {
+		t.Errorf("expected JSON data amount=42, got %#v", data)
+	}
+}
+
+func TestHandlerErrors(t *testing.T) {
+	rt, b, _ := newOrderRuntime(t)
+	h := NewHandler(b)
+	h.Register("orders", rt)
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		form   url.Values
+		status int
+	}{
+		{"unknown runtime", http.MethodPost, "/nobody", url.Values{EventNameParam: {"paid"}}, http.StatusNotFound},
+		{"unknown event", http.MethodPost, "/orders", url.Values{EventNameParam: {"refund"}}, http.StatusBadRequest},
+		{"default event name", http.MethodPost, "/orders", url.Values{}, http.StatusBadRequest},
+		{"wrong method", http.MethodGet, "/orders", nil, http.StatusMethodNotAllowed},
+		{"body too large", http.MethodPost, "/orders", url.Values{EventNameParam: {"paid"}, "pad": {strings.Repeat("x", maxBodyBytes)}}, http.StatusRequestEntityTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(tt.method, srv.URL+tt.path, strings.NewReader(tt.form.Encode()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+			resp, err := http.DefaultClient.Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tt.status {
+				t.Errorf("expected %d, got %s", tt.status, resp.Status)
+			}
+		})
+	}
+}
+
+func TestProcessorRoundTrip(t *testing.T) {
+	receiver, b, received := newOrderRuntime(t)
+	h := NewHandler(b)
+	h.Register("orders", receiver)
+	srv := httptest.NewServer(h)
+	defer srv.Close()
+
+	sender, _, _ := newOrderRuntime(t)
+	sender.RegisterIOProcessor(NewProcessor(b))
+
+	err := sender.Send(context.Background(), statechartx.SendRequest{
+		Type:   BasicHTTPEventProcessorType,
+		Target: srv.URL + "/orders",
+		Event:  statechartx.Event{ID: b.EventID("paid"), Data: map[string]any{"amount": 7}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data, ok := waitData(t, received).(map[string]string)
+	if !ok || data["amount"] != "7" {
+		t.Errorf("expected amount=7, got %#v", data)
+	}
+	if !receiver.IsInState(b.GetID("done")) {
+		t.Error("receiver should transition to done")
+	}
+}
+
+func TestProcessorBadStatus(t *testing.T) {
+	rt, b, _ := newOrderRuntime(t)
+	rt.RegisterIOProcessor(NewProcessor(b))
+
+	srv := httptest.NewServer(http.NotFoundHandler())
+	defer srv.Close()
+
+	err := rt.Send(context.Background(), statechartx.SendRequest{
+		Type:   BasicHTTPEventProcessorType,
+		Target: srv.URL,
+		Event:  statechartx.Event{ID: b.EventID("paid")},
+	})
+	if !errors.Is(err, ErrBadStatus) {
+		t.Errorf("expected ErrBadStatus, got %v", err)
+	}
+}
@@ -0,0 +1,76 @@
+package statechartx
+
+import (
+	"context"
+	"sync"
+)
+
+// idleTracker counts work in flight in a Runtime: queued events (including
+// events waiting in parallel region channels), the event being processed with
+// its microsteps, done events on their way to a queue, and parallel regions
+// still entering their initial states. Work is added before it becomes visible
+// to another goroutine, so the count never drops to zero between an action
+// raising an event and the event being queued.
+type idleTracker struct {
+	mu      sync.Mutex
+	pending int
+	wake    chan struct{} // closed when pending drops to zero
+}
+
+func (t *idleTracker) add(n int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.pending += n
+	if t.pending < 0 {
+		panic("statechartx: negative idle count")
+	}
+	if t.pending == 0 && t.wake != nil {
+		close(t.wake)
+		t.wake = nil
+	}
+}
+
+func (t *idleTracker) done() {
+	t.add(-1)
+}
+
+// wait blocks until pending is zero, ctx is done or stopped is closed
+func (t *idleTracker) wait(ctx context.Context, stopped <-chan struct{}) error {
+	for {
+		t.mu.Lock()
+		if t.pending == 0 {
+			t.mu.Unlock()
+			return nil
+		}
+		if t.wake == nil {
+			t.wake = make(chan struct{})
+		}
+		wake := t.wake
+		t.mu.Unlock()
+
+		select {
+		case <-wake:
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-stopped:
+			return ErrRuntimeStopped
+		}
+	}
+}
+
+// WaitIdle blocks until the runtime is quiescent: the event queue and every
+// parallel region queue are empty, no event or microstep is being processed,
+// no done event is in flight and all regions have entered their initial states.
+// It returns ctx.Err() if ctx ends first and ErrRuntimeStopped if the runtime
+// is stopped while work is still pending.
+//
+// Delayed sends (see Send) are not pending work: WaitIdle returns while they
+// wait for their timers. Runtimes using ParallelHooks track their own regions;
+// the realtime package provides its own WaitIdle.
+func (rt *Runtime) WaitIdle(ctx context.Context) error {
+	var stopped <-chan struct{}
+	if rt.ctx != nil {
+		stopped = rt.ctx.Done()
+	}
+	return rt.idle.wait(ctx, stopped)
+}
@@ -0,0 +1,128 @@
+package statechartx_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/comalice/statechartx"
+)
+
+// raiseOnEntry returns an entry action that sends name to *rt
+func raiseOnEntry(rt **Runtime, b *MachineBuilder, name string) Action {
+	return func(ctx context.Context, evt *Event, from, to StateID) error {
+		return (*rt).SendEvent(ctx, Event{ID: b.EventID(name)})
+	}
+}
+
+func TestWaitIdleEventChain(t *testing.T) {
+	var rt *Runtime
+	b := NewMachineBuilder("chain", "a")
+	b.State("a").On("go", "b", nil, nil)
+	b.State("b").Entry(raiseOnEntry(&rt, b, "next")).On("next", "c", nil, nil)
+	b.State("c").Entry(raiseOnEntry(&rt, b, "next")).On("next", "d", nil, nil)
+	b.State("d").On("", "done", nil, nil)
+	b.State("done").Final(nil)
+	machine, err := b.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rt = NewRuntime(machine, nil)
+	ctx := context.Background()
+	if err := rt.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	defer rt.Stop()
+
+	if err := rt.SendEvent(ctx, Event{ID: b.EventID("go")}); err != nil {
+		t.Fatal(err)
+	}
+	if err := rt.WaitIdle(ctx); err != nil {
+		t.Fatalf("WaitIdle failed: %v", err)
+	}
+	if !rt.IsInState(b.GetID("done")) {
+		t.Errorf("expected done after WaitIdle, got %s", b.GetName(rt.GetCurrentState()))
+	}
+}
+
+func TestWaitIdleParallelRegions(t *testing.T) {
+	var rt *Runtime
+	b := NewMachineBuilder("job", "work")
+	b.State("work").Parallel()
+	b.State("work.left").Compound("work.left.idle")
+	b.State("work.left.idle").On("start", "work.left.busy", nil, nil)
+	b.State("work.left.busy").Entry(raiseOnEntry(&rt, b, "step")).On("step", "work.left.done", nil, nil)
+	b.State("work.left.done").Atomic()
+	b.State("work.right").Compound("work.right.idle")
+	b.State("work.right.idle").On("start", "work.right.done", nil, nil)
+	b.State("work.right.done").Atomic()
+	machine, err := b.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rt = NewRuntime(machine, nil)
+	ctx := context.Background()
+	if err := rt.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	defer rt.Stop()
+
+	// Regions enter their initial states on their own goroutines
+	if err := rt.WaitIdle(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if !rt.IsInState(b.GetID("work.left.idle")) || !rt.IsInState(b.GetID("work.right.idle")) {
+		t.Fatal("regions should have entered their initial states")
+	}
+
+	if err := rt.SendEvent(ctx, Event{ID: b.EventID("start")}); err != nil {
+		t.Fatal(err)
+	}
+	if err := rt.WaitIdle(ctx); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"work.left.done", "work.right.done"} {
+		if !rt.IsInState(b.GetID(name)) {
+			t.Errorf("expected %s to be active after WaitIdle", name)
+		}
+	}
+}
+
+func TestWaitIdleTimeout(t *testing.T) {
+	release := make(chan struct{})
+	b := NewMachineBuilder("app", "a")
+	b.State("a").On("go", "b", nil, func(ctx context.Context, evt *Event, from, to StateID) error {
+		<-release
+		return nil
+	})
+	b.State("b").Atomic()
+	machine, err := b.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rt := NewRuntime(machine, nil)
+	ctx := context.Background()
+	if err := rt.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	defer rt.Stop()
+
+	rt.SendEvent(ctx, Event{ID: b.EventID("go")})
+	waitCtx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+	defer cancel()
+	if err := rt.WaitIdle(waitCtx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected deadline exceeded while the action blocks, got %v", err)
+	}
+
+	close(release)
+	if err := rt.WaitIdle(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if !rt.IsInState(b.GetID("b")) {
+		t.Errorf("expected b, got %s", b.GetName(rt.GetCurrentState()))
+	}
+}
@@ -0,0 +1,292 @@
+package statechartx
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// MachineFormatVersion is the current version of the JSON machine definition format.
+const MachineFormatVersion = 1
+
+// JSON definition errors
+var (
+	ErrUnsupportedVersion = errors.New("unsupported machine definition version")
+	ErrUnnamedBehavior    = errors.New("guard, action or data has no registry name")
+)
+
+// State types used by the JSON definition format.
+const (
+	stateTypeAtomic   = "atomic"
+	stateTypeCompound = "compound"
+	stateTypeParallel = "parallel"
+	stateTypeFinal    = "final"
+	stateTypeHistory  = "history"
+)
+
+// MachineDefinition is the versioned JSON representation of a machine.
+// State IDs are the MachineBuilder names (dot-separated paths), events are
+// MachineBuilder event names, and guards/actions/final data are Registry names.
+//
+// Example:
+//
+//	{
+//	  "version": 1,
+//	  "id": "light",
+//	  "initial": "green",
+//	  "states": [
+//	    {"id": "green", "transitions": [{"event": "timer", "target": "yellow", "guard": "carsWaiting"}]},
+//	    {"id": "yellow", "entry": ["startTimer"], "transitions": [{"event": "timer", "target": "green"}]}
+//	  ]
+//	}
+type MachineDefinition struct {
+	Version int               `json:"version"`
+	ID      string            `json:"id"`
+	Initial string            `json:"initial"`
+	States  []StateDefinition `json:"states,omitempty"`
+}
+
+// StateDefinition is the JSON representation of a state and its children.
+type StateDefinition struct {
+	ID          string                 `json:"id"`
+	Type        string                 `json:"type,omitempty"`    // atomic (default), compound, parallel, final, history
+	Initial     string                 `json:"initial,omitempty"` // compound states
+	History     string                 `json:"history,omitempty"` // history states: shallow or deep
+	Default     string                 `json:"default,omitempty"` // history states: default target
+	FinalData   string                 `json:"finalData,omitempty"`
+	Entry       []string               `json:"entry,omitempty"`
+	Exit        []string               `json:"exit,omitempty"`
+	Transitions []TransitionDefinition `json:"transitions,omitempty"`
+	States      []StateDefinition      `json:"states,omitempty"`
+}
+
+// TransitionDefinition is the JSON representation of a transition.
+// An empty Event is an eventless transition; an empty Target is an internal transition.
+type TransitionDefinition struct {
+	Event   string   `json:"event,omitempty"`
+	Target  string   `json:"target,omitempty"`
+	Guard   string   `json:"guard,omitempty"`
+	Actions []string `json:"actions,omitempty"`
+}
+
+// MarshalMachine encodes m as an indented JSON machine definition, naming states and
+// events with names (typically the *MachineBuilder that built m; nil uses "s<id>"/"e<id>").
+//
+// Guards, actions and final data must carry registry names (see StateBuilder.OnRef,
+// EntryRef, ExitRef and FinalRef); anonymous functions cannot be serialized and
+// produce an error wrapping ErrUnnamedBehavior.
+func MarshalMachine(m *Machine, names Namer) ([]byte, error) {
+	def, err := DefineMachine(m, names)
+	if err != nil {
+		return nil, err
+	}
+	return json.MarshalIndent(def, "", "  ")
+}
+
+// DefineMachine converts m into a MachineDefinition. See MarshalMachine.
+func DefineMachine(m *Machine, names Namer) (*MachineDefinition, error) {
+	root := m.Root()
+	if root == nil {
+		return nil, errors.New("machine has no root state")
+	}
+	if len(root.Transitions) > 0 || root.EntryAction != nil || len(root.EntryActions) > 0 ||
+		root.ExitAction != nil || len(root.ExitActions) > 0 {
+		return nil, errors.New("root state transitions and actions cannot be serialized")
+	}
+
+	def := &MachineDefinition{
+		Version: MachineFormatVersion,
+		ID:      StateNameOf(names, root.ID),
+	}
+	if root.Initial != 0 {
+		def.Initial = StateNameOf(names, root.Initial)
+	}
+
+	for _, child := range root.SortedChildren() {
+		sd, err := defineState(child, names)
+		if err != nil {
+			return nil, err
+		}
+		def.States = append(def.States, sd)
+	}
+	return def, nil
+}
+
+// defineState converts a state subtree into its definition
+func defineState(s *State, names Namer) (StateDefinition, error) {
+	name := StateNameOf(names, s.ID)
+	sd := StateDefinition{ID: name}
+
+	switch {
+	case s.IsHistoryState:
+		sd.Type = stateTypeHistory
+		sd.History = "shallow"
+		if s.HistoryType == HistoryDeep {
+			sd.History = "deep"
+		}
+		if s.HistoryDefault != 0 {
+			sd.Default = StateNameOf(names, s.HistoryDefault)
+		}
+	case s.IsParallel:
+		sd.Type = stateTypeParallel
+	case s.IsFinal || s.Final:
+		sd.Type = stateTypeFinal
+		if s.FinalStateData != nil && s.FinalDataRef == "" {
+			return sd, fmt.Errorf("%w: final data of state %s", ErrUnnamedBehavior, name)
+		}
+		sd.FinalData = s.FinalDataRef
+	case len(s.Children) > 0:
+		sd.Type = stateTypeCompound
+	}
+	if s.Initial != 0 && !s.IsParallel {
+		sd.Initial = StateNameOf(names, s.Initial)
+	}
+
+	if s.EntryAction != nil || s.ExitAction != nil || s.InitialAction != nil || len(s.InitialActions) > 0 ||
+		len(s.EntryActions) != len(s.EntryRefs) || len(s.ExitActions) != len(s.ExitRefs) {
+		return sd, fmt.Errorf("%w: entry/exit/initial action of state %s", ErrUnnamedBehavior, name)
+	}
+	sd.Entry = append([]string(nil), s.EntryRefs...)
+	sd.Exit = append([]string(nil), s.ExitRefs...)
+
+	for _, t := range s.Transitions {
+		if t == nil {
+			continue
+		}
+		if (t.Guard != nil && t.GuardRef == "") || t.Action != nil || len(t.Actions) != len(t.ActionRefs) {
+			return sd, fmt.Errorf("%w: transition on %q from state %s", ErrUnnamedBehavior, EventNameOf(names, t.Event), name)
+		}
+		td := TransitionDefinition{
+			Event:   EventNameOf(names, t.Event),
+			Guard:   t.GuardRef,
+			Actions: append([]string(nil), t.ActionRefs...),
+		}
+		if t.Target != 0 {
+			td.Target = StateNameOf(names, t.Target)
+		}
+		sd.Transitions = append(sd.Transitions, td)
+	}
+
+	for _, child := range s.SortedChildren() {
+		cd, err := defineState(child, names)
+		if err != nil {
+			return sd, err
+		}
+		sd.States = append(sd.States, cd)
+	}
+	return sd, nil
+}
+
+// UnmarshalMachine decodes a JSON machine definition into a MachineBuilder whose
+// guards, actions and final data are resolved from reg. Call Build on the result
+// to obtain the Machine; the builder also serves as the Namer for MarshalMachine.
+func UnmarshalMachine(data []byte, reg *Registry) (*MachineBuilder, error) {
+	var def MachineDefinition
+	if err := json.Unmarshal(data, &def); err != nil {
+		return nil, err
+	}
+	return def.Builder(reg)
+}
+
+// Builder replays the definition into a new MachineBuilder using reg to resolve names.
+func (def *MachineDefinition) Builder(reg *Registry) (*MachineBuilder, error) {
+	if def.Version != MachineFormatVersion {
+		return nil, fmt.Errorf("%w: %d", ErrUnsupportedVersion, def.Version)
+	}
+	if def.ID == "" {
+		return nil, errors.New("machine definition has no id")
+	}
+	if def.Initial == "" && len(def.States) > 0 {
+		return nil, errors.New("machine definition has no initial state")
+	}
+	if reg == nil {
+		reg = NewRegistry()
+	}
+
+	// Assign state IDs in document order so that sibling order survives a round trip
+	// even when transitions reference states declared later.
+	b := &MachineBuilder{
+		nextID:   1,
+		nameToID: map[string]StateID{def.ID: 0},
+		idToName: map[StateID]string{0: def.ID},
+	}
+	var declare func(states []StateDefinition)
+	declare = func(states []StateDefinition) {
+		for i := range states {
+			b.assignID(states[i].ID)
+			declare(states[i].States)
+		}
+	}
+	declare(def.States)
+	b.initRoot(def.ID, def.Initial)
+	b.WithRegistry(reg)
+
+	for i := range def.States {
+		if err := defineInBuilder(b, "", &def.States[i]); err != nil {
+			return nil, err
+		}
+	}
+	return b, nil
+}
+
+// defineInBuilder adds a state definition and its children to the builder
+func defineInBuilder(b *MachineBuilder, parent string, sd *StateDefinition) error {
+	if sd.ID == "" {
+		return errors.New("state definition has no id")
+	}
+	if parentPath, _ := splitPath(sd.ID); parentPath != parent {
+		return fmt.Errorf("state %s must be named %s.<name> to nest under %q", sd.ID, parent, parent)
+	}
+
+	sb := b.State(sd.ID)
+	switch sd.Type {
+	case "", stateTypeAtomic:
+		if len(sd.States) > 0 {
+			return fmt.Errorf("atomic state %s cannot have children", sd.ID)
+		}
+	case stateTypeCompound:
+		if sd.Initial == "" {
+			return fmt.Errorf("compound state %s has no initial state", sd.ID)
+		}
+		sb.Compound(sd.Initial)
+	case stateTypeParallel:
+		sb.Parallel()
+	case stateTypeFinal:
+		if sd.FinalData != "" {
+			sb.FinalRef(sd.FinalData)
+		} else {
+			sb.Final(nil)
+		}
+	case stateTypeHistory:
+		switch sd.History {
+		case "", "shallow":
+			sb.History(HistoryShallow, sd.Default)
+		case "deep":
+			sb.History(HistoryDeep, sd.Default)
+		default:
+			return fmt.Errorf("history state %s has unknown history type %q", sd.ID, sd.History)
+		}
+	default:
+		return fmt.Errorf("state %s has unknown type %q", sd.ID, sd.Type)
+	}
+	if len(sd.Entry) > 0 {
+		sb.EntryRef(sd.Entry...)
+	}
+	if len(sd.Exit) > 0 {
+		sb.ExitRef(sd.Exit...)
+	}
+	for _, td := range sd.Transitions {
+		if td.Target == "" {
+			sb.OnInternalRef(td.Event, td.Guard, td.Actions...)
+		} else {
+			sb.OnRef(td.Event, td.Target, td.Guard, td.Actions...)
+		}
+	}
+
+	for i := range sd.States {
+		if err := defineInBuilder(b, sd.ID, &sd.States[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
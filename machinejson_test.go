@@ -0,0 +1,155 @@
+package statechartx_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/comalice/statechartx"
+)
+
+// newJSONTestRegistry registers the guards, actions and data used by buildJSONTestChart
+func newJSONTestRegistry(entered *[]string) *Registry {
+	reg := NewRegistry()
+	reg.RegisterGuard("hasPower", Always())
+	reg.RegisterGuard("overheated", Never())
+	reg.RegisterAction("logEntry", func(ctx context.Context, evt *Event, from, to StateID) error {
+		*entered = append(*entered, "logEntry")
+		return nil
+	})
+	reg.RegisterAction("beep", func(ctx context.Context, evt *Event, from, to StateID) error {
+		return nil
+	})
+	reg.RegisterData("result", map[string]any{"ok": true})
+	return reg
+}
+
+func buildJSONTestChart(reg *Registry) *MachineBuilder {
+	b := NewMachineBuilder("device", "off").WithRegistry(reg)
+	b.State("off").OnRef("power", "on", "hasPower", "beep")
+	b.State("on").Compound("on.idle").EntryRef("logEntry").
+		OnRef("power", "off", "").
+		OnRef("resume", "on.hist", "").
+		OnRef("done.state.on", "finished", "")
+	b.State("on.idle").OnRef("work", "on.busy", "overheated").OnRef("work", "on.busy", "").
+		OnRef("after.on.idle.5", "off", "")
+	b.State("on.busy").Compound("on.busy.step1").OnInternalRef("tick", "", "beep")
+	b.State("on.busy.step1").OnRef("", "on.busy.step2", "")
+	b.State("on.busy.step2").Atomic()
+	b.State("on.hist").History(HistoryDeep, "on.idle")
+	b.State("finished").FinalRef("result")
+	b.State("both").Parallel()
+	b.State("both.left").Atomic()
+	b.State("both.right").Atomic()
+	return b
+}
+
+func TestMachineJSONRoundTrip(t *testing.T) {
+	var entered []string
+	reg := newJSONTestRegistry(&entered)
+
+	b := buildJSONTestChart(reg)
+	machine, err := b.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first, err := MarshalMachine(machine, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b2, err := UnmarshalMachine(first, reg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	machine2, err := b2.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	second, err := MarshalMachine(machine2, b2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(first) != string(second) {
+		t.Errorf("round trip changed the definition:\n%s\n---\n%s", first, second)
+	}
+
+	var def MachineDefinition
+	if err := json.Unmarshal(first, &def); err != nil {
+		t.Fatal(err)
+	}
+	if def.Version != MachineFormatVersion || def.ID != "device" || def.Initial != "off" {
+		t.Errorf("unexpected header: %+v", def)
+	}
+	for _, want := range []string{`"type": "history"`, `"history": "deep"`, `"finalData": "result"`, `"event": "done.state.on"`, `"event": "after.on.idle.5"`, `"type": "parallel"`} {
+		if !strings.Contains(string(first), want) {
+			t.Errorf("definition should contain %s", want)
+		}
+	}
+
+	// The loaded machine behaves like the original
+	rt := NewRuntime(machine2, nil)
+	ctx := context.Background()
+	if err := rt.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	defer rt.Stop()
+
+	rt.SendEvent(ctx, Event{ID: b2.EventID("power")})
+	rt.SendEvent(ctx, Event{ID: b2.EventID("work")})
+	time.Sleep(50 * time.Millisecond)
+
+	if !rt.IsInState(b2.GetID("on.busy.step2")) {
+		t.Errorf("expected on.busy.step2 after eventless transition, got %s", b2.GetName(rt.GetCurrentState()))
+	}
+	if len(entered) != 1 {
+		t.Errorf("expected entry action to run once, ran %d times", len(entered))
+	}
+}
+
+func TestMarshalMachineRejectsUnnamedFunctions(t *testing.T) {
+	b := NewMachineBuilder("app", "a")
+	b.State("a").On("go", "b", Always(), nil)
+	b.State("b").Atomic()
+	machine, err := b.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := MarshalMachine(machine, b); !errors.Is(err, ErrUnnamedBehavior) {
+		t.Errorf("expected ErrUnnamedBehavior, got %v", err)
+	}
+}
+
+func TestUnmarshalMachineErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		json string
+		want error
+	}{
+		{"version", `{"version": 2, "id": "m", "initial": "a", "states": [{"id": "a"}]}`, ErrUnsupportedVersion},
+		{"unknown guard", `{"version": 1, "id": "m", "initial": "a", "states": [{"id": "a", "transitions": [{"event": "go", "target": "a", "guard": "nope"}]}]}`, ErrUnknownName},
+		{"bad nesting", `{"version": 1, "id": "m", "initial": "p", "states": [{"id": "p", "type": "compound", "initial": "q.x", "states": [{"id": "q.x"}]}]}`, nil},
+		{"unknown type", `{"version": 1, "id": "m", "initial": "a", "states": [{"id": "a", "type": "weird"}]}`, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := UnmarshalMachine([]byte(tt.json), NewRegistry())
+			if err == nil {
+				_, err = b.Build()
+			}
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			if tt.want != nil && !errors.Is(err, tt.want) {
+				t.Errorf("expected %v, got %v", tt.want, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,296 @@
+// Package modelcheck explores the abstract state space of a statechartx machine and
+// answers questions about it before the chart is deployed.
+//
+// Explore builds the configuration graph of a *statechartx.Machine: every combination
+// of active states (and recorded history) reachable by sending events, with an edge for
+// each event that changes it. Guards are treated as nondeterministic unless a
+// GuardEvaluator decides them, so a query that holds on the graph holds for every guard
+// implementation. Queries return counterexamples as event sequences that can be replayed
+// against a runtime:
+//
+//	g, err := modelcheck.Explore(machine, modelcheck.Options{})
+//	if path, ok := g.Reachable(b.GetID("error")); ok {
+//		fmt.Println(path.Format(b)) // the events that lead to "error"
+//	}
+//	if path, ok := g.DeadlockFree(); !ok {
+//		fmt.Println("deadlock after", path.Events())
+//	}
+//
+// # Semantics
+//
+// Exploration follows SCXML macrostep semantics, which both runtimes implement for
+// deterministic charts:
+//
+//   - An event selects, for each active atomic state in document order, the first enabled
+//     transition searching outward through its ancestors (exact events before ANY_EVENT).
+//     Transitions whose exit sets overlap an already selected transition are dropped.
+//   - A transition exits the active states below the least common ancestor of the active
+//     state and the target (the parent for self-transitions), then enters the target and
+//     its initial descendants. Parallel states enter every region.
+//   - Eventless transitions are then taken until none is enabled, followed by done.state
+//     events (one at a time, each followed by eventless transitions). More than
+//     MAX_MICROSTEPS steps marks the edge as Overflow.
+//   - History is recorded when its parent state exits and restored when targeted.
+//   - Reaching a final child of the root ends the run (the node is Final).
+//
+// Actions are not executed and event data is not modelled.
+package modelcheck
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/comalice/statechartx"
+)
+
+// DefaultMaxConfigurations bounds exploration when Options.MaxConfigurations is zero.
+const DefaultMaxConfigurations = 100000
+
+// Outcome is a guard result decided by a GuardEvaluator.
+type Outcome int
+
+const (
+	// Unknown explores both results of the guard.
+	Unknown Outcome = iota
+	// True assumes the guard passes.
+	True
+	// False assumes the guard fails.
+	False
+)
+
+// GuardEvaluator decides guards abstractly from the transition and the active atomic
+// states. Returning Unknown makes the guard nondeterministic.
+type GuardEvaluator func(t *statechartx.Transition, active []statechartx.StateID) Outcome
+
+// Options control exploration.
+type Options struct {
+	// Events is the external event alphabet. If empty, every event used by a
+	// transition is explored (except eventless and done.state events).
+	Events []statechartx.EventID
+
+	// Guards decides guard outcomes. If nil, every guard is nondeterministic.
+	Guards GuardEvaluator
+
+	// MaxConfigurations stops exploration after this many nodes (Graph.Truncated is set).
+	MaxConfigurations int
+}
+
+// Assumption records the result a guard was assumed to return along an edge.
+type Assumption struct {
+	Transition *statechartx.Transition
+	Value      bool
+	Decided    bool // the GuardEvaluator returned Value; otherwise it was a free choice
+}
+
+// Edge is a macrostep: the configuration reached by sending Event.
+type Edge struct {
+	Event       statechartx.EventID
+	To          *Node
+	Assumptions []Assumption              // guard results the macrostep depends on
+	Fired       []*statechartx.Transition // transitions taken, in order
+	Overflow    bool                      // eventless transitions were still enabled after MAX_MICROSTEPS
+}
+
+// Node is a reachable configuration.
+type Node struct {
+	ID      int
+	Active  []statechartx.StateID // active atomic states, ascending
+	History map[statechartx.StateID][]statechartx.StateID
+	Final   bool // a final child of the root is active; no events are processed
+	Edges   []Edge
+	// Incomplete is set when some successors were dropped because MaxConfigurations
+	// was reached, so Edges does not describe every way to leave the node.
+	Incomplete bool
+
+	states map[statechartx.StateID]bool
+	key    string
+}
+
+// InState reports whether a state is active in n (atomic states and their ancestors).
+func (n *Node) InState(id statechartx.StateID) bool {
+	return n.states[id]
+}
+
+// Graph is the explored configuration graph.
+type Graph struct {
+	Machine *statechartx.Machine
+	Events  []statechartx.EventID
+	// Initial holds the start edges, one per guard outcome of the initial
+	// eventless transitions. Their Event is NO_EVENT.
+	Initial   []Edge
+	Nodes     []*Node
+	Truncated bool // MaxConfigurations was reached; negative answers are inconclusive
+
+	pred map[*Node]predecessor // breadth-first predecessors, computed once by Explore
+}
+
+// ErrNoRoot is returned for machines without a root state.
+var ErrNoRoot = errors.New("machine has no root state")
+
+// Explore builds the configuration graph of m.
+func Explore(m *statechartx.Machine, opts Options) (*Graph, error) {
+	root := m.Root()
+	if root == nil {
+		return nil, ErrNoRoot
+	}
+	if opts.MaxConfigurations <= 0 {
+		opts.MaxConfigurations = DefaultMaxConfigurations
+	}
+
+	x := &explorer{m: m, opts: opts, index: map[string]*Node{}}
+	x.g = &Graph{Machine: m, Events: opts.Events}
+	if len(x.g.Events) == 0 {
+		x.g.Events = alphabet(m)
+	}
+
+	var queue []*Node
+	visit := func(r run) *Node {
+		key := r.cfg.key()
+		if n, ok := x.index[key]; ok {
+			return n
+		}
+		if len(x.g.Nodes) >= opts.MaxConfigurations {
+			x.g.Truncated = true
+			return nil
+		}
+		n := x.newNode(r.cfg, key)
+		queue = append(queue, n)
+		return n
+	}
+
+	for _, r := range x.start() {
+		if n := visit(r); n != nil {
+			x.g.Initial = appendEdge(x.g.Initial, r.edge(statechartx.NO_EVENT, n))
+		}
+	}
+
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+		if n.Final {
+			continue
+		}
+		cfg := config{active: n.Active, history: n.History}
+		for _, ev := range x.g.Events {
+			for _, r := range x.macrostep(cfg, ev) {
+				if to := visit(r); to != nil {
+					n.Edges = appendEdge(n.Edges, r.edge(ev, to))
+				} else {
+					n.Incomplete = true
+				}
+			}
+		}
+	}
+	x.g.pred = x.g.bfs()
+	return x.g, nil
+}
+
+// alphabet returns every event that triggers a transition in m, ascending
+func alphabet(m *statechartx.Machine) []statechartx.EventID {
+	seen := map[statechartx.EventID]bool{}
+	var events []statechartx.EventID
+	for _, id := range m.StateIDs() {
+		for _, t := range m.GetState(id).Transitions {
+			if t == nil || t.Event == statechartx.NO_EVENT || isRaisedEvent(t.Event) || seen[t.Event] {
+				continue
+			}
+			seen[t.Event] = true
+			events = append(events, t.Event)
+		}
+	}
+	sort.Slice(events, func(i, j int) bool { return events[i] < events[j] })
+	return events
+}
+
+// isRaisedEvent reports whether id is raised by a runtime rather than sent:
+// done events, and timer events such as realtime.AfterTicks (which lie below them)
+func isRaisedEvent(id statechartx.EventID) bool {
+	return id <= statechartx.DoneEventID(0)
+}
+
+// appendEdge adds e unless an identical edge is already present
+func appendEdge(edges []Edge, e Edge) []Edge {
+	for _, existing := range edges {
+		if existing.Event == e.Event && existing.To == e.To && sameAssumptions(existing.Assumptions, e.Assumptions) {
+			return edges
+		}
+	}
+	return append(edges, e)
+}
+
+func sameAssumptions(a, b []Assumption) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// GuardName names a transition's guard: its registry name, or "<source>[<index>]"
+// for anonymous guards.
+func GuardName(t *statechartx.Transition, names statechartx.Namer) string {
+	if t.GuardRef != "" {
+		return t.GuardRef
+	}
+	if t.Source == nil {
+		return "guard"
+	}
+	for i, candidate := range t.Source.Transitions {
+		if candidate == t {
+			return statechartx.StateNameOf(names, t.Source.ID) + "[" + strconv.Itoa(i) + "]"
+		}
+	}
+	return statechartx.StateNameOf(names, t.Source.ID) + "[?]"
+}
+
+// config is an abstract configuration: active atomic states and recorded history,
+// keyed by history state ID.
+type config struct {
+	active  []statechartx.StateID
+	history map[statechartx.StateID][]statechartx.StateID
+}
+
+func (c config) key() string {
+	var sb strings.Builder
+	for _, id := range c.active {
+		fmt.Fprintf(&sb, "%d,", id)
+	}
+	hist := make([]statechartx.StateID, 0, len(c.history))
+	for h := range c.history {
+		hist = append(hist, h)
+	}
+	sort.Slice(hist, func(i, j int) bool { return hist[i] < hist[j] })
+	for _, h := range hist {
+		fmt.Fprintf(&sb, "|%d:", h)
+		for _, id := range c.history[h] {
+			fmt.Fprintf(&sb, "%d,", id)
+		}
+	}
+	return sb.String()
+}
+
+func (x *explorer) newNode(cfg config, key string) *Node {
+	n := &Node{
+		ID:      len(x.g.Nodes),
+		Active:  cfg.active,
+		History: cfg.history,
+		states:  map[statechartx.StateID]bool{},
+		key:     key,
+	}
+	for _, id := range cfg.active {
+		for s := x.m.GetState(id); s != nil; s = s.Parent {
+			n.states[s.ID] = true
+		}
+	}
+	n.Final = x.complete(x.m.Root(), n.states)
+	x.g.Nodes = append(x.g.Nodes, n)
+	x.index[key] = n
+	return n
+}
@@ -0,0 +1,234 @@
+package modelcheck_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/comalice/statechartx"
+	"github.com/comalice/statechartx/modelcheck"
+)
+
+func explore(t *testing.T, b *statechartx.MachineBuilder, opts modelcheck.Options) (*statechartx.Machine, *modelcheck.Graph) {
+	t.Helper()
+	machine, err := b.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, err := modelcheck.Explore(machine, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return machine, g
+}
+
+func eventNames(b *statechartx.MachineBuilder, events []statechartx.EventID) string {
+	names := make([]string, len(events))
+	for i, e := range events {
+		names[i] = b.EventName(e)
+	}
+	return strings.Join(names, " ")
+}
+
+func TestReachabilityAndReplay(t *testing.T) {
+	b := statechartx.NewMachineBuilder("device", "off")
+	b.State("off").On("power", "on", nil, nil)
+	b.State("on").Compound("on.idle").On("power", "off", nil, nil)
+	b.State("on.idle").On("work", "on.busy", nil, nil)
+	b.State("on.busy").On("fail", "broken", nil, nil).On("done", "on.idle", nil, nil)
+	b.State("broken").Atomic()
+	machine, g := explore(t, b, modelcheck.Options{})
+
+	if len(g.Nodes) != 4 || g.Truncated {
+		t.Fatalf("expected 4 configurations, got %d (truncated=%v)", len(g.Nodes), g.Truncated)
+	}
+
+	path, ok := g.Reachable(b.GetID("broken"))
+	if !ok {
+		t.Fatal("broken should be reachable")
+	}
+	if got := eventNames(b, path.Events()); got != "power work fail" {
+		t.Errorf("expected shortest path 'power work fail', got %q\n%s", got, path.Format(b))
+	}
+
+	// The counterexample replays on the real runtime
+	rt := statechartx.NewRuntime(machine, nil)
+	ctx := context.Background()
+	if err := rt.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	defer rt.Stop()
+	for _, e := range path.Events() {
+		rt.SendEvent(ctx, statechartx.Event{ID: e})
+	}
+	time.Sleep(50 * time.Millisecond)
+	if !rt.IsInState(b.GetID("broken")) {
+		t.Errorf("replaying %v should reach broken", path.Events())
+	}
+
+	// broken has no way out
+	path, ok = g.DeadlockFree()
+	if ok || !path.Last().InState(b.GetID("broken")) {
+		t.Errorf("expected a deadlock in broken, got ok=%v path=%v", ok, path)
+	}
+}
+
+func TestGuardsAreNondeterministic(t *testing.T) {
+	b := statechartx.NewMachineBuilder("app", "idle")
+	b.State("idle").
+		On("go", "fast", statechartx.Never(), nil).
+		On("go", "slow", nil, nil)
+	b.State("fast").On("reset", "idle", nil, nil)
+	b.State("slow").On("reset", "idle", nil, nil)
+
+	_, g := explore(t, b, modelcheck.Options{})
+	path, ok := g.Reachable(b.GetID("fast"))
+	if !ok {
+		t.Fatal("fast should be reachable when guards are nondeterministic")
+	}
+	if last := path[len(path)-1]; len(last.Assumptions) != 1 || !last.Assumptions[0].Value {
+		t.Errorf("path should assume the guard passes, got %+v", last.Assumptions)
+	}
+	if !strings.Contains(path.Format(b), "go [idle[0]] -> fast") {
+		t.Errorf("unexpected format:\n%s", path.Format(b))
+	}
+	if _, ok := g.Reachable(b.GetID("slow")); !ok {
+		t.Error("slow should be reachable when the guard fails")
+	}
+	if _, ok := g.DeadlockFree(); !ok {
+		t.Error("chart should be deadlock-free")
+	}
+
+	// An evaluator that decides the guard prunes the branch
+	_, g = explore(t, b, modelcheck.Options{
+		Guards: func(*statechartx.Transition, []statechartx.StateID) modelcheck.Outcome { return modelcheck.False },
+	})
+	if _, ok := g.Reachable(b.GetID("fast")); ok {
+		t.Error("fast should be unreachable when the evaluator rejects the guard")
+	}
+}
+
+func TestParallelInterleavingsAndDoneEvents(t *testing.T) {
+	b := statechartx.NewMachineBuilder("job", "work")
+	b.State("work").Parallel().On("done.state.work", "finished", nil, nil)
+	b.State("work.left").Compound("work.left.a")
+	b.State("work.left.a").On("x", "work.left.done", nil, nil)
+	b.State("work.left.done").Final(nil)
+	b.State("work.right").Compound("work.right.a")
+	b.State("work.right.a").On("y", "work.right.done", nil, nil)
+	b.State("work.right.done").Final(nil)
+	b.State("finished").Final(nil)
+
+	_, g := explore(t, b, modelcheck.Options{})
+
+	// {a,a} {done,a} {a,done} {finished}
+	if len(g.Nodes) != 4 {
+		t.Errorf("expected 4 configurations, got %d", len(g.Nodes))
+	}
+	path, ok := g.Reachable(b.GetID("finished"))
+	if !ok || len(path.Events()) != 2 {
+		t.Fatalf("finished should be reachable after both regions finish, got %v", path)
+	}
+	if !path.Last().Final {
+		t.Error("reaching a final child of the root should end the run")
+	}
+	if _, ok := g.DeadlockFree(); !ok {
+		t.Error("chart should be deadlock-free")
+	}
+	if _, ok := g.EventuallyWithin(b.GetID("work"), b.GetID("finished"), 2); !ok {
+		t.Error("every path from work should finish within two events")
+	}
+	if path, ok := g.EventuallyWithin(b.GetID("work"), b.GetID("finished"), 1); ok {
+		t.Error("one event is not enough to finish")
+	} else if len(path.Events()) != 1 {
+		t.Errorf("counterexample should send one event, got %v", path.Events())
+	}
+}
+
+func TestHistoryAndEventless(t *testing.T) {
+	b := statechartx.NewMachineBuilder("player", "on")
+	b.State("on").Compound("on.stopped").On("off", "off", nil, nil)
+	b.State("on.stopped").On("play", "on.playing", nil, nil)
+	b.State("on.playing").On("pause", "on.paused", nil, nil)
+	b.State("on.paused").On("", "on.stopped", statechartx.Always(), nil)
+	b.State("on.hist").History(statechartx.HistoryShallow, "on.stopped")
+	b.State("off").On("on", "on.hist", nil, nil)
+
+	_, g := explore(t, b, modelcheck.Options{})
+
+	if _, ok := g.Reachable(b.GetID("on.paused")); !ok {
+		t.Error("on.paused should be reachable when the eventless guard fails")
+	}
+
+	// play, off, on restores on.playing through history
+	path, ok := g.Reachable(b.GetID("on.playing"))
+	if !ok {
+		t.Fatal("on.playing should be reachable")
+	}
+	n := follow(t, b, path.Last(), "off", "on")
+	if !n.InState(b.GetID("on.playing")) {
+		t.Errorf("history should restore on.playing, got %v", n.Active)
+	}
+
+	if _, ok := g.EventuallyWithin(b.GetID("off"), b.GetID("on.playing"), 1); ok {
+		t.Error("off does not always lead back to on.playing")
+	}
+}
+
+// follow sends events from n, requiring exactly one outcome for each
+func follow(t *testing.T, b *statechartx.MachineBuilder, n *modelcheck.Node, events ...string) *modelcheck.Node {
+	t.Helper()
+	for _, name := range events {
+		var next *modelcheck.Node
+		for _, e := range n.Edges {
+			if e.Event == b.EventID(name) {
+				if next != nil && next != e.To {
+					t.Fatalf("event %s is nondeterministic from %v", name, n.Active)
+				}
+				next = e.To
+			}
+		}
+		if next == nil {
+			t.Fatalf("event %s is ignored in %v", name, n.Active)
+		}
+		n = next
+	}
+	return n
+}
+
+func TestEventlessOverflow(t *testing.T) {
+	b := statechartx.NewMachineBuilder("app", "a")
+	b.State("a").On("go", "b", nil, nil)
+	b.State("b").On("", "c", nil, nil)
+	b.State("c").On("", "b", nil, nil)
+
+	_, g := explore(t, b, modelcheck.Options{})
+	overflow := false
+	for _, n := range g.Nodes {
+		for _, e := range n.Edges {
+			overflow = overflow || e.Overflow
+		}
+	}
+	if !overflow {
+		t.Error("an unguarded eventless cycle should overflow MAX_MICROSTEPS")
+	}
+}
+
+func TestMaxConfigurations(t *testing.T) {
+	b := statechartx.NewMachineBuilder("app", "a")
+	b.State("a").On("next", "b", nil, nil)
+	b.State("b").On("next", "c", nil, nil)
+	b.State("c").On("next", "a", nil, nil)
+
+	_, g := explore(t, b, modelcheck.Options{MaxConfigurations: 2})
+	if !g.Truncated || len(g.Nodes) != 2 {
+		t.Errorf("expected truncation at 2 configurations, got %d (truncated=%v)", len(g.Nodes), g.Truncated)
+	}
+	if !g.Nodes[1].Incomplete {
+		t.Error("frontier configuration should be marked incomplete")
+	}
+	if stuck := g.Deadlocks(); len(stuck) != 0 {
+		t.Errorf("frontier configurations are not deadlocks, got %d", len(stuck))
+	}
+}
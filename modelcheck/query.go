@@ -0,0 +1,233 @@
+package modelcheck
+
+import (
+	"strings"
+
+	"github.com/comalice/statechartx"
+)
+
+// Step is one macrostep of a path through the graph.
+type Step struct {
+	Event       statechartx.EventID // NO_EVENT for the first (start) step
+	Assumptions []Assumption
+	Node        *Node // configuration after the step
+}
+
+// Path is a run of the machine: a start step followed by one step per external event.
+type Path []Step
+
+// Events returns the external events of the path, in order. Sending them to a
+// runtime whose guards return the path's assumptions reproduces the path.
+func (p Path) Events() []statechartx.EventID {
+	var events []statechartx.EventID
+	for i, step := range p {
+		if i > 0 {
+			events = append(events, step.Event)
+		}
+	}
+	return events
+}
+
+// Last returns the final configuration of the path, or nil for an empty path.
+func (p Path) Last() *Node {
+	if len(p) == 0 {
+		return nil
+	}
+	return p[len(p)-1].Node
+}
+
+// Format renders the path one step per line, e.g.
+//
+//	start -> off
+//	power [hasPower] -> on.idle
+func (p Path) Format(names statechartx.Namer) string {
+	var sb strings.Builder
+	for i, step := range p {
+		if i == 0 {
+			sb.WriteString("start")
+		} else {
+			sb.WriteString(statechartx.EventNameOf(names, step.Event))
+		}
+		if len(step.Assumptions) > 0 {
+			guards := make([]string, len(step.Assumptions))
+			for j, a := range step.Assumptions {
+				guards[j] = GuardName(a.Transition, names)
+				if !a.Value {
+					guards[j] = "!" + guards[j]
+				}
+			}
+			sb.WriteString(" [" + strings.Join(guards, ", ") + "]")
+		}
+		sb.WriteString(" -> ")
+		active := make([]string, len(step.Node.Active))
+		for j, id := range step.Node.Active {
+			active[j] = statechartx.StateNameOf(names, id)
+		}
+		sb.WriteString(strings.Join(active, ", "))
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// predecessor records how breadth-first search first reached a node
+type predecessor struct {
+	from *Node // nil for start edges
+	edge Edge
+}
+
+// PathTo returns a shortest path from the start to n, or nil if n is unreachable.
+func (g *Graph) PathTo(n *Node) Path {
+	pred := g.predecessors()
+	p, ok := pred[n]
+	if !ok {
+		return nil
+	}
+	var rev Path
+	for {
+		rev = append(rev, Step{Event: p.edge.Event, Assumptions: p.edge.Assumptions, Node: p.edge.To})
+		if p.from == nil {
+			break
+		}
+		p = pred[p.from]
+	}
+	path := make(Path, len(rev))
+	for i := range rev {
+		path[i] = rev[len(rev)-1-i]
+	}
+	return path
+}
+
+// predecessors returns the breadth-first predecessors recorded by Explore
+func (g *Graph) predecessors() map[*Node]predecessor {
+	if g.pred != nil {
+		return g.pred
+	}
+	return g.bfs() // graph not built by Explore
+}
+
+// bfs returns the breadth-first predecessor of every reachable node
+func (g *Graph) bfs() map[*Node]predecessor {
+	pred := map[*Node]predecessor{}
+	var queue []*Node
+	for _, e := range g.Initial {
+		if _, seen := pred[e.To]; !seen {
+			pred[e.To] = predecessor{edge: e}
+			queue = append(queue, e.To)
+		}
+	}
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+		for _, e := range n.Edges {
+			if _, seen := pred[e.To]; !seen {
+				pred[e.To] = predecessor{from: n, edge: e}
+				queue = append(queue, e.To)
+			}
+		}
+	}
+	return pred
+}
+
+// Reachable reports whether state id can become active, with a shortest witness path.
+func (g *Graph) Reachable(id statechartx.StateID) (Path, bool) {
+	for _, n := range g.byDistance() {
+		if n.InState(id) {
+			return g.PathTo(n), true
+		}
+	}
+	return nil, false
+}
+
+// byDistance returns the reachable nodes in breadth-first order
+func (g *Graph) byDistance() []*Node {
+	pred := g.predecessors()
+	nodes := make([]*Node, 0, len(pred))
+	for _, n := range g.Nodes {
+		if _, ok := pred[n]; ok {
+			nodes = append(nodes, n)
+		}
+	}
+	// Nodes are numbered in exploration (breadth-first) order already
+	return nodes
+}
+
+// Deadlocks returns the reachable non-final configurations that no event can leave:
+// every event is ignored or leads back to the same configuration. Incomplete nodes
+// at the frontier of a truncated graph are not reported; see DeadlockFree.
+func (g *Graph) Deadlocks() []*Node {
+	var stuck []*Node
+	for _, n := range g.byDistance() {
+		if n.Final || n.Incomplete {
+			continue
+		}
+		leaves := false
+		for _, e := range n.Edges {
+			if e.To != n {
+				leaves = true
+				break
+			}
+		}
+		if !leaves {
+			stuck = append(stuck, n)
+		}
+	}
+	return stuck
+}
+
+// DeadlockFree reports whether the chart has no deadlock (see Deadlocks).
+// If it has one, the shortest path into a deadlock is returned. On a truncated
+// graph a true result is inconclusive: check Graph.Truncated.
+func (g *Graph) DeadlockFree() (Path, bool) {
+	if stuck := g.Deadlocks(); len(stuck) > 0 {
+		return g.PathTo(stuck[0]), false
+	}
+	return nil, true
+}
+
+// EventuallyWithin reports whether every path from a configuration in which from is
+// active reaches a configuration in which to is active within n events. Events that
+// are ignored do not count. A path that ends (final or no enabled events) before
+// reaching to violates the property.
+//
+// If the property fails, the counterexample runs from the start to a configuration
+// with from active and continues for up to n events without reaching to.
+func (g *Graph) EventuallyWithin(from, to statechartx.StateID, n int) (Path, bool) {
+	type key struct{ node, steps int }
+	memo := map[key]Path{}
+	checked := map[key]bool{}
+
+	// violation returns a suffix of at most k steps from node that avoids to, or nil
+	var violation func(node *Node, k int) (Path, bool)
+	violation = func(node *Node, k int) (Path, bool) {
+		if node.InState(to) {
+			return nil, false
+		}
+		if node.Final || k == 0 || (len(node.Edges) == 0 && !node.Incomplete) {
+			return Path{}, true
+		}
+		kk := key{node.ID, k}
+		if checked[kk] {
+			p, ok := memo[kk]
+			return p, ok
+		}
+		checked[kk] = true
+		for _, e := range node.Edges {
+			if suffix, ok := violation(e.To, k-1); ok {
+				p := append(Path{{Event: e.Event, Assumptions: e.Assumptions, Node: e.To}}, suffix...)
+				memo[kk] = p
+				return p, true
+			}
+		}
+		return nil, false
+	}
+
+	for _, node := range g.byDistance() {
+		if !node.InState(from) {
+			continue
+		}
+		if suffix, ok := violation(node, n); ok {
+			return append(g.PathTo(node), suffix...), false
+		}
+	}
+	return nil, true
+}
@@ -0,0 +1,522 @@
+package modelcheck
+
+import (
+	"sort"
+
+	"github.com/comalice/statechartx"
+)
+
+// explorer holds the machine being explored and the graph built so far
+type explorer struct {
+	m     *statechartx.Machine
+	opts  Options
+	g     *Graph
+	index map[string]*Node
+}
+
+// run is one branch of a macrostep in progress
+type run struct {
+	cfg      config
+	queue    []statechartx.EventID // pending done.state events
+	assume   []Assumption
+	fired    []*statechartx.Transition
+	overflow bool
+}
+
+func (r run) edge(ev statechartx.EventID, to *Node) Edge {
+	return Edge{Event: ev, To: to, Assumptions: r.assume, Fired: r.fired, Overflow: r.overflow}
+}
+
+// choice is a transition selected by an active atomic state
+type choice struct {
+	t    *statechartx.Transition
+	leaf *statechartx.State
+}
+
+// selection is one possible set of transitions enabled by an event
+type selection struct {
+	choices []choice
+	assume  []Assumption
+}
+
+// start enters the initial configuration and settles it
+func (x *explorer) start() []run {
+	entered := map[statechartx.StateID]bool{}
+	leaves := x.fill(x.m.Root(), map[statechartx.StateID]bool{}, entered)
+	r := run{cfg: config{active: sortIDs(leaves)}}
+	r.queue = x.doneEvents(nil, x.statesOf(r.cfg.active), entered)
+
+	var out []run
+	x.settle(r, 0, &out)
+	return out
+}
+
+// macrostep returns every outcome of sending ev in cfg. Branches in which no
+// transition is enabled are omitted (the event is ignored).
+func (x *explorer) macrostep(cfg config, ev statechartx.EventID) []run {
+	fired, _ := x.microstep(run{cfg: cfg}, ev)
+	var out []run
+	for _, r := range fired {
+		x.settle(r, 1, &out)
+	}
+	return out
+}
+
+// settle takes eventless transitions and queued done events until r is stable
+func (x *explorer) settle(r run, steps int, out *[]run) {
+	if steps >= statechartx.MAX_MICROSTEPS {
+		r.overflow = true
+		*out = append(*out, r)
+		return
+	}
+
+	fired, idle := x.microstep(r, statechartx.NO_EVENT)
+	for _, f := range fired {
+		x.settle(f, steps+1, out)
+	}
+	for _, i := range idle {
+		if len(i.queue) == 0 {
+			*out = append(*out, i)
+			continue
+		}
+		ev := i.queue[0]
+		i.queue = i.queue[1:]
+		doneFired, doneIdle := x.microstep(i, ev)
+		for _, f := range doneFired {
+			x.settle(f, steps+1, out)
+		}
+		for _, d := range doneIdle {
+			x.settle(d, steps+1, out)
+		}
+	}
+}
+
+// microstep selects and applies the transitions enabled by ev. It returns the
+// branches that took transitions and the branches in which none was enabled.
+func (x *explorer) microstep(r run, ev statechartx.EventID) (fired, idle []run) {
+	for _, sel := range x.selections(r.cfg, ev) {
+		next := r
+		next.assume = append(append([]Assumption(nil), r.assume...), sel.assume...)
+		if len(sel.choices) == 0 {
+			idle = append(idle, next)
+			continue
+		}
+		fired = append(fired, x.apply(next, sel))
+	}
+	return fired, idle
+}
+
+// selections enumerates the transition sets ev can enable, one per guard outcome
+func (x *explorer) selections(cfg config, ev statechartx.EventID) []selection {
+	var out []selection
+	var pick func(i int, sel selection)
+	pick = func(i int, sel selection) {
+		if i == len(cfg.active) {
+			out = append(out, sel)
+			return
+		}
+		leaf := x.m.GetState(cfg.active[i])
+		if x.covered(leaf, sel.choices) {
+			pick(i+1, sel)
+			return
+		}
+		for _, opt := range x.candidates(leaf, ev, sel.assume, cfg.active) {
+			next := selection{choices: sel.choices, assume: opt.assume}
+			if opt.t != nil && !x.conflicts(opt.t, leaf, sel.choices) {
+				next.choices = append(append([]choice(nil), sel.choices...), choice{t: opt.t, leaf: leaf})
+			}
+			pick(i+1, next)
+		}
+	}
+	pick(0, selection{})
+	return out
+}
+
+// option is a possible transition for one atomic state (nil if none is enabled)
+type option struct {
+	t      *statechartx.Transition
+	assume []Assumption
+}
+
+// candidates returns the transitions leaf may take for ev, branching on guards
+func (x *explorer) candidates(leaf *statechartx.State, ev statechartx.EventID, assume []Assumption, active []statechartx.StateID) []option {
+	var list []*statechartx.Transition
+	for s := leaf; s != nil; s = s.Parent {
+		for _, t := range s.Transitions {
+			if t != nil && t.Event == ev {
+				list = append(list, t)
+			}
+		}
+		if ev != statechartx.NO_EVENT && ev != statechartx.ANY_EVENT {
+			for _, t := range s.Transitions {
+				if t != nil && t.Event == statechartx.ANY_EVENT {
+					list = append(list, t)
+				}
+			}
+		}
+	}
+
+	var out []option
+	var walk func(i int, assume []Assumption)
+	walk = func(i int, assume []Assumption) {
+		if i == len(list) {
+			out = append(out, option{assume: assume})
+			return
+		}
+		t := list[i]
+		if t.Guard == nil {
+			out = append(out, option{t: t, assume: assume})
+			return
+		}
+		if value, decided := lookup(assume, t); decided {
+			if value {
+				out = append(out, option{t: t, assume: assume})
+			} else {
+				walk(i+1, assume)
+			}
+			return
+		}
+
+		outcome := Unknown
+		if x.opts.Guards != nil {
+			outcome = x.opts.Guards(t, active)
+		}
+		decided := outcome != Unknown
+		if outcome != False {
+			out = append(out, option{t: t, assume: with(assume, Assumption{Transition: t, Value: true, Decided: decided})})
+		}
+		if outcome != True {
+			walk(i+1, with(assume, Assumption{Transition: t, Value: false, Decided: decided}))
+		}
+	}
+	walk(0, nil)
+	return out
+}
+
+func lookup(assume []Assumption, t *statechartx.Transition) (value, decided bool) {
+	for _, a := range assume {
+		if a.Transition == t {
+			return a.Value, true
+		}
+	}
+	return false, false
+}
+
+func with(assume []Assumption, a Assumption) []Assumption {
+	return append(append([]Assumption(nil), assume...), a)
+}
+
+// domain returns the state whose active descendants a transition exits
+// (nil for internal transitions)
+func (x *explorer) domain(t *statechartx.Transition, leaf *statechartx.State) *statechartx.State {
+	if t.Target == 0 {
+		return nil
+	}
+	target := x.m.GetState(t.Target)
+	if target == nil {
+		return nil
+	}
+	if target == leaf {
+		if leaf.Parent != nil {
+			return leaf.Parent
+		}
+		return leaf
+	}
+	return lca(leaf, target)
+}
+
+// covered reports whether leaf is exited by an already selected transition
+func (x *explorer) covered(leaf *statechartx.State, choices []choice) bool {
+	for _, c := range choices {
+		if d := x.domain(c.t, c.leaf); d != nil && leaf.IsDescendantOf(d) {
+			return true
+		}
+	}
+	return false
+}
+
+// conflicts reports whether t is already selected or exits states another selected transition exits
+func (x *explorer) conflicts(t *statechartx.Transition, leaf *statechartx.State, choices []choice) bool {
+	d := x.domain(t, leaf)
+	for _, c := range choices {
+		if c.t == t {
+			return true
+		}
+		other := x.domain(c.t, c.leaf)
+		if d == nil || other == nil {
+			continue
+		}
+		if d == other || d.IsDescendantOf(other) || other.IsDescendantOf(d) {
+			return true
+		}
+	}
+	return false
+}
+
+// apply executes a selection on r: exits, history recording, entries and done events
+func (x *explorer) apply(r run, sel selection) run {
+	before := x.statesOf(r.cfg.active)
+	active := map[statechartx.StateID]bool{}
+	for _, id := range r.cfg.active {
+		active[id] = true
+	}
+	history := r.cfg.history
+	entered := map[statechartx.StateID]bool{}
+
+	r.fired = append([]*statechartx.Transition(nil), r.fired...)
+	for _, c := range sel.choices {
+		r.fired = append(r.fired, c.t)
+		dom := x.domain(c.t, c.leaf)
+		if dom == nil {
+			continue // internal transition
+		}
+
+		var exited []statechartx.StateID
+		for id := range active {
+			if x.m.GetState(id).IsDescendantOf(dom) {
+				exited = append(exited, id)
+			}
+		}
+		history = x.recordHistory(sortIDs(exited), dom, history)
+		for _, id := range exited {
+			delete(active, id)
+		}
+
+		in := map[statechartx.StateID]bool{}
+		for _, target := range x.resolve(x.m.GetState(c.t.Target), history) {
+			for s := target; s != nil && s != dom; s = s.Parent {
+				in[s.ID] = true
+			}
+		}
+		domEntered := entered[dom.ID]
+		for _, id := range x.fill(dom, in, entered) {
+			active[id] = true
+		}
+		if !domEntered {
+			delete(entered, dom.ID)
+		}
+	}
+
+	leaves := make([]statechartx.StateID, 0, len(active))
+	for id := range active {
+		leaves = append(leaves, id)
+	}
+	r.cfg = config{active: sortIDs(leaves), history: history}
+	r.queue = append(append([]statechartx.EventID(nil), r.queue...), x.doneEvents(before, x.statesOf(r.cfg.active), entered)...)
+	return r
+}
+
+// fill enters s and its descendants, preferring the states marked in in,
+// and returns the atomic states entered
+func (x *explorer) fill(s *statechartx.State, in, entered map[statechartx.StateID]bool) []statechartx.StateID {
+	entered[s.ID] = true
+	children := s.SortedChildren()
+	switch {
+	case len(children) == 0:
+		return []statechartx.StateID{s.ID}
+	case s.IsParallel:
+		var leaves []statechartx.StateID
+		for _, child := range children {
+			if !child.IsHistoryState {
+				leaves = append(leaves, x.fill(child, in, entered)...)
+			}
+		}
+		return leaves
+	}
+
+	for _, child := range children {
+		if in[child.ID] {
+			return x.fill(child, in, entered)
+		}
+	}
+	initial := x.m.GetState(s.Initial)
+	if initial == nil || !initial.IsDescendantOf(s) {
+		initial = children[0]
+		for _, child := range children {
+			if !child.IsHistoryState {
+				initial = child
+				break
+			}
+		}
+	}
+	for p := initial; p != nil && p != s; p = p.Parent {
+		in[p.ID] = true
+	}
+	for _, child := range children {
+		if in[child.ID] {
+			return x.fill(child, in, entered)
+		}
+	}
+	return nil
+}
+
+// resolve returns the states a transition target stands for, restoring history
+func (x *explorer) resolve(target *statechartx.State, history map[statechartx.StateID][]statechartx.StateID) []*statechartx.State {
+	if !target.IsHistoryState {
+		return []*statechartx.State{target}
+	}
+	if recorded, ok := history[target.ID]; ok {
+		states := make([]*statechartx.State, len(recorded))
+		for i, id := range recorded {
+			states[i] = x.m.GetState(id)
+		}
+		return states
+	}
+	if def := x.m.GetState(target.HistoryDefault); def != nil && target.HistoryDefault != 0 {
+		return []*statechartx.State{def}
+	}
+	if target.Parent != nil {
+		if initial := x.m.GetState(target.Parent.Initial); initial != nil {
+			return []*statechartx.State{initial}
+		}
+		return []*statechartx.State{target.Parent}
+	}
+	return nil
+}
+
+// recordHistory records history for every state below dom that the exited atomic
+// states leave. Only states with history children are recorded.
+func (x *explorer) recordHistory(exited []statechartx.StateID, dom *statechartx.State, history map[statechartx.StateID][]statechartx.StateID) map[statechartx.StateID][]statechartx.StateID {
+	copied := false
+	seen := map[statechartx.StateID]bool{}
+	for _, id := range exited {
+		for s := x.m.GetState(id).Parent; s != nil && s != dom && s.IsDescendantOf(dom); s = s.Parent {
+			if seen[s.ID] {
+				continue
+			}
+			seen[s.ID] = true
+			for _, h := range s.SortedChildren() {
+				if !h.IsHistoryState {
+					continue
+				}
+				if !copied {
+					history = copyHistory(history)
+					copied = true
+				}
+				history[h.ID] = recorded(h, s, exited, x.m)
+			}
+		}
+	}
+	return history
+}
+
+// recorded returns the value a history state records when its parent exits
+func recorded(h, parent *statechartx.State, exited []statechartx.StateID, m *statechartx.Machine) []statechartx.StateID {
+	var ids []statechartx.StateID
+	seen := map[statechartx.StateID]bool{}
+	for _, id := range exited {
+		s := m.GetState(id)
+		if !s.IsDescendantOf(parent) {
+			continue
+		}
+		if h.HistoryType != statechartx.HistoryDeep {
+			for s.Parent != parent {
+				s = s.Parent
+			}
+		}
+		if !seen[s.ID] {
+			seen[s.ID] = true
+			ids = append(ids, s.ID)
+		}
+	}
+	return sortIDs(ids)
+}
+
+func copyHistory(h map[statechartx.StateID][]statechartx.StateID) map[statechartx.StateID][]statechartx.StateID {
+	c := make(map[statechartx.StateID][]statechartx.StateID, len(h)+1)
+	for k, v := range h {
+		c[k] = v
+	}
+	return c
+}
+
+// doneEvents returns the done.state events raised by moving from before to after,
+// innermost states first
+func (x *explorer) doneEvents(before, after, entered map[statechartx.StateID]bool) []statechartx.EventID {
+	var done []*statechartx.State
+	for id := range after {
+		s := x.m.GetState(id)
+		if s.Parent == nil || len(s.Children) == 0 || !x.complete(s, after) {
+			continue
+		}
+		if before[id] && x.complete(s, before) && !entered[id] {
+			continue
+		}
+		done = append(done, s)
+	}
+	sort.Slice(done, func(i, j int) bool {
+		di, dj := depth(done[i]), depth(done[j])
+		if di != dj {
+			return di > dj
+		}
+		return done[i].ID < done[j].ID
+	})
+	events := make([]statechartx.EventID, len(done))
+	for i, s := range done {
+		events[i] = statechartx.DoneEventID(s.ID)
+	}
+	return events
+}
+
+// complete reports whether s has reached a final state in the given active set
+func (x *explorer) complete(s *statechartx.State, states map[statechartx.StateID]bool) bool {
+	switch {
+	case len(s.Children) == 0:
+		return isFinal(s)
+	case s.IsParallel:
+		for _, child := range s.Children {
+			if !child.IsHistoryState && !x.complete(child, states) {
+				return false
+			}
+		}
+		return true
+	}
+	for _, child := range s.Children {
+		if states[child.ID] && len(child.Children) == 0 && isFinal(child) {
+			return true
+		}
+	}
+	return false
+}
+
+// statesOf returns the active atomic states and all their ancestors
+func (x *explorer) statesOf(active []statechartx.StateID) map[statechartx.StateID]bool {
+	states := map[statechartx.StateID]bool{}
+	for _, id := range active {
+		for s := x.m.GetState(id); s != nil; s = s.Parent {
+			states[s.ID] = true
+		}
+	}
+	return states
+}
+
+func isFinal(s *statechartx.State) bool {
+	return s.IsFinal || s.Final
+}
+
+// lca returns the deepest state that is an ancestor-or-self of both a and b
+func lca(a, b *statechartx.State) *statechartx.State {
+	ancestors := map[*statechartx.State]bool{}
+	for s := a; s != nil; s = s.Parent {
+		ancestors[s] = true
+	}
+	for s := b; s != nil; s = s.Parent {
+		if ancestors[s] {
+			return s
+		}
+	}
+	return nil
+}
+
+func depth(s *statechartx.State) int {
+	d := 0
+	for p := s.Parent; p != nil; p = p.Parent {
+		d++
+	}
+	return d
+}
+
+func sortIDs(ids []statechartx.StateID) []statechartx.StateID {
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
@@ -0,0 +1,164 @@
+package realtime
+
+import (
+	"fmt"
+
+	"github.com/comalice/statechartx"
+)
+
+// CoalesceMode is how an event is combined with an event of the same ID
+// already batched for the same tick.
+type CoalesceMode int
+
+const (
+	// CoalesceKeepLast replaces the batched event's data with the new event's.
+	// The event keeps the batched event's place in the tick order.
+	CoalesceKeepLast CoalesceMode = iota
+	// CoalesceKeepFirst drops the new event.
+	CoalesceKeepFirst
+	// CoalesceMerge replaces the batched event with CoalesceRule.Merge of the
+	// batched and the new event.
+	CoalesceMerge
+)
+
+// CoalesceRule configures coalescing of one event ID (see Config.Coalesce).
+// Only events with the same Address, priority and due tick that were sent in
+// the same way (from outside, or raised by a tick) are combined, so coalescing
+// is the same in replays and re-simulation.
+type CoalesceRule struct {
+	Mode  CoalesceMode
+	Merge func(batched, next statechartx.Event) statechartx.Event // CoalesceMerge only, required
+}
+
+// validate reports why the rule for event id cannot be applied, if it cannot
+func (r CoalesceRule) validate(id statechartx.EventID) error {
+	switch r.Mode {
+	case CoalesceKeepLast, CoalesceKeepFirst:
+		return nil
+	case CoalesceMerge:
+		if r.Merge == nil {
+			return fmt.Errorf("realtime: coalesce rule for event %d uses CoalesceMerge without a Merge func", id)
+		}
+		return nil
+	default:
+		return fmt.Errorf("realtime: coalesce rule for event %d has unknown mode %d", id, r.Mode)
+	}
+}
+
+// EventFilter decides whether an event is processed in the given tick. It is
+// called on the tick goroutine with the tick's events in processing order and
+// must give the same answer for the same input on every run.
+type EventFilter func(tick uint64, event EventWithMeta) bool
+
+// EventMetrics are cumulative counts of events the runtime did not process as sent.
+type EventMetrics struct {
+	Coalesced uint64 // events dropped or replaced by CoalesceKeepFirst or CoalesceKeepLast
+	Merged    uint64 // events combined by CoalesceMerge
+	Filtered  uint64 // events dropped by Config.EventFilters
+	QueueFull uint64 // events rejected because the batch held MaxEventsPerTick events
+}
+
+// EventMetrics returns the event counts so far.
+func (rt *RealtimeRuntime) EventMetrics() EventMetrics {
+	rt.batchMu.Lock()
+	defer rt.batchMu.Unlock()
+	return rt.eventMetrics
+}
+
+// coalesceKey identifies events that may be combined
+type coalesceKey struct {
+	id       statechartx.EventID
+	address  statechartx.StateID
+	priority int
+	at       uint64
+	raised   bool
+}
+
+// coalesceEvent combines event with a matching batched event if its ID has a
+// CoalesceRule, and reports whether it did. The caller holds batchMu.
+func (rt *RealtimeRuntime) coalesceEvent(event statechartx.Event, priority int, at uint64) bool {
+	rule, ok := rt.coalesce[event.ID]
+	if !ok || (rt.resimulating && !rt.inTick) {
+		return false
+	}
+
+	key := coalesceKey{id: event.ID, address: event.Address, priority: priority, at: rt.dueTick(at), raised: rt.inTick}
+	i, ok := rt.coalesceIndex[key]
+	if !ok || i >= len(rt.eventBatch) || rt.batchedKey(i) != key {
+		// Not batched, or the index is from a previous batch
+		rt.coalesceIndex[key] = len(rt.eventBatch)
+		return false
+	}
+
+	batched := &rt.eventBatch[i].Event
+	switch rule.Mode {
+	case CoalesceKeepLast:
+		*batched = event
+		rt.eventMetrics.Coalesced++
+	case CoalesceKeepFirst:
+		rt.eventMetrics.Coalesced++
+	case CoalesceMerge:
+		*batched = rule.Merge(*batched, event)
+		rt.eventMetrics.Merged++
+	}
+	return true
+}
+
+// resetCoalesceIndex forgets the indexes of a batch that was taken or
+// replaced, so the index only holds keys of the current batch. The caller
+// holds batchMu.
+func (rt *RealtimeRuntime) resetCoalesceIndex() {
+	for key := range rt.coalesceIndex {
+		delete(rt.coalesceIndex, key)
+	}
+}
+
+// batchedKey returns the coalescing key of the batched event i. The caller holds batchMu.
+func (rt *RealtimeRuntime) batchedKey(i int) coalesceKey {
+	e := rt.eventBatch[i]
+	return coalesceKey{id: e.Event.ID, address: e.Event.Address, priority: e.Priority, at: rt.dueTick(e.at), raised: e.raised}
+}
+
+// dueTick normalises a scheduled tick for coalescing: events due at or before
+// the next tick to run (including SendEvent's 0) are delivered together, so
+// they share the key 0. The caller holds batchMu.
+func (rt *RealtimeRuntime) dueTick(at uint64) uint64 {
+	next := rt.tickNum
+	if rt.inTick {
+		next++
+	}
+	if at <= next {
+		return 0
+	}
+	return at
+}
+
+// filterEvents drops the events rejected by Config.EventFilters, in place
+func (rt *RealtimeRuntime) filterEvents(tick uint64, events []EventWithMeta) []EventWithMeta {
+	if len(rt.filters) == 0 {
+		return events
+	}
+
+	kept := events[:0]
+	for _, e := range events {
+		if rt.acceptEvent(tick, e) {
+			kept = append(kept, e)
+		}
+	}
+	if dropped := len(events) - len(kept); dropped > 0 {
+		rt.batchMu.Lock()
+		rt.eventMetrics.Filtered += uint64(dropped)
+		rt.batchMu.Unlock()
+	}
+	return kept
+}
+
+// acceptEvent reports whether every filter accepts e
+func (rt *RealtimeRuntime) acceptEvent(tick uint64, e EventWithMeta) bool {
+	for _, filter := range rt.filters {
+		if !filter(tick, e) {
+			return false
+		}
+	}
+	return true
+}
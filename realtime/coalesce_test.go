@@ -0,0 +1,159 @@
+package realtime
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/comalice/statechartx"
+)
+
+// TestCoalesce tests each coalescing mode and that coalesced events do not
+// fill the batch
+func TestCoalesce(t *testing.T) {
+	b := statechartx.NewMachineBuilder("app", "idle")
+	b.State("idle").
+		OnInternal("move", nil, nil).
+		OnInternal("fire", nil, nil).
+		OnInternal("scroll", nil, nil).
+		OnInternal("key", nil, nil)
+	machine, err := b.Build()
+	if err != nil {
+		t.Fatalf("Failed to create machine: %v", err)
+	}
+
+	move, fire, scroll, key := b.EventID("move"), b.EventID("fire"), b.EventID("scroll"), b.EventID("key")
+	rt := NewRuntime(machine, Config{
+		Manual:           true,
+		MaxEventsPerTick: 4,
+		Coalesce: map[statechartx.EventID]CoalesceRule{
+			move: {Mode: CoalesceKeepLast},
+			fire: {Mode: CoalesceKeepFirst},
+			scroll: {Mode: CoalesceMerge, Merge: func(batched, next statechartx.Event) statechartx.Event {
+				batched.Data = batched.Data.(int) + next.Data.(int)
+				return batched
+			}},
+		},
+	})
+	if err := rt.Start(context.Background()); err != nil {
+		t.Fatalf("Failed to start runtime: %v", err)
+	}
+	defer rt.Stop()
+
+	for i := 1; i <= 10; i++ {
+		for _, id := range []statechartx.EventID{move, fire, scroll} {
+			if err := rt.SendEvent(statechartx.Event{ID: id, Data: i}); err != nil {
+				t.Fatalf("SendEvent %d failed: %v", i, err)
+			}
+		}
+	}
+	rt.SendEvent(statechartx.Event{ID: key})
+	if err := rt.SendEvent(statechartx.Event{ID: key}); !errors.Is(err, statechartx.ErrEventQueueFull) {
+		t.Errorf("expected ErrEventQueueFull for an event without a rule, got %v", err)
+	}
+
+	report := mustStep(t, rt)
+	if len(report.Events) != 4 {
+		t.Fatalf("expected 4 events, got %v", report.Events)
+	}
+	if report.Events[0].Data != 10 || report.Events[1].Data != 1 || report.Events[2].Data != 55 {
+		t.Errorf("expected move 10, fire 1, scroll 55, got %v", report.Events)
+	}
+	want := EventMetrics{Coalesced: 18, Merged: 9, QueueFull: 1}
+	if m := rt.EventMetrics(); m != want {
+		t.Errorf("expected %+v, got %+v", want, m)
+	}
+
+	// A new batch starts a new coalesced event
+	rt.SendEvent(statechartx.Event{ID: move, Data: 11})
+	if report := mustStep(t, rt); len(report.Events) != 1 || report.Events[0].Data != 11 {
+		t.Errorf("expected move 11, got %v", report.Events)
+	}
+
+	// SendEvent and SendEventAtTick for the current tick are delivered together
+	rt.SendEvent(statechartx.Event{ID: move, Data: 12})
+	if err := rt.SendEventAtTick(statechartx.Event{ID: move, Data: 13}, rt.GetTickNumber()); err != nil {
+		t.Fatalf("SendEventAtTick failed: %v", err)
+	}
+	if report := mustStep(t, rt); len(report.Events) != 1 || report.Events[0].Data != 13 {
+		t.Errorf("expected move 13, got %v", report.Events)
+	}
+}
+
+// TestCoalesceRules tests that invalid rules are rejected and that the
+// coalescing index does not keep keys of events scheduled for later ticks
+func TestCoalesceRules(t *testing.T) {
+	cfg := Config{Coalesce: map[statechartx.EventID]CoalesceRule{1: {Mode: CoalesceMerge}}}
+	if err := cfg.Validate(); err == nil {
+		t.Error("expected an error for CoalesceMerge without Merge")
+	}
+	cfg.Coalesce[1] = CoalesceRule{Mode: CoalesceMode(42)}
+	if err := cfg.Validate(); err == nil {
+		t.Error("expected an error for an unknown mode")
+	}
+
+	b := statechartx.NewMachineBuilder("app", "idle")
+	b.State("idle").OnInternal("move", nil, nil)
+	machine, err := b.Build()
+	if err != nil {
+		t.Fatalf("Failed to create machine: %v", err)
+	}
+	if err := NewRuntime(machine, cfg).Start(context.Background()); err == nil {
+		t.Error("expected Start to fail on an invalid rule")
+	}
+	move := b.EventID("move")
+	rt := NewRuntime(machine, Config{Manual: true, Coalesce: map[statechartx.EventID]CoalesceRule{move: {Mode: CoalesceKeepLast}}})
+	if err := rt.Start(context.Background()); err != nil {
+		t.Fatalf("Failed to start runtime: %v", err)
+	}
+	defer rt.Stop()
+
+	for i := uint64(0); i < 100; i++ {
+		if err := rt.SendEventAfterTicks(statechartx.Event{ID: move}, 1000+i); err != nil {
+			t.Fatalf("SendEventAfterTicks failed: %v", err)
+		}
+		rt.Step()
+	}
+	rt.batchMu.Lock()
+	n := len(rt.coalesceIndex)
+	rt.batchMu.Unlock()
+	if n != 0 {
+		t.Errorf("expected an empty coalescing index between ticks, got %d keys", n)
+	}
+}
+
+// TestEventFilters tests that filters drop events from a tick
+func TestEventFilters(t *testing.T) {
+	b := statechartx.NewMachineBuilder("app", "idle")
+	b.State("idle").On("go", "moving", nil, nil).OnInternal("noise", nil, nil)
+	b.State("moving").Atomic()
+	machine, err := b.Build()
+	if err != nil {
+		t.Fatalf("Failed to create machine: %v", err)
+	}
+
+	rt := NewRuntime(machine, Config{
+		Manual: true,
+		EventFilters: []EventFilter{
+			func(tick uint64, e EventWithMeta) bool { return e.Event.ID != b.EventID("noise") },
+			func(tick uint64, e EventWithMeta) bool { return tick%2 == 1 },
+		},
+	})
+	if err := rt.Start(context.Background()); err != nil {
+		t.Fatalf("Failed to start runtime: %v", err)
+	}
+	defer rt.Stop()
+
+	rt.SendEvent(statechartx.Event{ID: b.EventID("go")})
+	if report := mustStep(t, rt); len(report.Events) != 0 || !rt.IsInState(b.GetID("idle")) {
+		t.Fatalf("expected tick 0 to drop every event, got %v", report.Events)
+	}
+	rt.SendEvent(statechartx.Event{ID: b.EventID("noise")})
+	rt.SendEvent(statechartx.Event{ID: b.EventID("go")})
+	if report := mustStep(t, rt); len(report.Events) != 1 || !rt.IsInState(b.GetID("moving")) {
+		t.Errorf("expected go at tick 1, got %v", report.Events)
+	}
+	if m := rt.EventMetrics(); m.Filtered != 2 {
+		t.Errorf("expected 2 filtered events, got %d", m.Filtered)
+	}
+}
@@ -0,0 +1,41 @@
+package realtime
+
+import (
+	"context"
+
+	"github.com/comalice/statechartx"
+)
+
+// The sequential region code below runs entries, transitions and guards itself,
+// so it reports them to the embedded Runtime's Coverage recorder here.
+
+// runEntryActions records the entry and runs the state's entry actions
+func (rt *RealtimeRuntime) runEntryActions(ctx context.Context, state *statechartx.State, from, to statechartx.StateID) error {
+	if rt.Coverage != nil {
+		rt.Coverage.StateEntered(state)
+	}
+	rt.startTimers(state.ID)
+	return state.RunEntryActions(ctx, nil, from, to)
+}
+
+// runTransitionActions records the transition and runs its actions
+func (rt *RealtimeRuntime) runTransitionActions(ctx context.Context, t *statechartx.Transition, evt *statechartx.Event, from, to statechartx.StateID) error {
+	if rt.Coverage != nil {
+		rt.Coverage.TransitionTaken(t)
+	}
+	rt.transitionTaken(t)
+	return t.RunActions(ctx, evt, from, to)
+}
+
+// checkGuard evaluates the transition's guard (true if it has none)
+func (rt *RealtimeRuntime) checkGuard(ctx context.Context, t *statechartx.Transition, evt *statechartx.Event, from statechartx.StateID) bool {
+	if t.Guard == nil {
+		return true
+	}
+	ok, err := t.Guard(ctx, evt, from, t.Target)
+	ok = ok && err == nil
+	if rt.Coverage != nil {
+		rt.Coverage.GuardEvaluated(t, ok)
+	}
+	return ok
+}
@@ -0,0 +1,100 @@
+package realtime
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/comalice/statechartx"
+)
+
+// Divergence describes the first tick at which two peers' states differ.
+type Divergence struct {
+	Tick uint64     // first tick whose hashes differ
+	A, B TickRecord // each peer's record of that tick
+
+	// OnlyA and OnlyB are the states active on one peer but not the other
+	// after Tick. Both are empty if only history or extended state differs.
+	OnlyA, OnlyB []statechartx.StateID
+
+	// EventsDiffer reports whether the peers processed different events at or
+	// before Tick; EventTick is the first such tick and usually the cause.
+	EventsDiffer bool
+	EventTick    uint64
+}
+
+// String summarises the divergence for logs and test failures.
+func (d *Divergence) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "desync at tick %d: hash %016x != %016x", d.Tick, d.A.Hash, d.B.Hash)
+	if len(d.OnlyA) > 0 || len(d.OnlyB) > 0 {
+		fmt.Fprintf(&b, ", only in A: %v, only in B: %v", d.OnlyA, d.OnlyB)
+	}
+	if d.EventsDiffer {
+		fmt.Fprintf(&b, ", event logs differ from tick %d", d.EventTick)
+	}
+	return b.String()
+}
+
+// FindDivergence compares two peers' tick records (for example from
+// TickRecords) and returns the first tick recorded by both whose hashes differ,
+// or nil if every common tick matches. Ticks recorded by only one peer are
+// ignored, so records retained over different windows can be compared.
+func FindDivergence(a, b []TickRecord) *Divergence {
+	byTick := make(map[uint64]TickRecord, len(b))
+	for _, record := range b {
+		byTick[record.Tick] = record
+	}
+	a = append([]TickRecord(nil), a...)
+	sort.Slice(a, func(i, j int) bool { return a[i].Tick < a[j].Tick })
+
+	var eventTick uint64
+	eventsDiffer := false
+	for _, ra := range a {
+		rb, ok := byTick[ra.Tick]
+		if !ok {
+			continue
+		}
+		if !eventsDiffer && !sameEvents(ra.Events, rb.Events) {
+			eventsDiffer, eventTick = true, ra.Tick
+		}
+		if ra.Hash == rb.Hash {
+			continue
+		}
+
+		d := &Divergence{Tick: ra.Tick, A: ra, B: rb, EventsDiffer: eventsDiffer, EventTick: eventTick}
+		d.OnlyA = missingStates(ra.Configuration, rb.Configuration)
+		d.OnlyB = missingStates(rb.Configuration, ra.Configuration)
+		return d
+	}
+	return nil
+}
+
+// sameEvents reports whether two event logs are equal, comparing data deeply
+func sameEvents(a, b []statechartx.Event) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].ID != b[i].ID || a[i].Address != b[i].Address || !reflect.DeepEqual(a[i].Data, b[i].Data) {
+			return false
+		}
+	}
+	return true
+}
+
+// missingStates returns the states in from that are not in other
+func missingStates(from, other []statechartx.StateID) []statechartx.StateID {
+	in := make(map[statechartx.StateID]bool, len(other))
+	for _, id := range other {
+		in[id] = true
+	}
+	var missing []statechartx.StateID
+	for _, id := range from {
+		if !in[id] {
+			missing = append(missing, id)
+		}
+	}
+	return missing
+}
@@ -15,6 +15,80 @@
 //	rt.Start(ctx)
 //	rt.SendEvent(statechartx.Event{ID: 1})
 //
+// # Tick Budget
+//
+// Each tick has a budget of one TickRate. Ticks that take longer are reported
+// to Config.OnOverrun and counted in TickMetrics, and Config.CatchUp decides
+// whether the missed tick slots are dropped (CatchUpSlowMotion), skipped with
+// the tick number advanced (CatchUpSkip) or run back to back (CatchUpBurst).
+//
+// # Pause and Time Scale
+//
+// Pause and Resume stop and continue tick progression while keeping the tick
+// goroutine and the event batch; Config.WhilePaused decides whether events
+// sent meanwhile are buffered or rejected with ErrPaused. SetTimeScale changes
+// how often ticks run without changing the simulated time per tick.
+//
+// # Coalescing and Filters
+//
+// Config.Coalesce combines repeated events of an ID batched for the same tick
+// (keep last, keep first or merge), and Config.EventFilters drop events from a
+// tick before they are processed. EventMetrics counts the events affected.
+//
+// # Tick Hooks
+//
+// Config.OnTickStart, OnEventsCollected and OnTickEnd run user code at the
+// start of a tick, once its events are ordered and after the state machine
+// ran. Actions and guards get the current TickInfo (tick number and delta)
+// with TickFromContext.
+//
+// # Scheduled Events
+//
+// SendEventAtTick and SendEventAfterTicks queue an event for a later tick, and
+// AfterTicks adds a transition taken once its source state has been active for
+// a number of ticks (cancelled if the state exits first). Both are
+// deterministic: they are included in state hashes, snapshots and replays.
+//
+// # Worlds
+//
+// A World steps many runtimes created with Config.Manual on a single clock,
+// in ascending member name order, optionally on a pool of worker goroutines.
+// Members send each other events with SendToMember; WorldConfig.Delivery
+// decides whether they are processed at the next tick or, for members later
+// in step order, in the same tick.
+//
+// # Manual Stepping
+//
+// With Config.Manual set no ticker runs; the caller advances time with Step or
+// StepN, which run one tick each on the calling goroutine and return a
+// TickReport of what happened:
+//
+//	rt := realtime.NewRuntime(machine, realtime.Config{Manual: true})
+//	rt.Start(ctx)
+//	rt.SendEvent(statechartx.Event{ID: 1})
+//	report, err := rt.Step()
+//
+// # Desync Detection
+//
+// With Config.HashHistory set, each tick records a TickRecord holding a stable
+// hash of the configuration, region states, history and (through
+// Config.HashExtendedState) the user's extended state. TickHash returns a
+// tick's hash, and FindDivergence compares two peers' records and reports the
+// first divergent tick with the difference in configurations.
+//
+// # Rollback
+//
+// With Config.Manual and Config.SnapshotHistory set, the runtime snapshots its
+// state at the start of every tick (extended state through a Cloner). Rollback
+// restores an earlier tick, InsertEventAt adds a late event to a past tick and
+// Resimulate re-runs the ticks up to the present with their original events.
+//
+// # Replay Files
+//
+// Record writes the events of every tick to a versioned JSON Lines replay file
+// with configuration checkpoints, and a Player steps a manual runtime through
+// the file, checking each checkpoint.
+//
 // # Trade-offs vs Event-Driven
 //
 // Lower throughput (60K vs 2M events/sec at 60 FPS)
@@ -11,6 +11,14 @@ type EventWithMeta struct {
 	Event       statechartx.Event
 	SequenceNum uint64
 	Priority    int // For future priority ordering
+
+	raised bool // sent by an action during the previous tick rather than from outside
+
+	// Scheduling (see schedule.go)
+	at         uint64              // tick the event is due at (0 = next tick)
+	timer      bool                // AfterTicks countdown of timerState
+	timerState statechartx.StateID // state whose entry started the countdown
+	timerGen   uint64              // entry of timerState that started it
 }
 
 // sortEvents orders events deterministically
@@ -0,0 +1,177 @@
+package realtime
+
+import (
+	"encoding/binary"
+	"hash"
+	"hash/fnv"
+	"sort"
+
+	"github.com/comalice/statechartx"
+)
+
+// TickRecord is the state hash recorded at the end of a tick, together with the
+// events the tick processed and the resulting configuration. Peers running the
+// same chart on the same events produce identical records.
+type TickRecord struct {
+	Tick          uint64                // tick number (GetTickNumber while the tick ran)
+	Hash          uint64                // StateHash at the end of the tick
+	Events        []statechartx.Event   // external events processed, in order
+	Configuration []statechartx.StateID // active states after the tick, sorted
+}
+
+// StateHash returns a stable 64-bit FNV-1a hash of the runtime's state: the
+// active configuration, the current state of every parallel region, events
+// scheduled for later ticks, the shallow and deep history maps and, if
+// Config.HashExtendedState is set, the user's extended state. Event data and
+// events queued for the next tick are not included.
+func (rt *RealtimeRuntime) StateHash() uint64 {
+	h := fnv.New64a()
+	rt.writeState(h, rt.Configuration())
+	return h.Sum64()
+}
+
+// TickHash returns the state hash recorded at the end of tick. It reports false
+// if hashing is disabled (Config.HashHistory is zero) or the tick has not run
+// or is no longer retained.
+func (rt *RealtimeRuntime) TickHash(tick uint64) (uint64, bool) {
+	record, ok := rt.TickRecord(tick)
+	return record.Hash, ok
+}
+
+// TickRecord returns the record of tick, with the same availability as TickHash.
+func (rt *RealtimeRuntime) TickRecord(tick uint64) (TickRecord, bool) {
+	rt.recordsMu.RLock()
+	defer rt.recordsMu.RUnlock()
+
+	if len(rt.tickRecords) == 0 {
+		return TickRecord{}, false
+	}
+	record := rt.tickRecords[tick%uint64(len(rt.tickRecords))]
+	if record.Tick != tick || record.Configuration == nil {
+		return TickRecord{}, false
+	}
+	return record, true
+}
+
+// TickRecords returns the retained tick records, oldest first.
+func (rt *RealtimeRuntime) TickRecords() []TickRecord {
+	rt.recordsMu.RLock()
+	defer rt.recordsMu.RUnlock()
+
+	records := make([]TickRecord, 0, len(rt.tickRecords))
+	for _, record := range rt.tickRecords {
+		if record.Configuration != nil {
+			records = append(records, record)
+		}
+	}
+	sort.Slice(records, func(i, j int) bool { return records[i].Tick < records[j].Tick })
+	return records
+}
+
+// recordTick hashes the state at the end of the current tick (called from processTick)
+func (rt *RealtimeRuntime) recordTick(events []EventWithMeta) {
+	if len(rt.tickRecords) == 0 {
+		return
+	}
+
+	rt.batchMu.Lock()
+	tick := rt.tickNum
+	rt.batchMu.Unlock()
+
+	record := TickRecord{
+		Tick:          tick,
+		Events:        make([]statechartx.Event, len(events)),
+		Configuration: rt.Configuration(),
+	}
+	for i, e := range events {
+		record.Events[i] = e.Event
+	}
+	h := fnv.New64a()
+	rt.writeState(h, record.Configuration)
+	record.Hash = h.Sum64()
+
+	rt.recordsMu.Lock()
+	rt.tickRecords[tick%uint64(len(rt.tickRecords))] = record
+	rt.recordsMu.Unlock()
+}
+
+// writeState writes the hashed state in a fixed order. Every list is prefixed
+// with its length so that different states cannot produce the same byte stream.
+func (rt *RealtimeRuntime) writeState(h hash.Hash64, config []statechartx.StateID) {
+	var buf [8]byte
+	writeInt := func(v int64) {
+		binary.LittleEndian.PutUint64(buf[:], uint64(v))
+		h.Write(buf[:])
+	}
+
+	// Active configuration
+	writeInt(int64(len(config)))
+	for _, id := range config {
+		writeInt(int64(id))
+	}
+
+	// Parallel regions, ordered by parallel state then region
+	rt.regionMu.RLock()
+	parallelIDs := sortedKeys(rt.parallelRegionStates)
+	writeInt(int64(len(parallelIDs)))
+	for _, parallelID := range parallelIDs {
+		regions := rt.parallelRegionStates[parallelID]
+		regionIDs := sortedKeys(regions)
+		writeInt(int64(parallelID))
+		writeInt(int64(len(regionIDs)))
+		for _, regionID := range regionIDs {
+			writeInt(int64(regionID))
+			writeInt(int64(regions[regionID].currentState))
+		}
+	}
+	rt.regionMu.RUnlock()
+
+	// Scheduled events and AfterTicks entries
+	rt.batchMu.Lock()
+	writeInt(int64(len(rt.scheduled)))
+	for _, e := range rt.scheduled {
+		writeInt(int64(e.at))
+		writeInt(int64(e.Event.ID))
+		writeInt(int64(e.Priority))
+	}
+	timed := sortedKeys(rt.timerGen)
+	writeInt(int64(len(timed)))
+	for _, id := range timed {
+		writeInt(int64(id))
+		writeInt(int64(rt.timerGen[id]))
+	}
+	rt.batchMu.Unlock()
+
+	// History
+	shallow, deep := rt.Runtime.History()
+	parents := sortedKeys(shallow)
+	writeInt(int64(len(parents)))
+	for _, parent := range parents {
+		writeInt(int64(parent))
+		writeInt(int64(shallow[parent]))
+	}
+	parents = sortedKeys(deep)
+	writeInt(int64(len(parents)))
+	for _, parent := range parents {
+		writeInt(int64(parent))
+		writeInt(int64(len(deep[parent])))
+		for _, id := range deep[parent] {
+			writeInt(int64(id))
+		}
+	}
+
+	// Extended state
+	if rt.hashExtended != nil {
+		rt.hashExtended(h)
+	}
+}
+
+// sortedKeys returns the keys of a map keyed by state ID in ascending order
+func sortedKeys[V any](m map[statechartx.StateID]V) []statechartx.StateID {
+	keys := make([]statechartx.StateID, 0, len(m))
+	for id := range m {
+		keys = append(keys, id)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+	return keys
+}
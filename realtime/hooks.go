@@ -0,0 +1,75 @@
+package realtime
+
+import (
+	"context"
+	"time"
+)
+
+// TickInfo describes the tick being processed.
+type TickInfo struct {
+	Tick  uint64        // tick number (GetTickNumber while the tick runs)
+	Delta time.Duration // simulated time since the previous tick that ran
+}
+
+// tickContextKey is the context key of the runtime whose actions run with the context
+type tickContextKey struct{}
+
+// TickFromContext returns the tick being processed by the runtime whose action
+// (or guard) received ctx. Entry actions run by Start see tick 0 with a zero
+// Delta. It returns false if ctx does not come from a RealtimeRuntime.
+func TickFromContext(ctx context.Context) (TickInfo, bool) {
+	rt, ok := ctx.Value(tickContextKey{}).(*RealtimeRuntime)
+	if !ok {
+		return TickInfo{}, false
+	}
+	rt.batchMu.Lock()
+	defer rt.batchMu.Unlock()
+	return rt.tickInfo, true
+}
+
+// beginTick records the TickInfo of the tick about to run and returns its
+// number. Delta is one TickRate, or more after ticks skipped by the catch-up
+// policy.
+func (rt *RealtimeRuntime) beginTick() uint64 {
+	rt.batchMu.Lock()
+	defer rt.batchMu.Unlock()
+
+	tick := rt.tickNum
+	ticks := uint64(1)
+	if rt.ranTick && tick > rt.tickInfo.Tick {
+		ticks = tick - rt.tickInfo.Tick
+	}
+	rt.tickInfo = TickInfo{Tick: tick, Delta: time.Duration(ticks) * rt.tickRate}
+	rt.ranTick = true
+	return tick
+}
+
+// startTickHooks runs Config.OnTickStart and returns the events it sent, which
+// are processed in this tick like events raised by actions
+func (rt *RealtimeRuntime) startTickHooks(tick uint64) []EventWithMeta {
+	if rt.onTickStart == nil {
+		return nil
+	}
+	rt.onTickStart(tick)
+
+	rt.batchMu.Lock()
+	defer rt.batchMu.Unlock()
+	return rt.takeBatch()
+}
+
+// endTickHooks runs Config.OnTickEnd. Events it sends count as raised by the
+// tick, so replays and re-simulation regenerate them instead of resending.
+func (rt *RealtimeRuntime) endTickHooks(report TickReport) {
+	if rt.onTickEnd == nil {
+		return
+	}
+	rt.batchMu.Lock()
+	rt.inTick = true
+	rt.batchMu.Unlock()
+	defer func() {
+		rt.batchMu.Lock()
+		rt.inTick = false
+		rt.batchMu.Unlock()
+	}()
+	rt.onTickEnd(report.Tick, report)
+}
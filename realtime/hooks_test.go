@@ -0,0 +1,103 @@
+package realtime
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/comalice/statechartx"
+)
+
+// TestTickHooks tests the order of the tick hooks and that events sent from
+// OnTickStart are processed in the same tick
+func TestTickHooks(t *testing.T) {
+	b := statechartx.NewMachineBuilder("app", "idle")
+	var calls []string
+	b.State("idle").On("go", "moving", nil, func(ctx context.Context, evt *statechartx.Event, from, to statechartx.StateID) error {
+		info, ok := TickFromContext(ctx)
+		calls = append(calls, fmt.Sprintf("action %d %v %v", info.Tick, info.Delta, ok))
+		return nil
+	})
+	b.State("moving").Atomic()
+	machine, err := b.Build()
+	if err != nil {
+		t.Fatalf("Failed to create machine: %v", err)
+	}
+
+	var rt *RealtimeRuntime
+	rt = NewRuntime(machine, Config{
+		Manual:   true,
+		TickRate: 10 * time.Millisecond,
+		OnTickStart: func(tick uint64) {
+			calls = append(calls, fmt.Sprintf("start %d", tick))
+			if tick == 1 {
+				rt.SendEvent(statechartx.Event{ID: b.EventID("go")})
+			}
+		},
+		OnEventsCollected: func(tick uint64, events []EventWithMeta) {
+			calls = append(calls, fmt.Sprintf("collected %d %d", tick, len(events)))
+		},
+		OnTickEnd: func(tick uint64, report TickReport) {
+			calls = append(calls, fmt.Sprintf("end %d %d", tick, len(report.Transitions)))
+		},
+	})
+	if err := rt.Start(context.Background()); err != nil {
+		t.Fatalf("Failed to start runtime: %v", err)
+	}
+	defer rt.Stop()
+
+	rt.StepN(2)
+	want := []string{
+		"start 0", "collected 0 0", "end 0 0",
+		"start 1", "collected 1 1", "action 1 10ms true", "end 1 1",
+	}
+	if fmt.Sprint(calls) != fmt.Sprint(want) {
+		t.Errorf("expected %v, got %v", want, calls)
+	}
+	if _, ok := TickFromContext(context.Background()); ok {
+		t.Error("expected no tick outside a runtime")
+	}
+}
+
+// TestTickHooksResimulate tests that events sent by hooks are regenerated,
+// not duplicated, by re-simulation
+func TestTickHooksResimulate(t *testing.T) {
+	b := statechartx.NewMachineBuilder("app", "idle")
+	b.State("idle").OnInternal("ping", nil, nil)
+	machine, err := b.Build()
+	if err != nil {
+		t.Fatalf("Failed to create machine: %v", err)
+	}
+
+	var rt *RealtimeRuntime
+	ping := func(tick uint64) {
+		rt.SendEvent(statechartx.Event{ID: b.EventID("ping")})
+	}
+	rt = NewRuntime(machine, Config{
+		Manual:          true,
+		HashHistory:     16,
+		SnapshotHistory: 16,
+		OnTickStart:     ping,
+		OnTickEnd:       func(tick uint64, report TickReport) { ping(tick) },
+	})
+	if err := rt.Start(context.Background()); err != nil {
+		t.Fatalf("Failed to start runtime: %v", err)
+	}
+	defer rt.Stop()
+
+	rt.StepN(6)
+	want := rt.TickRecords()
+	if err := rt.Rollback(2); err != nil {
+		t.Fatalf("Rollback failed: %v", err)
+	}
+	if err := rt.Resimulate(); err != nil {
+		t.Fatalf("Resimulate failed: %v", err)
+	}
+	if d := FindDivergence(want, rt.TickRecords()); d != nil {
+		t.Errorf("expected identical re-simulation, got %s", d)
+	}
+	if r, _ := rt.TickRecord(4); len(r.Events) != 2 {
+		t.Errorf("expected two pings at tick 4, got %v", r.Events)
+	}
+}
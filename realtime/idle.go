@@ -0,0 +1,71 @@
+package realtime
+
+import (
+	"context"
+
+	"github.com/comalice/statechartx"
+)
+
+// WaitIdle blocks until the runtime is quiescent: no tick is running (and the
+// first tick after Start has run), no events are batched for the next tick and
+// no internal or region events are queued.
+// Because a tick runs eventless transitions to completion, a quiescent runtime
+// has no microsteps pending either. It returns ctx.Err() if ctx ends first and
+// statechartx.ErrRuntimeStopped if the tick loop stops while work is pending.
+func (rt *RealtimeRuntime) WaitIdle(ctx context.Context) error {
+	for {
+		rt.batchMu.Lock()
+		busy := rt.ticking || len(rt.eventBatch) > 0
+		if rt.tickDone == nil {
+			rt.tickDone = make(chan struct{})
+		}
+		tickDone := rt.tickDone
+		rt.batchMu.Unlock()
+
+		if !busy && !rt.hasQueuedEvents() {
+			return nil
+		}
+
+		select {
+		case <-tickDone:
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-rt.stopped:
+			return statechartx.ErrRuntimeStopped
+		}
+	}
+}
+
+// hasQueuedEvents reports whether internal or region events are waiting
+func (rt *RealtimeRuntime) hasQueuedEvents() bool {
+	rt.internalQueueMu.Lock()
+	internal := len(rt.internalEventQueue)
+	rt.internalQueueMu.Unlock()
+	if internal > 0 {
+		return true
+	}
+
+	rt.regionMu.RLock()
+	defer rt.regionMu.RUnlock()
+	for _, regions := range rt.parallelRegionStates {
+		for _, region := range regions {
+			if len(region.eventQueue) > 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// endTick marks the end of a tick and wakes WaitIdle callers. The caller holds batchMu.
+func (rt *RealtimeRuntime) endTick() {
+	rt.tickNum++
+	rt.ticking = false
+	if rt.resimulating && rt.tickNum == rt.present {
+		rt.finishResimulation()
+	}
+	if rt.tickDone != nil {
+		close(rt.tickDone)
+		rt.tickDone = nil
+	}
+}
@@ -21,21 +21,41 @@ func (rt *RealtimeRuntime) createParallelHooks() *statechartx.ParallelStateHooks
 		OnSendToRegions: func(ctx context.Context, event statechartx.Event) error {
 			return rt.sendEventToRegionsSequential(ctx, event)
 		},
+		OnStateEntered: func(state *statechartx.State) {
+			rt.startTimers(state.ID)
+		},
+		OnTransitionTaken: rt.transitionTaken,
 	}
 }
 
 // Start overrides the embedded Runtime's Start to use sequential parallel state processing
 func (rt *RealtimeRuntime) Start(ctx context.Context) error {
+	if rt.configErr != nil {
+		return rt.configErr
+	}
 
-	// Initialize the embedded runtime's context (needed for internal operations)
-	rt.Runtime.SetContext(ctx)
+	// Initialize the embedded runtime's context (needed for internal operations).
+	// Actions read the tick being processed from it (see hooks.go).
+	rt.actionCtx = context.WithValue(ctx, tickContextKey{}, rt)
+	rt.Runtime.SetContext(rt.actionCtx)
 
 	// Initialize machine state without using goroutines for parallel states
 	rt.enterInitialStateSequential(ctx)
 
+	// Eventless transitions of the initial configuration run on the first tick
+	rt.batchMu.Lock()
+	rt.ticking = true
+	rt.batchMu.Unlock()
+
 	// Start tick loop
 	rt.tickCtx, rt.tickCancel = context.WithCancel(ctx)
-	rt.ticker = time.NewTicker(rt.tickRate)
+	if rt.manual {
+		return nil
+	}
+	rt.batchMu.Lock()
+	rt.epoch = time.Now()
+	rt.ticker = time.NewTicker(rt.period)
+	rt.batchMu.Unlock()
 
 	go rt.tickLoop()
 
@@ -61,14 +81,12 @@ func (rt *RealtimeRuntime) enterInitialStateSequential(ctx context.Context) erro
 	// Find the deepest initial state
 	deepestInitial := machine.FindDeepestInitial(initialStateID)
 
-	// Build the path from root to deepest initial
+	// Build the path from root to deepest initial (ancestors are entered too,
+	// as in the event-driven runtime)
 	var path []statechartx.StateID
 	current := machine.GetState(deepestInitial)
 	for current != nil {
 		path = append([]statechartx.StateID{current.ID}, path...) // prepend
-		if current.ID == initialStateID {
-			break
-		}
 		current = current.Parent
 	}
 
@@ -89,16 +107,14 @@ func (rt *RealtimeRuntime) enterInitialStateSequential(ctx context.Context) erro
 		}
 
 		// Execute entry action
-		if state.EntryAction != nil {
-			if err := state.EntryAction(ctx, nil, 0, stateID); err != nil {
-				return err
-			}
+		if err := rt.runEntryActions(ctx, state, 0, stateID); err != nil {
+			return err
 		}
 
 		// Execute initial action if not at end
-		if i < len(path)-1 && state.InitialAction != nil {
+		if i < len(path)-1 {
 			nextStateID := path[i+1]
-			if err := state.InitialAction(ctx, nil, stateID, nextStateID); err != nil {
+			if err := state.RunInitialActions(ctx, nil, stateID, nextStateID); err != nil {
 				return err
 			}
 		}
@@ -138,10 +154,8 @@ func (rt *RealtimeRuntime) enterParallelState(ctx context.Context, state *statec
 	})
 
 	// Execute parent entry action first
-	if state.EntryAction != nil {
-		if err := state.EntryAction(ctx, nil, 0, state.ID); err != nil {
-			return err
-		}
+	if err := rt.runEntryActions(ctx, state, 0, state.ID); err != nil {
+		return err
 	}
 
 	// CRITICAL: Wrap the parallel state's ExitAction to ensure region exits happen
@@ -258,10 +272,8 @@ func (rt *RealtimeRuntime) exitParallelState(ctx context.Context, state *statech
 	}
 
 	// Execute parent exit action last
-	if state.ExitAction != nil {
-		if err := state.ExitAction(ctx, nil, state.ID, 0); err != nil {
-			return err
-		}
+	if err := state.RunExitActions(ctx, nil, state.ID, 0); err != nil {
+		return err
 	}
 
 	// Clean up region state
@@ -293,14 +305,12 @@ func (rt *RealtimeRuntime) enterRegionHierarchyWithoutMicrosteps(ctx context.Con
 		}
 
 		// Execute entry action
-		if state.EntryAction != nil {
-			state.EntryAction(ctx, nil, 0, stateID)
-		}
+		rt.runEntryActions(ctx, state, 0, stateID)
 
 		// Execute initial action if this is not the last state
-		if i < len(path)-1 && state.InitialAction != nil {
+		if i < len(path)-1 {
 			nextStateID := path[i+1]
-			state.InitialAction(ctx, nil, stateID, nextStateID)
+			state.RunInitialActions(ctx, nil, stateID, nextStateID)
 		}
 	}
 }
@@ -333,9 +343,7 @@ func (rt *RealtimeRuntime) exitRegionHierarchy(ctx context.Context, region *real
 			continue
 		}
 
-		if state.ExitAction != nil {
-			state.ExitAction(ctx, nil, state.ID, 0)
-		}
+		state.RunExitActions(ctx, nil, state.ID, 0)
 	}
 }
 
@@ -366,11 +374,8 @@ func (rt *RealtimeRuntime) processRegionMicrosteps(ctx context.Context, region *
 			}
 
 			// Check guard
-			if transition.Guard != nil {
-				ok, err := transition.Guard(ctx, nil, region.currentState, transition.Target)
-				if err != nil || !ok {
-					continue
-				}
+			if !rt.checkGuard(ctx, transition, nil, region.currentState) {
+				continue
 			}
 
 			// Found matching eventless transition
@@ -388,9 +393,7 @@ func (rt *RealtimeRuntime) processRegionMicrosteps(ctx context.Context, region *
 			// External transition
 			rt.exitRegionHierarchy(ctx, region)
 
-			if selectedTransition.Action != nil {
-				selectedTransition.Action(ctx, nil, region.currentState, selectedTransition.Target)
-			}
+			rt.runTransitionActions(ctx, selectedTransition, nil, region.currentState, selectedTransition.Target)
 
 			// Update region state to target
 			region.currentState = rt.Runtime.GetMachine().FindDeepestInitial(selectedTransition.Target)
@@ -403,9 +406,7 @@ func (rt *RealtimeRuntime) processRegionMicrosteps(ctx context.Context, region *
 			// Loop will continue to check for more NO_EVENT transitions in the new state
 		} else {
 			// Internal transition - just execute action, don't process further microsteps
-			if selectedTransition.Action != nil {
-				selectedTransition.Action(ctx, nil, region.currentState, 0)
-			}
+			rt.runTransitionActions(ctx, selectedTransition, nil, region.currentState, 0)
 			// Don't continue for internal transitions - they don't change state
 			return
 		}
@@ -568,7 +569,7 @@ func (rt *RealtimeRuntime) hasEventlessTransition(region *realtimeRegion) bool {
 
 		// Check guard if present
 		if transition.Guard != nil {
-			ok, err := transition.Guard(context.Background(), nil, region.currentState, transition.Target)
+			ok, err := transition.Guard(rt.actionCtx, nil, region.currentState, transition.Target)
 			if err != nil || !ok {
 				continue
 			}
@@ -595,11 +596,8 @@ func (rt *RealtimeRuntime) executeEventlessTransition(ctx context.Context, regio
 		}
 
 		// Check guard
-		if transition.Guard != nil {
-			ok, err := transition.Guard(ctx, nil, region.currentState, transition.Target)
-			if err != nil || !ok {
-				continue
-			}
+		if !rt.checkGuard(ctx, transition, nil, region.currentState) {
+			continue
 		}
 
 		selectedTransition = transition
@@ -615,9 +613,7 @@ func (rt *RealtimeRuntime) executeEventlessTransition(ctx context.Context, regio
 		// External transition
 		rt.exitRegionHierarchy(ctx, region)
 
-		if selectedTransition.Action != nil {
-			selectedTransition.Action(ctx, nil, region.currentState, selectedTransition.Target)
-		}
+		rt.runTransitionActions(ctx, selectedTransition, nil, region.currentState, selectedTransition.Target)
 
 		region.currentState = rt.Runtime.GetMachine().FindDeepestInitial(selectedTransition.Target)
 
@@ -627,9 +623,7 @@ func (rt *RealtimeRuntime) executeEventlessTransition(ctx context.Context, regio
 		}
 	} else {
 		// Internal transition
-		if selectedTransition.Action != nil {
-			selectedTransition.Action(ctx, nil, region.currentState, 0)
-		}
+		rt.runTransitionActions(ctx, selectedTransition, nil, region.currentState, 0)
 	}
 }
 
@@ -645,16 +639,13 @@ func (rt *RealtimeRuntime) processRegionEvent(ctx context.Context, region *realt
 	var selectedTransition *statechartx.Transition
 	for _, transition := range currentState.Transitions {
 		// Check event match
-		if transition.Event != event.ID && transition.Event != statechartx.ANY_EVENT {
+		if transition.Event != event.ID && (transition.Event != statechartx.ANY_EVENT || !event.ID.MatchesWildcard()) {
 			continue
 		}
 
 		// Check guard
-		if transition.Guard != nil {
-			ok, err := transition.Guard(ctx, &event, region.currentState, transition.Target)
-			if err != nil || !ok {
-				continue
-			}
+		if !rt.checkGuard(ctx, transition, &event, region.currentState) {
+			continue
 		}
 
 		// Found matching transition
@@ -671,9 +662,7 @@ func (rt *RealtimeRuntime) processRegionEvent(ctx context.Context, region *realt
 		// External transition - exit current, execute action, enter target
 		rt.exitRegionHierarchy(ctx, region)
 
-		if selectedTransition.Action != nil {
-			selectedTransition.Action(ctx, &event, region.currentState, selectedTransition.Target)
-		}
+		rt.runTransitionActions(ctx, selectedTransition, &event, region.currentState, selectedTransition.Target)
 
 		// Update region's current state
 		region.currentState = rt.Runtime.GetMachine().FindDeepestInitial(selectedTransition.Target)
@@ -685,9 +674,7 @@ func (rt *RealtimeRuntime) processRegionEvent(ctx context.Context, region *realt
 		}
 	} else {
 		// Internal transition - just execute action
-		if selectedTransition.Action != nil {
-			selectedTransition.Action(ctx, &event, region.currentState, 0)
-		}
+		rt.runTransitionActions(ctx, selectedTransition, &event, region.currentState, 0)
 	}
 }
 
@@ -709,16 +696,18 @@ func (rt *RealtimeRuntime) SendEvent(event statechartx.Event) error {
 	rt.batchMu.Lock()
 	defer rt.batchMu.Unlock()
 
+	if rt.rejectPaused() {
+		return ErrPaused
+	}
+	if rt.coalesceEvent(event, 0, 0) {
+		return nil
+	}
 	if len(rt.eventBatch) >= cap(rt.eventBatch) {
+		rt.eventMetrics.QueueFull++
 		return statechartx.ErrEventQueueFull
 	}
 
-	rt.eventBatch = append(rt.eventBatch, EventWithMeta{
-		Event:       event,
-		SequenceNum: rt.sequenceNum,
-		Priority:    0,
-	})
-	rt.sequenceNum++
+	rt.queueEvent(event, 0)
 	return nil
 }
 
@@ -0,0 +1,98 @@
+package realtime
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+// ErrPaused is returned when sending an event to a paused runtime configured
+// with PauseReject.
+var ErrPaused = errors.New("realtime: runtime is paused")
+
+// PausePolicy decides what happens to events sent while the runtime is paused.
+type PausePolicy int
+
+const (
+	// PauseBuffer batches events sent while paused for the first tick after
+	// Resume. This is the default.
+	PauseBuffer PausePolicy = iota
+	// PauseReject makes SendEvent and friends return ErrPaused while paused.
+	PauseReject
+)
+
+// Pause stops tick progression until Resume. The tick loop goroutine keeps
+// running and the event batch is kept; a tick in progress completes. While
+// paused the tick number does not advance, and the paused time is neither
+// simulated nor treated as a backlog by the catch-up policy. With
+// Config.Manual, Step still runs ticks; only the PausePolicy applies.
+func (rt *RealtimeRuntime) Pause() {
+	rt.batchMu.Lock()
+	defer rt.batchMu.Unlock()
+	rt.paused = true
+}
+
+// Resume continues tick progression after Pause. The first tick runs one
+// scaled TickRate later, with a Delta of one TickRate.
+func (rt *RealtimeRuntime) Resume() {
+	rt.batchMu.Lock()
+	defer rt.batchMu.Unlock()
+	if !rt.paused {
+		return
+	}
+	rt.paused = false
+	rt.rebaseClock()
+}
+
+// Paused reports whether the runtime is paused.
+func (rt *RealtimeRuntime) Paused() bool {
+	rt.batchMu.Lock()
+	defer rt.batchMu.Unlock()
+	return rt.paused
+}
+
+// SetTimeScale runs ticks scale times as fast as TickRate: 0.5 is slow motion
+// at half speed, 2 is fast forward. Only the wall-clock interval between ticks
+// changes; each tick still simulates one TickRate (TickInfo.Delta), so results
+// are identical at any scale. The tick budget for overruns is scaled too.
+// scale must be positive and finite.
+func (rt *RealtimeRuntime) SetTimeScale(scale float64) error {
+	if !(scale > 0) || math.IsInf(scale, 1) {
+		return fmt.Errorf("realtime: invalid time scale %v", scale)
+	}
+	period := time.Duration(float64(rt.tickRate) / scale)
+	if period <= 0 {
+		return fmt.Errorf("realtime: time scale %v too large for tick rate %v", scale, rt.tickRate)
+	}
+
+	rt.batchMu.Lock()
+	defer rt.batchMu.Unlock()
+	rt.timeScale = scale
+	rt.period = period
+	rt.rebaseClock()
+	if rt.ticker != nil {
+		rt.ticker.Reset(period)
+	}
+	return nil
+}
+
+// TimeScale returns the scale set with SetTimeScale (default 1).
+func (rt *RealtimeRuntime) TimeScale() float64 {
+	rt.batchMu.Lock()
+	defer rt.batchMu.Unlock()
+	return rt.timeScale
+}
+
+// rebaseClock restarts the tick loop's accumulator from now, so time before
+// a pause or scale change is not counted as due ticks. The caller holds batchMu.
+func (rt *RealtimeRuntime) rebaseClock() {
+	rt.epoch = time.Now()
+	rt.clockGen++
+}
+
+// rejectPaused reports whether an event sent now must be rejected with
+// ErrPaused. The caller holds batchMu.
+func (rt *RealtimeRuntime) rejectPaused() bool {
+	return rt.paused && rt.whilePaused == PauseReject && !rt.inTick
+}
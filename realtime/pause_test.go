@@ -0,0 +1,114 @@
+package realtime
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/comalice/statechartx"
+)
+
+// TestPauseResume tests that a paused runtime keeps its tick number and
+// batched events until resumed
+func TestPauseResume(t *testing.T) {
+	b := statechartx.NewMachineBuilder("app", "idle")
+	b.State("idle").On("go", "moving", nil, nil)
+	b.State("moving").Atomic()
+	machine, err := b.Build()
+	if err != nil {
+		t.Fatalf("Failed to create machine: %v", err)
+	}
+
+	rt := NewRuntime(machine, Config{TickRate: 2 * time.Millisecond})
+	if err := rt.Start(context.Background()); err != nil {
+		t.Fatalf("Failed to start runtime: %v", err)
+	}
+	defer rt.Stop()
+
+	time.Sleep(20 * time.Millisecond)
+	rt.Pause()
+	if !rt.Paused() {
+		t.Fatal("expected Paused to report true")
+	}
+	time.Sleep(5 * time.Millisecond) // let a tick in progress finish
+	paused, before := rt.GetTickNumber(), rt.TickMetrics()
+	if err := rt.SendEvent(statechartx.Event{ID: b.EventID("go")}); err != nil {
+		t.Fatalf("SendEvent while paused failed: %v", err)
+	}
+	time.Sleep(30 * time.Millisecond)
+	if tick := rt.GetTickNumber(); tick != paused || !rt.IsInState(b.GetID("idle")) {
+		t.Fatalf("expected no ticks while paused, got %d ticks after %d", tick, paused)
+	}
+
+	rt.Resume()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if err := rt.WaitIdle(ctx); err != nil {
+		t.Fatalf("WaitIdle failed: %v", err)
+	}
+	if !rt.IsInState(b.GetID("moving")) {
+		t.Errorf("expected the buffered event after Resume, got %s", b.GetName(rt.GetCurrentState()))
+	}
+	// 30ms paused at a 2ms tick would be a backlog of about 15 ticks; allow
+	// for a few ticks lost to scheduling on a loaded machine
+	m := rt.TickMetrics()
+	if backlog := m.DroppedTicks - before.DroppedTicks + m.SkippedTicks - before.SkippedTicks; backlog >= 5 {
+		t.Errorf("expected paused time not to count as a backlog, got %+v after %+v", m, before)
+	}
+}
+
+// TestPauseReject tests that PauseReject rejects events while paused
+func TestPauseReject(t *testing.T) {
+	machine, err := statechartx.NewMachine(&statechartx.State{ID: 1})
+	if err != nil {
+		t.Fatalf("Failed to create machine: %v", err)
+	}
+	rt := NewRuntime(machine, Config{Manual: true, WhilePaused: PauseReject})
+	if err := rt.Start(context.Background()); err != nil {
+		t.Fatalf("Failed to start runtime: %v", err)
+	}
+	defer rt.Stop()
+
+	rt.Pause()
+	if err := rt.SendEvent(statechartx.Event{ID: 1}); !errors.Is(err, ErrPaused) {
+		t.Errorf("expected ErrPaused from SendEvent, got %v", err)
+	}
+	if err := rt.SendEventAfterTicks(statechartx.Event{ID: 1}, 2); !errors.Is(err, ErrPaused) {
+		t.Errorf("expected ErrPaused from SendEventAfterTicks, got %v", err)
+	}
+	rt.Resume()
+	if err := rt.SendEventWithPriority(statechartx.Event{ID: 1}, 1); err != nil {
+		t.Errorf("expected SendEventWithPriority to succeed after Resume, got %v", err)
+	}
+}
+
+// TestSetTimeScale tests that the time scale changes how often ticks run
+func TestSetTimeScale(t *testing.T) {
+	machine, err := statechartx.NewMachine(&statechartx.State{ID: 1})
+	if err != nil {
+		t.Fatalf("Failed to create machine: %v", err)
+	}
+	rt := NewRuntime(machine, Config{TickRate: 5 * time.Millisecond})
+	for _, scale := range []float64{0, -1, math.NaN(), math.Inf(1)} {
+		if err := rt.SetTimeScale(scale); err == nil {
+			t.Errorf("expected an error for time scale %v", scale)
+		}
+	}
+	if err := rt.SetTimeScale(0.25); err != nil {
+		t.Fatalf("SetTimeScale failed: %v", err)
+	}
+	if err := rt.Start(context.Background()); err != nil {
+		t.Fatalf("Failed to start runtime: %v", err)
+	}
+	defer rt.Stop()
+
+	time.Sleep(100 * time.Millisecond) // 20 ticks at full speed, 5 at 0.25
+	if ticks := rt.GetTickNumber(); ticks < 3 || ticks > 8 {
+		t.Errorf("expected about 5 ticks at scale 0.25, got %d", ticks)
+	}
+	if rt.TimeScale() != 0.25 {
+		t.Errorf("expected time scale 0.25, got %v", rt.TimeScale())
+	}
+}
@@ -2,6 +2,9 @@ package realtime
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"io"
 	"testing"
 	"time"
 
@@ -251,3 +254,409 @@ func TestEventSorting(t *testing.T) {
 		}
 	}
 }
+
+// TestWaitIdle tests that WaitIdle returns once batched events, raised events
+// and eventless transitions have been processed
+func TestWaitIdle(t *testing.T) {
+	b := statechartx.NewMachineBuilder("app", "a")
+	b.State("a").On("go", "b", nil, nil)
+	b.State("b").On("next", "c", nil, nil)
+	b.State("c").On("", "d", nil, nil)
+	b.State("d").Atomic()
+	machine, err := b.Build()
+	if err != nil {
+		t.Fatalf("Failed to create machine: %v", err)
+	}
+
+	rt := NewRuntime(machine, Config{TickRate: 20 * time.Millisecond})
+	b.State("b").Entry(func(ctx context.Context, evt *statechartx.Event, from, to statechartx.StateID) error {
+		return rt.SendEvent(statechartx.Event{ID: b.EventID("next")})
+	})
+
+	ctx := context.Background()
+	if err := rt.Start(ctx); err != nil {
+		t.Fatalf("Failed to start runtime: %v", err)
+	}
+	defer rt.Stop()
+
+	if err := rt.SendEvent(statechartx.Event{ID: b.EventID("go")}); err != nil {
+		t.Fatal(err)
+	}
+	if err := rt.WaitIdle(ctx); err != nil {
+		t.Fatalf("WaitIdle failed: %v", err)
+	}
+	if !rt.IsInState(b.GetID("d")) {
+		t.Errorf("expected d after WaitIdle, got %s", b.GetName(rt.GetCurrentState()))
+	}
+
+	// An event waiting for the next tick is pending work
+	tick := rt.GetTickNumber()
+	rt.SendEvent(statechartx.Event{ID: b.EventID("go")})
+	waitCtx, cancel := context.WithTimeout(ctx, time.Millisecond)
+	defer cancel()
+	if err := rt.WaitIdle(waitCtx); err == nil && rt.GetTickNumber() == tick {
+		t.Error("WaitIdle should not return before the batched event's tick")
+	}
+}
+
+// mustStep runs one tick with Step and fails the test on error
+func mustStep(t *testing.T, rt *RealtimeRuntime) TickReport {
+	t.Helper()
+	report, err := rt.Step()
+	if err != nil {
+		t.Fatalf("Step failed: %v", err)
+	}
+	return report
+}
+
+// mustStepN runs n ticks with StepN and fails the test on error
+func mustStepN(t *testing.T, rt *RealtimeRuntime, n int) []TickReport {
+	t.Helper()
+	reports, err := rt.StepN(n)
+	if err != nil {
+		t.Fatalf("StepN failed: %v", err)
+	}
+	return reports
+}
+
+// TestStep tests that manual mode runs ticks only when stepped and reports
+// the events, transitions and configuration of each tick
+func TestStep(t *testing.T) {
+	b := statechartx.NewMachineBuilder("app", "a")
+	b.State("a").On("go", "b", nil, nil)
+	b.State("b").On("", "c", nil, nil)
+	b.State("c").Atomic()
+	machine, err := b.Build()
+	if err != nil {
+		t.Fatalf("Failed to create machine: %v", err)
+	}
+
+	if _, err := NewRuntime(machine, Config{}).Step(); !errors.Is(err, ErrNotManual) {
+		t.Errorf("expected ErrNotManual without Config.Manual, got %v", err)
+	}
+	rt := NewRuntime(machine, Config{TickRate: time.Millisecond, Manual: true})
+	if _, err := rt.Step(); !errors.Is(err, ErrNotStarted) {
+		t.Errorf("expected ErrNotStarted before Start, got %v", err)
+	}
+	if err := rt.Start(context.Background()); err != nil {
+		t.Fatalf("Failed to start runtime: %v", err)
+	}
+	defer rt.Stop()
+
+	rt.SendEvent(statechartx.Event{ID: b.EventID("go")})
+	time.Sleep(5 * time.Millisecond)
+	if rt.GetTickNumber() != 0 || !rt.IsInState(b.GetID("a")) {
+		t.Fatalf("manual runtime ticked on its own: tick %d, state %s", rt.GetTickNumber(), b.GetName(rt.GetCurrentState()))
+	}
+
+	report := mustStep(t, rt)
+	if report.Tick != 0 {
+		t.Errorf("expected tick 0, got %d", report.Tick)
+	}
+	if len(report.Events) != 1 || report.Events[0].ID != b.EventID("go") {
+		t.Errorf("expected events [go], got %v", report.Events)
+	}
+	if len(report.Transitions) != 2 || report.Transitions[0].Target != b.GetID("b") || report.Transitions[1].Target != b.GetID("c") {
+		t.Errorf("expected transitions to b then c, got %d transitions", len(report.Transitions))
+	}
+	want := []statechartx.StateID{b.GetID("app"), b.GetID("c")}
+	if len(report.Configuration) != 2 || report.Configuration[0] != want[0] || report.Configuration[1] != want[1] {
+		t.Errorf("expected configuration %v, got %v", want, report.Configuration)
+	}
+	if rt.GetTickNumber() != 1 {
+		t.Errorf("expected tick number 1, got %d", rt.GetTickNumber())
+	}
+
+	reports := mustStepN(t, rt, 3)
+	if len(reports) != 3 || reports[2].Tick != 3 || len(reports[2].Events) != 0 {
+		t.Errorf("expected 3 empty reports ending at tick 3, got %+v", reports)
+	}
+	if rt.GetTickNumber() != 4 {
+		t.Errorf("expected tick number 4, got %d", rt.GetTickNumber())
+	}
+}
+
+// TestStartEntersAncestors tests that Start runs the entry actions of the root
+// and every ancestor of the initial state, in document order, like the
+// event-driven runtime
+func TestStartEntersAncestors(t *testing.T) {
+	var entered []string
+	enter := func(name string) statechartx.Action {
+		return func(ctx context.Context, evt *statechartx.Event, from, to statechartx.StateID) error {
+			entered = append(entered, name)
+			return nil
+		}
+	}
+	b := statechartx.NewMachineBuilder("app", "outer")
+	b.State("app").Entry(enter("app"))
+	b.State("outer").Compound("outer.inner").Entry(enter("outer"))
+	b.State("outer.inner").Entry(enter("outer.inner"))
+	machine, err := b.Build()
+	if err != nil {
+		t.Fatalf("Failed to create machine: %v", err)
+	}
+
+	rt := startManual(t, machine)
+	defer rt.Stop()
+	if got := fmt.Sprint(entered); got != "[app outer outer.inner]" {
+		t.Errorf("expected entries [app outer outer.inner], got %s", got)
+	}
+}
+
+// TestIsInStateParallelRegions tests that IsInState sees the states of the
+// sequentially processed parallel regions and their ancestors
+func TestIsInStateParallelRegions(t *testing.T) {
+	b := statechartx.NewMachineBuilder("app", "both")
+	b.State("both").Parallel()
+	b.State("both.left").Compound("both.left.a")
+	b.State("both.left.a").Atomic()
+	b.State("both.left.b").Atomic()
+	b.State("both.right").Compound("both.right.x")
+	b.State("both.right.x").Atomic()
+	machine, err := b.Build()
+	if err != nil {
+		t.Fatalf("Failed to create machine: %v", err)
+	}
+
+	rt := startManual(t, machine)
+	defer rt.Stop()
+	mustStep(t, rt)
+	for _, name := range []string{"app", "both", "both.left", "both.left.a", "both.right", "both.right.x"} {
+		if !rt.IsInState(b.GetID(name)) {
+			t.Errorf("expected %s to be active", name)
+		}
+	}
+	if rt.IsInState(b.GetID("both.left.b")) {
+		t.Error("expected both.left.b to be inactive")
+	}
+}
+
+// countingRecorder counts the transitions reported to it
+type countingRecorder struct{ transitions int }
+
+func (r *countingRecorder) StateEntered(*statechartx.State)              {}
+func (r *countingRecorder) TransitionTaken(*statechartx.Transition)      { r.transitions++ }
+func (r *countingRecorder) GuardEvaluated(*statechartx.Transition, bool) {}
+func (r *countingRecorder) HistoryRestored(*statechartx.State, bool)     {}
+
+// TestStepKeepsCoverage tests that reporting a tick leaves the user's Coverage
+// recorder in place and still reports to it
+func TestStepKeepsCoverage(t *testing.T) {
+	b := statechartx.NewMachineBuilder("app", "a")
+	var rt *RealtimeRuntime
+	rec := &countingRecorder{}
+	var seen statechartx.CoverageRecorder
+	b.State("a").On("go", "b", nil, func(ctx context.Context, evt *statechartx.Event, from, to statechartx.StateID) error {
+		seen = rt.Coverage
+		return nil
+	})
+	b.State("b").Atomic()
+	machine, err := b.Build()
+	if err != nil {
+		t.Fatalf("Failed to create machine: %v", err)
+	}
+
+	rt = NewRuntime(machine, Config{Manual: true})
+	rt.Coverage = rec
+	if err := rt.Start(context.Background()); err != nil {
+		t.Fatalf("Failed to start runtime: %v", err)
+	}
+	defer rt.Stop()
+
+	rt.SendEvent(statechartx.Event{ID: b.EventID("go")})
+	report := mustStep(t, rt)
+	if seen != rec {
+		t.Errorf("expected Coverage to stay the user's recorder during the tick, got %T", seen)
+	}
+	if len(report.Transitions) != 1 || rec.transitions != 1 {
+		t.Errorf("expected 1 reported and 1 recorded transition, got %d and %d", len(report.Transitions), rec.transitions)
+	}
+}
+
+// TestTickHashDivergence tests that two lockstep peers produce the same tick
+// hashes until their events differ, and that FindDivergence reports the tick
+func TestTickHashDivergence(t *testing.T) {
+	b := statechartx.NewMachineBuilder("app", "idle")
+	b.State("idle").On("left", "l", nil, nil).On("right", "r", nil, nil)
+	b.State("l").Atomic()
+	b.State("r").Atomic()
+	machine, err := b.Build()
+	if err != nil {
+		t.Fatalf("Failed to create machine: %v", err)
+	}
+
+	score := map[*RealtimeRuntime]int{}
+	newPeer := func() *RealtimeRuntime {
+		var rt *RealtimeRuntime
+		rt = NewRuntime(machine, Config{Manual: true, HashHistory: 3, HashExtendedState: func(w io.Writer) {
+			fmt.Fprintf(w, "score=%d", score[rt])
+		}})
+		if err := rt.Start(context.Background()); err != nil {
+			t.Fatalf("Failed to start runtime: %v", err)
+		}
+		return rt
+	}
+	a, c := newPeer(), newPeer()
+	defer a.Stop()
+	defer c.Stop()
+
+	a.StepN(2)
+	c.StepN(2)
+	if d := FindDivergence(a.TickRecords(), c.TickRecords()); d != nil {
+		t.Fatalf("expected no divergence, got %s", d)
+	}
+
+	// Extended state alone changes the hash
+	score[a] = 1
+	a.Step()
+	c.Step()
+	d := FindDivergence(a.TickRecords(), c.TickRecords())
+	if d == nil || d.Tick != 2 || len(d.OnlyA) != 0 || d.EventsDiffer {
+		t.Fatalf("expected extended state divergence at tick 2, got %v", d)
+	}
+	score[a] = 0
+
+	a.SendEvent(statechartx.Event{ID: b.EventID("left")})
+	c.SendEvent(statechartx.Event{ID: b.EventID("right")})
+	a.Step()
+	c.Step()
+
+	// Tick 0 has left the 3-tick window
+	if _, ok := a.TickHash(0); ok {
+		t.Error("expected tick 0 to be pruned")
+	}
+	hash, ok := a.TickHash(3)
+	if !ok || hash != a.StateHash() {
+		t.Errorf("expected TickHash(3) to match the current state hash")
+	}
+
+	records := a.TickRecords()
+	d = FindDivergence(records[2:], c.TickRecords())
+	if d == nil {
+		t.Fatal("expected a divergence")
+	}
+	if d.Tick != 3 || !d.EventsDiffer || d.EventTick != 3 {
+		t.Errorf("expected event divergence at tick 3, got %s", d)
+	}
+	if len(d.OnlyA) != 1 || d.OnlyA[0] != b.GetID("l") || len(d.OnlyB) != 1 || d.OnlyB[0] != b.GetID("r") {
+		t.Errorf("expected configuration diff l/r, got %v/%v", d.OnlyA, d.OnlyB)
+	}
+}
+
+// TestRollbackErrors tests the rollback preconditions
+func TestRollbackErrors(t *testing.T) {
+	b := statechartx.NewMachineBuilder("app", "a")
+	b.State("a").On("go", "b", nil, nil)
+	b.State("b").Atomic()
+	machine, err := b.Build()
+	if err != nil {
+		t.Fatalf("Failed to create machine: %v", err)
+	}
+
+	ticked := NewRuntime(machine, Config{SnapshotHistory: 4})
+	if err := ticked.Rollback(0); !errors.Is(err, ErrNotManual) {
+		t.Errorf("expected ErrNotManual, got %v", err)
+	}
+
+	rt := NewRuntime(machine, Config{Manual: true, SnapshotHistory: 4})
+	if err := rt.Start(context.Background()); err != nil {
+		t.Fatalf("Failed to start runtime: %v", err)
+	}
+	defer rt.Stop()
+	rt.StepN(6)
+
+	if err := rt.Rollback(1); !errors.Is(err, ErrTickNotRetained) {
+		t.Errorf("expected ErrTickNotRetained for a pruned tick, got %v", err)
+	}
+	if err := rt.Rollback(7); !errors.Is(err, ErrTickNotRetained) {
+		t.Errorf("expected ErrTickNotRetained for a future tick, got %v", err)
+	}
+	if err := rt.InsertEventAt(7, statechartx.Event{ID: b.EventID("go")}); err == nil {
+		t.Error("expected an error inserting at a future tick")
+	}
+
+	// Inserting at an already run tick only takes effect after re-simulating
+	if err := rt.InsertEventAt(3, statechartx.Event{ID: b.EventID("go")}); err != nil {
+		t.Fatalf("InsertEventAt failed: %v", err)
+	}
+	if !rt.IsInState(b.GetID("a")) {
+		t.Fatalf("expected a before re-simulation, got %s", b.GetName(rt.GetCurrentState()))
+	}
+	if err := rt.Rollback(2); err != nil {
+		t.Fatalf("Rollback failed: %v", err)
+	}
+	if err := rt.Resimulate(); err != nil {
+		t.Fatalf("Resimulate failed: %v", err)
+	}
+	if !rt.IsInState(b.GetID("b")) || rt.GetTickNumber() != 6 {
+		t.Errorf("expected b at tick 6, got %s at tick %d", b.GetName(rt.GetCurrentState()), rt.GetTickNumber())
+	}
+}
+
+// TestCatchUpPolicies tests overrun detection and how each catch-up policy
+// accounts for the tick slots missed while a slow tick ran
+func TestCatchUpPolicies(t *testing.T) {
+	for _, tc := range []struct {
+		name   string
+		policy CatchUpPolicy
+	}{
+		{"SlowMotion", CatchUpSlowMotion},
+		{"Skip", CatchUpSkip},
+		{"Burst", CatchUpBurst},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			b := statechartx.NewMachineBuilder("app", "a")
+			b.State("a").On("slow", "b", nil, func(ctx context.Context, evt *statechartx.Event, from, to statechartx.StateID) error {
+				time.Sleep(30 * time.Millisecond)
+				return nil
+			})
+			b.State("b").Atomic()
+			machine, err := b.Build()
+			if err != nil {
+				t.Fatalf("Failed to create machine: %v", err)
+			}
+
+			overruns := make(chan uint64, 10)
+			rt := NewRuntime(machine, Config{
+				TickRate:        5 * time.Millisecond,
+				CatchUp:         tc.policy,
+				MaxCatchUpTicks: 2,
+				OnOverrun: func(tick uint64, took time.Duration) {
+					overruns <- tick
+				},
+			})
+			if err := rt.Start(context.Background()); err != nil {
+				t.Fatalf("Failed to start runtime: %v", err)
+			}
+			rt.SendEvent(statechartx.Event{ID: b.EventID("slow")})
+			time.Sleep(60 * time.Millisecond)
+			rt.Stop()
+
+			select {
+			case <-overruns:
+			default:
+				t.Error("expected OnOverrun to be called")
+			}
+			m := rt.TickMetrics()
+			if m.Overruns == 0 || m.MaxDuration < 30*time.Millisecond {
+				t.Errorf("expected an overrun of at least 30ms, got %+v", m)
+			}
+
+			ticks := rt.GetTickNumber()
+			switch tc.policy {
+			case CatchUpSlowMotion:
+				if m.DroppedTicks == 0 || m.SkippedTicks != 0 || ticks != m.Ticks {
+					t.Errorf("expected dropped slots and tick number %d == ticks run, got %+v", ticks, m)
+				}
+			case CatchUpSkip:
+				if m.SkippedTicks == 0 || m.CatchUpTicks != 0 || ticks != m.Ticks+m.SkippedTicks {
+					t.Errorf("expected skipped ticks counted in tick number %d, got %+v", ticks, m)
+				}
+			case CatchUpBurst:
+				if m.CatchUpTicks == 0 || ticks != m.Ticks+m.SkippedTicks {
+					t.Errorf("expected catch-up ticks and tick number %d == run + skipped, got %+v", ticks, m)
+				}
+			}
+		})
+	}
+}
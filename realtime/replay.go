@@ -0,0 +1,355 @@
+package realtime
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/comalice/statechartx"
+)
+
+// ReplayFormatVersion is the current version of the replay file format.
+const ReplayFormatVersion = 1
+
+// Replay errors
+var (
+	ErrFingerprintMismatch = errors.New("realtime: replay was recorded with a different machine")
+	ErrCheckpointMismatch  = errors.New("realtime: replay checkpoint mismatch")
+)
+
+// A replay file is JSON Lines: a ReplayHeader followed by one ReplayTick per
+// tick that received events or carries a checkpoint, in tick order, and a
+// final ReplayTick with End set. Only events sent from outside the runtime are
+// recorded; events raised by actions are regenerated when the file is played.
+//
+//	{"version":1,"fingerprint":"9c1e0b7a5d3f2e41","tickRate":16667000}
+//	{"tick":0,"configuration":[1,2]}
+//	{"tick":3,"events":[{"id":1,"seq":0}],"configuration":[1,3]}
+//	{"tick":10,"end":true}
+
+// ReplayHeader is the first line of a replay file.
+type ReplayHeader struct {
+	Version     int           `json:"version"`
+	Fingerprint string        `json:"fingerprint"` // Fingerprint of the recorded machine
+	TickRate    time.Duration `json:"tickRate"`    // nanoseconds
+}
+
+// ReplayTick is the record of one tick: the events it received, in the order
+// they were sent, and optionally the configuration it ended in.
+type ReplayTick struct {
+	Tick          uint64                `json:"tick"`
+	Events        []ReplayEvent         `json:"events,omitempty"`
+	Configuration []statechartx.StateID `json:"configuration,omitempty"` // checkpoint, sorted
+	End           bool                  `json:"end,omitempty"`           // recording stopped before this tick
+}
+
+// ReplayEvent is a recorded event with its ordering metadata.
+type ReplayEvent struct {
+	ID       statechartx.EventID `json:"id"`
+	Address  statechartx.StateID `json:"address,omitempty"`
+	Priority int                 `json:"priority,omitempty"`
+	Seq      uint64              `json:"seq"`
+	At       uint64              `json:"at,omitempty"` // tick the event was scheduled for (see SendEventAtTick)
+	Data     json.RawMessage     `json:"data,omitempty"`
+}
+
+// DataCodec converts Event.Data to and from the JSON stored in replay files.
+type DataCodec interface {
+	EncodeData(event statechartx.Event) (json.RawMessage, error)
+	DecodeData(id statechartx.EventID, data json.RawMessage) (any, error)
+}
+
+// JSONCodec is the default DataCodec. It marshals Data with encoding/json and
+// decodes it into the generic JSON types (map[string]any, float64, ...), so
+// actions that type-assert Data need a codec that knows the concrete types.
+type JSONCodec struct{}
+
+// EncodeData marshals event.Data, or returns nil if it is nil.
+func (JSONCodec) EncodeData(event statechartx.Event) (json.RawMessage, error) {
+	if event.Data == nil {
+		return nil, nil
+	}
+	return json.Marshal(event.Data)
+}
+
+// DecodeData unmarshals data into an any.
+func (JSONCodec) DecodeData(id statechartx.EventID, data json.RawMessage) (any, error) {
+	var v any
+	err := json.Unmarshal(data, &v)
+	return v, err
+}
+
+// Fingerprint returns a hash of the machine's structure: state IDs, hierarchy,
+// kinds, initial and history defaults, and transition events and targets.
+// Actions and guards are not included.
+func Fingerprint(m *statechartx.Machine) string {
+	h := fnv.New64a()
+	var buf [8]byte
+	writeInt := func(v int64) {
+		binary.LittleEndian.PutUint64(buf[:], uint64(v))
+		h.Write(buf[:])
+	}
+	writeBool := func(b bool) {
+		if b {
+			writeInt(1)
+		} else {
+			writeInt(0)
+		}
+	}
+
+	for _, id := range m.StateIDs() {
+		s := m.GetState(id)
+		writeInt(int64(s.ID))
+		if s.Parent != nil {
+			writeInt(int64(s.Parent.ID))
+		} else {
+			writeInt(-1)
+		}
+		writeInt(int64(s.Initial))
+		writeBool(s.IsParallel)
+		writeBool(s.IsFinal || s.Final)
+		writeBool(s.IsHistoryState)
+		writeInt(int64(s.HistoryType))
+		writeInt(int64(s.HistoryDefault))
+		writeInt(int64(len(s.Transitions)))
+		for _, t := range s.Transitions {
+			writeInt(int64(t.Event))
+			writeInt(int64(t.Target))
+			writeBool(t.Guard != nil || t.GuardRef != "")
+		}
+	}
+	return fmt.Sprintf("%016x", h.Sum64())
+}
+
+// RecorderOptions configures a Recorder.
+type RecorderOptions struct {
+	// Codec encodes Event.Data. Nil means JSONCodec.
+	Codec DataCodec
+	// CheckpointInterval is the number of ticks between configuration
+	// checkpoints. Zero checkpoints every tick.
+	CheckpointInterval uint64
+}
+
+// Recorder writes the ticks of a RealtimeRuntime to a replay file.
+type Recorder struct {
+	rt       *RealtimeRuntime
+	enc      *json.Encoder
+	codec    DataCodec
+	interval uint64
+
+	mu     sync.Mutex
+	err    error // first write error; later ticks are dropped
+	closed bool
+}
+
+// Record writes a replay header to w and records every following tick of rt
+// until the returned Recorder is closed. Only one Recorder can be attached to a
+// runtime at a time; start it before the first tick to capture a full session.
+func (rt *RealtimeRuntime) Record(w io.Writer, opts RecorderOptions) (*Recorder, error) {
+	rec := &Recorder{
+		rt:       rt,
+		enc:      json.NewEncoder(w),
+		codec:    opts.Codec,
+		interval: opts.CheckpointInterval,
+	}
+	if rec.codec == nil {
+		rec.codec = JSONCodec{}
+	}
+	if rec.interval == 0 {
+		rec.interval = 1
+	}
+
+	header := ReplayHeader{
+		Version:     ReplayFormatVersion,
+		Fingerprint: Fingerprint(rt.Runtime.GetMachine()),
+		TickRate:    rt.tickRate,
+	}
+	if err := rec.enc.Encode(header); err != nil {
+		return nil, err
+	}
+
+	rt.batchMu.Lock()
+	defer rt.batchMu.Unlock()
+	if rt.recorder != nil {
+		return nil, errors.New("realtime: a recorder is already attached")
+	}
+	rt.recorder = rec
+	return rec, nil
+}
+
+// Close detaches the recorder, writes the end marker and returns the first
+// error encountered while recording.
+func (rec *Recorder) Close() error {
+	rec.rt.batchMu.Lock()
+	if rec.rt.recorder == rec {
+		rec.rt.recorder = nil
+	}
+	tick := rec.rt.tickNum
+	rec.rt.batchMu.Unlock()
+
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	if rec.closed {
+		return rec.err
+	}
+	rec.closed = true
+	if rec.err == nil {
+		rec.err = rec.enc.Encode(ReplayTick{Tick: tick, End: true})
+	}
+	return rec.err
+}
+
+// recordTick writes one tick's outside events and checkpoint
+func (rec *Recorder) recordTick(tick uint64, events []EventWithMeta) {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+	if rec.closed || rec.err != nil {
+		return
+	}
+
+	line := ReplayTick{Tick: tick}
+	for _, e := range events {
+		if e.raised {
+			continue
+		}
+		data, err := rec.codec.EncodeData(e.Event)
+		if err != nil {
+			rec.err = fmt.Errorf("realtime: encoding data of event %d at tick %d: %w", e.Event.ID, tick, err)
+			return
+		}
+		line.Events = append(line.Events, ReplayEvent{
+			ID:       e.Event.ID,
+			Address:  e.Event.Address,
+			Priority: e.Priority,
+			Seq:      e.SequenceNum,
+			At:       e.at,
+			Data:     data,
+		})
+	}
+	sort.Slice(line.Events, func(i, j int) bool { return line.Events[i].Seq < line.Events[j].Seq })
+	if tick%rec.interval == 0 {
+		line.Configuration = rec.rt.Configuration()
+	}
+	if len(line.Events) == 0 && line.Configuration == nil {
+		return
+	}
+	rec.err = rec.enc.Encode(line)
+}
+
+// recordReplay passes the tick to the attached Recorder, if any (called from processTick)
+func (rt *RealtimeRuntime) recordReplay(events []EventWithMeta) {
+	rt.batchMu.Lock()
+	rec, tick := rt.recorder, rt.tickNum
+	rt.batchMu.Unlock()
+	if rec != nil {
+		rec.recordTick(tick, events)
+	}
+}
+
+// Player drives a manually stepped RealtimeRuntime from a replay file.
+type Player struct {
+	dec    *json.Decoder
+	codec  DataCodec
+	header ReplayHeader
+}
+
+// NewPlayer reads the replay header from r. A nil codec means JSONCodec.
+func NewPlayer(r io.Reader, codec DataCodec) (*Player, error) {
+	p := &Player{dec: json.NewDecoder(r), codec: codec}
+	if p.codec == nil {
+		p.codec = JSONCodec{}
+	}
+	if err := p.dec.Decode(&p.header); err != nil {
+		return nil, fmt.Errorf("realtime: reading replay header: %w", err)
+	}
+	if p.header.Version != ReplayFormatVersion {
+		return nil, fmt.Errorf("realtime: unsupported replay file version %d", p.header.Version)
+	}
+	return p, nil
+}
+
+// Header returns the replay file's header.
+func (p *Player) Header() ReplayHeader {
+	return p.header
+}
+
+// Play steps rt through the recording: before each recorded tick it sends the
+// tick's events in their original order and priority, and after it compares
+// the configuration with the recorded checkpoint. rt must use Config.Manual,
+// be started, and not be past the first recorded tick. Play returns an error
+// wrapping ErrFingerprintMismatch if rt runs a different machine and
+// ErrCheckpointMismatch at the first diverging checkpoint.
+func (p *Player) Play(rt *RealtimeRuntime) error {
+	if !rt.manual {
+		return ErrNotManual
+	}
+	if fp := Fingerprint(rt.Runtime.GetMachine()); fp != p.header.Fingerprint {
+		return fmt.Errorf("%w: recorded %s, got %s", ErrFingerprintMismatch, p.header.Fingerprint, fp)
+	}
+
+	for {
+		var line ReplayTick
+		if err := p.dec.Decode(&line); err != nil {
+			if err == io.EOF {
+				return errors.New("realtime: replay file ends without an end marker")
+			}
+			return fmt.Errorf("realtime: reading replay: %w", err)
+		}
+		if rt.GetTickNumber() > line.Tick {
+			return fmt.Errorf("realtime: runtime is at tick %d, past recorded tick %d", rt.GetTickNumber(), line.Tick)
+		}
+		for rt.GetTickNumber() < line.Tick {
+			if _, err := rt.Step(); err != nil {
+				return err
+			}
+		}
+		if line.End {
+			return nil
+		}
+
+		for _, e := range line.Events {
+			event := statechartx.Event{ID: e.ID, Address: e.Address}
+			if len(e.Data) > 0 {
+				data, err := p.codec.DecodeData(e.ID, e.Data)
+				if err != nil {
+					return fmt.Errorf("realtime: decoding data of event %d at tick %d: %w", e.ID, line.Tick, err)
+				}
+				event.Data = data
+			}
+			send := func() error { return rt.SendEventWithPriority(event, e.Priority) }
+			if e.At != 0 {
+				send = func() error { return rt.scheduleEvent(event, e.Priority, e.At) }
+			}
+			if err := send(); err != nil {
+				return fmt.Errorf("realtime: replaying event %d at tick %d: %w", e.ID, line.Tick, err)
+			}
+		}
+
+		report, err := rt.Step()
+		if err != nil {
+			return err
+		}
+		if line.Configuration != nil && !sameConfiguration(line.Configuration, report.Configuration) {
+			return fmt.Errorf("%w at tick %d: expected %v, got %v", ErrCheckpointMismatch, line.Tick, line.Configuration, report.Configuration)
+		}
+	}
+}
+
+// sameConfiguration reports whether two sorted configurations are equal
+func sameConfiguration(a, b []statechartx.StateID) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
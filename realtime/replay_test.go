@@ -0,0 +1,143 @@
+package realtime
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/comalice/statechartx"
+)
+
+// replayMachine builds a chart whose guard reads event data and whose entry
+// action raises an event, so replays must restore data and must not duplicate
+// raised events
+func replayMachine(t *testing.T, threshold float64) (*statechartx.MachineBuilder, *statechartx.Machine) {
+	t.Helper()
+	b := statechartx.NewMachineBuilder("app", "idle")
+	b.State("idle").On("move", "moving", func(ctx context.Context, evt *statechartx.Event, from, to statechartx.StateID) (bool, error) {
+		speed, _ := evt.Data.(map[string]any)["speed"].(float64)
+		return speed > threshold, nil
+	}, nil)
+	b.State("moving").On("stop", "idle", nil, nil).On("tick", "moving.fast", nil, nil)
+	b.State("moving.slow").Atomic()
+	b.State("moving.fast").Atomic()
+	b.State("moving").Compound("moving.slow")
+	machine, err := b.Build()
+	if err != nil {
+		t.Fatalf("Failed to create machine: %v", err)
+	}
+	return b, machine
+}
+
+// startManual starts a manually stepped runtime for machine
+func startManual(t *testing.T, machine *statechartx.Machine) *RealtimeRuntime {
+	t.Helper()
+	rt := NewRuntime(machine, Config{Manual: true, HashHistory: 64})
+	if err := rt.Start(context.Background()); err != nil {
+		t.Fatalf("Failed to start runtime: %v", err)
+	}
+	return rt
+}
+
+// TestRecordAndPlay tests that playing a recording reproduces every tick
+func TestRecordAndPlay(t *testing.T) {
+	b, machine := replayMachine(t, 1)
+	rt := startManual(t, machine)
+	defer rt.Stop()
+	b.State("moving.slow").Entry(func(ctx context.Context, evt *statechartx.Event, from, to statechartx.StateID) error {
+		return rt.SendEvent(statechartx.Event{ID: b.EventID("tick")})
+	})
+
+	var file bytes.Buffer
+	rec, err := rt.Record(&file, RecorderOptions{CheckpointInterval: 2})
+	if err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+	rt.Step()
+	rt.SendEvent(statechartx.Event{ID: b.EventID("move"), Data: map[string]any{"speed": 0.5}})
+	rt.Step()
+	rt.SendEventWithPriority(statechartx.Event{ID: b.EventID("stop")}, 1)
+	rt.SendEvent(statechartx.Event{ID: b.EventID("move"), Data: map[string]any{"speed": 2.0}})
+	rt.StepN(4)
+	if err := rec.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	if !rt.IsInState(b.GetID("moving.fast")) {
+		t.Fatalf("expected moving.fast after recording, got %s", b.GetName(rt.GetCurrentState()))
+	}
+
+	player, err := NewPlayer(strings.NewReader(file.String()), nil)
+	if err != nil {
+		t.Fatalf("NewPlayer failed: %v", err)
+	}
+	if player.Header().Fingerprint != Fingerprint(machine) || player.Header().TickRate != rt.tickRate {
+		t.Errorf("unexpected header %+v", player.Header())
+	}
+
+	_, machine2 := replayMachine(t, 1)
+	replay := startManual(t, machine2)
+	defer replay.Stop()
+	machine2.GetState(b.GetID("moving.slow")).EntryAction = func(ctx context.Context, evt *statechartx.Event, from, to statechartx.StateID) error {
+		return replay.SendEvent(statechartx.Event{ID: b.EventID("tick")})
+	}
+	if err := player.Play(replay); err != nil {
+		t.Fatalf("Play failed: %v", err)
+	}
+	if replay.GetTickNumber() != rt.GetTickNumber() {
+		t.Errorf("expected tick %d after playing, got %d", rt.GetTickNumber(), replay.GetTickNumber())
+	}
+	if d := FindDivergence(rt.TickRecords(), replay.TickRecords()); d != nil {
+		t.Errorf("expected identical ticks, got %s", d)
+	}
+}
+
+// TestPlayMismatch tests that playing into a different machine or a machine
+// that behaves differently is reported
+func TestPlayMismatch(t *testing.T) {
+	b, machine := replayMachine(t, 1)
+	rt := startManual(t, machine)
+	defer rt.Stop()
+
+	var file bytes.Buffer
+	rec, err := rt.Record(&file, RecorderOptions{})
+	if err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+	if _, err := rt.Record(&bytes.Buffer{}, RecorderOptions{}); err == nil {
+		t.Error("expected an error attaching a second recorder")
+	}
+	rt.SendEvent(statechartx.Event{ID: b.EventID("move"), Data: map[string]any{"speed": 2.0}})
+	rt.StepN(3)
+	rec.Close()
+
+	other := statechartx.NewMachineBuilder("app", "idle")
+	other.State("idle").Atomic()
+	otherMachine, err := other.Build()
+	if err != nil {
+		t.Fatalf("Failed to create machine: %v", err)
+	}
+	player, err := NewPlayer(bytes.NewReader(file.Bytes()), nil)
+	if err != nil {
+		t.Fatalf("NewPlayer failed: %v", err)
+	}
+	otherRT := startManual(t, otherMachine)
+	defer otherRT.Stop()
+	if err := player.Play(otherRT); !errors.Is(err, ErrFingerprintMismatch) {
+		t.Errorf("expected ErrFingerprintMismatch, got %v", err)
+	}
+
+	// Same structure, but the guard rejects the recorded speed
+	_, strict := replayMachine(t, 5)
+	player, err = NewPlayer(bytes.NewReader(file.Bytes()), nil)
+	if err != nil {
+		t.Fatalf("NewPlayer failed: %v", err)
+	}
+	strictRT := startManual(t, strict)
+	defer strictRT.Stop()
+	err = player.Play(strictRT)
+	if !errors.Is(err, ErrCheckpointMismatch) || !strings.Contains(err.Error(), "at tick 0") {
+		t.Errorf("expected ErrCheckpointMismatch at tick 0, got %v", err)
+	}
+}
@@ -0,0 +1,273 @@
+package realtime
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/comalice/statechartx"
+)
+
+var (
+	// ErrNotManual is returned by Step, the rollback API, Player.Play and
+	// World.Step when Config.Manual (or WorldConfig.Manual) is not set.
+	ErrNotManual = errors.New("realtime: manual stepping requires Manual to be set")
+	// ErrTickNotRetained is returned for ticks older than Config.SnapshotHistory
+	// allows, or for any tick when snapshots are disabled.
+	ErrTickNotRetained = errors.New("realtime: tick snapshot not retained")
+)
+
+// Cloner captures and restores the user's extended state for Rollback.
+// Snapshot runs at the start of every tick and must return a value that later
+// ticks do not mutate; Restore receives it back when rolling back to that tick
+// and may be called with the same value more than once.
+type Cloner interface {
+	Snapshot() any
+	Restore(snapshot any)
+}
+
+// tickSnapshot is the state of the runtime at the start of a tick, after its
+// events were collected
+type tickSnapshot struct {
+	tick  uint64
+	valid bool
+
+	current  statechartx.StateID
+	shallow  map[statechartx.StateID]statechartx.StateID
+	deep     map[statechartx.StateID][]statechartx.StateID
+	regions  map[statechartx.StateID]map[statechartx.StateID]*realtimeRegion
+	internal []statechartx.Event
+	extended any
+
+	scheduled []EventWithMeta // events due at later ticks
+	timerGen  map[statechartx.StateID]uint64
+
+	sequenceNum uint64          // sequence number of the first external event
+	raised      []EventWithMeta // events raised by the previous tick
+	external    []EventWithMeta // events sent from outside, in arrival order
+}
+
+// Rollback restores the runtime to the start of tick, as it was before the
+// tick's events were processed, and sets GetTickNumber to tick. The ticks
+// between tick and the present are then re-run by Resimulate (or one at a time
+// by Step) with the events they originally received plus any added with
+// InsertEventAt. Events sent before Rollback for the present tick, and events
+// sent from outside while re-simulating, are kept for the present tick.
+//
+// Rolling back again before reaching the present is allowed; the present does
+// not move. Rollback requires Config.Manual and a snapshot of tick (see
+// Config.SnapshotHistory), and fails while a replay Recorder is attached.
+func (rt *RealtimeRuntime) Rollback(tick uint64) error {
+	if !rt.manual {
+		return ErrNotManual
+	}
+
+	rt.batchMu.Lock()
+	if tick == rt.tickNum {
+		rt.batchMu.Unlock()
+		return nil
+	}
+	if rt.recorder != nil {
+		rt.batchMu.Unlock()
+		return errors.New("realtime: cannot roll back while recording a replay")
+	}
+	snap := rt.snapshotAt(tick)
+	if snap == nil || tick > rt.tickNum {
+		rt.batchMu.Unlock()
+		return fmt.Errorf("%w: tick %d", ErrTickNotRetained, tick)
+	}
+
+	if !rt.resimulating {
+		rt.resimulating = true
+		rt.present = rt.tickNum
+		for _, e := range rt.eventBatch {
+			if !e.raised {
+				rt.pending = append(rt.pending, e)
+			}
+		}
+	}
+	rt.tickNum = tick
+	rt.sequenceNum = snap.sequenceNum
+	batch := make([]EventWithMeta, 0, cap(rt.eventBatch))
+	rt.eventBatch = append(batch, snap.raised...)
+	rt.resetCoalesceIndex()
+	rt.scheduled = append([]EventWithMeta(nil), snap.scheduled...)
+	rt.timerGen = make(map[statechartx.StateID]uint64, len(snap.timerGen))
+	for id, gen := range snap.timerGen {
+		rt.timerGen[id] = gen
+	}
+	restore := *snap
+	rt.batchMu.Unlock()
+
+	rt.Runtime.SetCurrentState(restore.current)
+	rt.Runtime.SetHistory(restore.shallow, restore.deep)
+
+	rt.regionMu.Lock()
+	rt.parallelRegionStates = copyRegions(restore.regions)
+	rt.regionMu.Unlock()
+
+	rt.internalQueueMu.Lock()
+	rt.internalEventQueue = append([]statechartx.Event(nil), restore.internal...)
+	rt.inMacrostep = false
+	rt.internalQueueMu.Unlock()
+
+	if rt.cloner != nil {
+		rt.cloner.Restore(restore.extended)
+	}
+	return nil
+}
+
+// InsertEventAt adds a late event to the events tick received from outside,
+// after the ones already recorded. For a tick that has already run, the event
+// takes effect once the runtime is rolled back to that tick (or earlier) and
+// re-simulated. For the present tick it is equivalent to SendEvent.
+func (rt *RealtimeRuntime) InsertEventAt(tick uint64, event statechartx.Event) error {
+	if !rt.manual {
+		return ErrNotManual
+	}
+
+	rt.batchMu.Lock()
+	defer rt.batchMu.Unlock()
+
+	present := rt.tickNum
+	if rt.resimulating {
+		present = rt.present
+	}
+	if tick > present {
+		return fmt.Errorf("realtime: cannot insert event at future tick %d (present is %d)", tick, present)
+	}
+	if tick == present {
+		rt.queueEvent(event, 0)
+		return nil
+	}
+
+	snap := rt.snapshotAt(tick)
+	if snap == nil {
+		return fmt.Errorf("%w: tick %d", ErrTickNotRetained, tick)
+	}
+	snap.external = append(snap.external, EventWithMeta{Event: event})
+	return nil
+}
+
+// Resimulate re-runs the ticks between a Rollback and the present, leaving the
+// runtime at the tick it was at before the first Rollback. It does nothing if
+// the runtime is not rolled back.
+func (rt *RealtimeRuntime) Resimulate() error {
+	if !rt.manual {
+		return ErrNotManual
+	}
+	for {
+		rt.batchMu.Lock()
+		resimulating := rt.resimulating
+		rt.batchMu.Unlock()
+		if !resimulating {
+			return nil
+		}
+		if _, err := rt.Step(); err != nil {
+			return err
+		}
+	}
+}
+
+// saveSnapshot records the state at the start of the current tick (called
+// from processTick with the tick's collected batch)
+func (rt *RealtimeRuntime) saveSnapshot(events []EventWithMeta) {
+	if len(rt.snapshots) == 0 {
+		return
+	}
+
+	snap := tickSnapshot{valid: true, current: rt.Runtime.GetCurrentState()}
+	snap.shallow, snap.deep = rt.Runtime.History()
+
+	rt.regionMu.RLock()
+	snap.regions = copyRegions(rt.parallelRegionStates)
+	rt.regionMu.RUnlock()
+
+	rt.internalQueueMu.Lock()
+	snap.internal = append([]statechartx.Event(nil), rt.internalEventQueue...)
+	rt.internalQueueMu.Unlock()
+
+	for _, e := range events {
+		if e.raised {
+			snap.raised = append(snap.raised, e)
+		} else {
+			snap.external = append(snap.external, e)
+		}
+	}
+	sort.SliceStable(snap.external, func(i, j int) bool {
+		return snap.external[i].SequenceNum < snap.external[j].SequenceNum
+	})
+
+	if rt.cloner != nil {
+		snap.extended = rt.cloner.Snapshot()
+	}
+
+	rt.batchMu.Lock()
+	defer rt.batchMu.Unlock()
+	snap.tick = rt.tickNum
+	snap.sequenceNum = rt.sequenceNum
+	snap.scheduled = append([]EventWithMeta(nil), rt.scheduled...)
+	snap.timerGen = make(map[statechartx.StateID]uint64, len(rt.timerGen))
+	for id, gen := range rt.timerGen {
+		snap.timerGen[id] = gen
+	}
+	if len(snap.external) > 0 {
+		snap.sequenceNum = snap.external[0].SequenceNum
+	}
+	rt.snapshots[snap.tick%uint64(len(rt.snapshots))] = snap
+}
+
+// snapshotAt returns the retained snapshot of tick, or nil. The caller holds batchMu.
+func (rt *RealtimeRuntime) snapshotAt(tick uint64) *tickSnapshot {
+	if len(rt.snapshots) == 0 {
+		return nil
+	}
+	snap := &rt.snapshots[tick%uint64(len(rt.snapshots))]
+	if !snap.valid || snap.tick != tick {
+		return nil
+	}
+	return snap
+}
+
+// replayExternalEvents batches the recorded outside events of the tick being
+// re-simulated, numbered as they were originally. The caller holds batchMu.
+func (rt *RealtimeRuntime) replayExternalEvents() {
+	snap := rt.snapshotAt(rt.tickNum)
+	if snap == nil {
+		return
+	}
+	for _, e := range snap.external {
+		e.SequenceNum = rt.sequenceNum
+		rt.sequenceNum++
+		rt.eventBatch = append(rt.eventBatch, e)
+	}
+}
+
+// finishResimulation batches the events kept for the present once
+// re-simulation reaches it. The caller holds batchMu.
+func (rt *RealtimeRuntime) finishResimulation() {
+	rt.resimulating = false
+	for _, e := range rt.pending {
+		e.SequenceNum = rt.sequenceNum
+		rt.sequenceNum++
+		rt.eventBatch = append(rt.eventBatch, e)
+	}
+	rt.pending = nil
+}
+
+// copyRegions deep-copies parallel region state
+func copyRegions(src map[statechartx.StateID]map[statechartx.StateID]*realtimeRegion) map[statechartx.StateID]map[statechartx.StateID]*realtimeRegion {
+	dst := make(map[statechartx.StateID]map[statechartx.StateID]*realtimeRegion, len(src))
+	for parallelID, regions := range src {
+		copied := make(map[statechartx.StateID]*realtimeRegion, len(regions))
+		for regionID, region := range regions {
+			copied[regionID] = &realtimeRegion{
+				regionID:     region.regionID,
+				currentState: region.currentState,
+				eventQueue:   append([]statechartx.Event(nil), region.eventQueue...),
+			}
+		}
+		dst[parallelID] = copied
+	}
+	return dst
+}
@@ -3,6 +3,7 @@ package realtime
 import (
 	"context"
 	"errors"
+	"io"
 	"sync"
 	"time"
 
@@ -24,8 +25,11 @@ type RealtimeRuntime struct {
 
 	// Tick-specific fields
 	tickRate time.Duration // e.g., 16.67ms for 60 FPS
+	manual   bool          // ticks are driven by Step instead of the ticker
 	ticker   *time.Ticker
 	tickNum  uint64
+	ticking  bool          // a tick is running or owed since Start (guarded by batchMu)
+	tickDone chan struct{} // closed at the end of a tick, for WaitIdle (guarded by batchMu)
 
 	// Event batching (replaces async channel)
 	eventBatch  []EventWithMeta
@@ -45,9 +49,62 @@ type RealtimeRuntime struct {
 	inMacrostep        bool // Flag indicating we're in macrostep processing
 
 	// Control
+	configErr  error           // from Config.Validate, returned by Start
+	actionCtx  context.Context // passed to actions and guards, carries the runtime (see hooks.go)
 	tickCtx    context.Context
 	tickCancel context.CancelFunc
 	stopped    chan struct{}
+	stopOnce   sync.Once // closes stopped in manual mode
+
+	// Tick hashing (see hash.go)
+	hashExtended func(w io.Writer)
+	tickRecords  []TickRecord // ring buffer indexed by tick % len
+	recordsMu    sync.RWMutex
+
+	// Rollback (see rollback.go)
+	cloner       Cloner
+	snapshots    []tickSnapshot  // ring buffer indexed by tick % len
+	inTick       bool            // events sent now are raised by the tick (guarded by batchMu)
+	resimulating bool            // ticks before present are being re-run (guarded by batchMu)
+	present      uint64          // tick number to re-simulate up to (guarded by batchMu)
+	pending      []EventWithMeta // events sent for the present while re-simulating (guarded by batchMu)
+
+	recorder *Recorder // attached replay recorder (guarded by batchMu, see replay.go)
+
+	// Scheduled events and AfterTicks transitions (see schedule.go)
+	scheduled []EventWithMeta                  // events due at later ticks (guarded by batchMu)
+	timers    map[statechartx.StateID][]uint64 // AfterTicks counts by state
+	timerGen  map[statechartx.StateID]uint64   // entries per timed state (guarded by batchMu)
+
+	// Tick timing (see timing.go and pause.go)
+	epoch       time.Time     // start of tick slot 0 (guarded by batchMu)
+	period      time.Duration // wall-clock time per tick, TickRate / timeScale (guarded by batchMu)
+	timeScale   float64       // guarded by batchMu
+	clockGen    uint64        // incremented when epoch is rebased (guarded by batchMu)
+	paused      bool          // guarded by batchMu
+	whilePaused PausePolicy
+	catchUp     CatchUpPolicy
+	maxCatchUp  int
+	onOverrun   func(tick uint64, took time.Duration)
+	metrics     TickMetrics
+	metricsMu   sync.Mutex
+
+	// Coalescing and filters (see coalesce.go)
+	coalesce      map[statechartx.EventID]CoalesceRule
+	coalesceIndex map[coalesceKey]int // batch index of coalescable events (guarded by batchMu)
+	filters       []EventFilter
+	eventMetrics  EventMetrics // guarded by batchMu
+
+	// Tick hooks (see hooks.go)
+	tickInfo          TickInfo // tick being processed (guarded by batchMu)
+	ranTick           bool     // a tick has run since NewRuntime (guarded by batchMu)
+	onTickStart       func(tick uint64)
+	onEventsCollected func(tick uint64, events []EventWithMeta)
+	onTickEnd         func(tick uint64, report TickReport)
+
+	// Tick reports (see step.go), tick goroutine only
+	reporting bool                      // reportTick is collecting transitions
+	taken     []*statechartx.Transition // transitions taken in the reported tick
 }
 
 // realtimeRegion represents a single region in a parallel state (sequential processing)
@@ -61,9 +118,69 @@ type realtimeRegion struct {
 type Config struct {
 	TickRate         time.Duration // Fixed tick rate (e.g., 16.67ms for 60 FPS)
 	MaxEventsPerTick int           // Event queue capacity (default: 1000)
+
+	// Manual disables the ticker: Start only enters the initial state and the
+	// caller advances time with Step or StepN (see step.go).
+	Manual bool
+
+	// HashHistory is how many recent ticks keep a TickRecord with the state
+	// hash for TickHash and TickRecords (see hash.go). Zero disables hashing.
+	HashHistory int
+	// HashExtendedState writes the user's extended state into the tick hash.
+	// It runs on the tick goroutine at the end of every hashed tick and must
+	// write the same bytes on every peer for equal state.
+	HashExtendedState func(w io.Writer)
+
+	// SnapshotHistory is how many recent ticks keep a snapshot for Rollback
+	// (see rollback.go). Zero disables rollback.
+	SnapshotHistory int
+	// Cloner captures and restores the user's extended state in snapshots.
+	Cloner Cloner
+
+	// CatchUp is what the tick loop does when it falls behind (see timing.go).
+	CatchUp CatchUpPolicy
+	// MaxCatchUpTicks bounds the extra ticks CatchUpBurst runs per wake-up
+	// (default DefaultMaxCatchUpTicks).
+	MaxCatchUpTicks int
+	// OnOverrun is called on the tick goroutine after a tick whose processing
+	// took longer than TickRate (divided by the time scale, see SetTimeScale).
+	OnOverrun func(tick uint64, took time.Duration)
+
+	// Coalesce combines repeated events of an ID batched for the same tick
+	// instead of queueing each one (see coalesce.go).
+	Coalesce map[statechartx.EventID]CoalesceRule
+	// EventFilters drop events from a tick before they are processed. Dropped
+	// events are still recorded in replays and snapshots.
+	EventFilters []EventFilter
+
+	// WhilePaused is what happens to events sent while paused (see pause.go).
+	WhilePaused PausePolicy
+
+	// OnTickStart is called on the tick goroutine at the start of every tick,
+	// before the state machine runs. Events it sends are processed in the same
+	// tick, like events raised by actions, and are not recorded in replays.
+	OnTickStart func(tick uint64)
+	// OnEventsCollected is called with the tick's events in processing order,
+	// before the first is processed. It must not modify or keep the slice.
+	OnEventsCollected func(tick uint64, events []EventWithMeta)
+	// OnTickEnd is called with the tick's report after the state machine ran.
+	// Events it sends are processed at the next tick, like events raised by
+	// actions.
+	OnTickEnd func(tick uint64, report TickReport)
+}
+
+// Validate reports the first setting of cfg that NewRuntime cannot use.
+func (cfg Config) Validate() error {
+	for id, rule := range cfg.Coalesce {
+		if err := rule.validate(id); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
-// NewRuntime creates a new tick-based runtime by embedding the event-driven runtime
+// NewRuntime creates a new tick-based runtime by embedding the event-driven runtime.
+// If cfg is invalid (see Config.Validate), Start returns the error.
 func NewRuntime(machine *statechartx.Machine, cfg Config) *RealtimeRuntime {
 	if cfg.MaxEventsPerTick == 0 {
 		cfg.MaxEventsPerTick = 1000
@@ -71,11 +188,34 @@ func NewRuntime(machine *statechartx.Machine, cfg Config) *RealtimeRuntime {
 	if cfg.TickRate == 0 {
 		cfg.TickRate = 16667 * time.Microsecond // Default 60 FPS
 	}
+	if cfg.MaxCatchUpTicks == 0 {
+		cfg.MaxCatchUpTicks = DefaultMaxCatchUpTicks
+	}
 
 	rt := &RealtimeRuntime{
 		// Embed existing runtime (THIS IS THE KEY - REUSE EVERYTHING)
 		Runtime:              statechartx.NewRuntime(machine, nil),
+		configErr:            cfg.Validate(),
 		tickRate:             cfg.TickRate,
+		period:               cfg.TickRate,
+		timeScale:            1,
+		whilePaused:          cfg.WhilePaused,
+		manual:               cfg.Manual,
+		hashExtended:         cfg.HashExtendedState,
+		tickRecords:          make([]TickRecord, cfg.HashHistory),
+		cloner:               cfg.Cloner,
+		snapshots:            make([]tickSnapshot, cfg.SnapshotHistory),
+		catchUp:              cfg.CatchUp,
+		maxCatchUp:           cfg.MaxCatchUpTicks,
+		onOverrun:            cfg.OnOverrun,
+		coalesce:             cfg.Coalesce,
+		coalesceIndex:        make(map[coalesceKey]int),
+		filters:              cfg.EventFilters,
+		onTickStart:          cfg.OnTickStart,
+		onEventsCollected:    cfg.OnEventsCollected,
+		onTickEnd:            cfg.OnTickEnd,
+		timers:               findTimers(machine),
+		timerGen:             make(map[statechartx.StateID]uint64),
 		eventBatch:           make([]EventWithMeta, 0, cfg.MaxEventsPerTick),
 		stopped:              make(chan struct{}),
 		parallelRegionStates: make(map[statechartx.StateID]map[statechartx.StateID]*realtimeRegion),
@@ -84,6 +224,11 @@ func NewRuntime(machine *statechartx.Machine, cfg Config) *RealtimeRuntime {
 	// Register parallel state hooks for sequential processing
 	rt.Runtime.ParallelHooks = rt.createParallelHooks()
 
+	// Events addressed to this session via Send are batched like SendEvent
+	rt.Runtime.SetEventSink(func(ctx context.Context, event statechartx.Event) error {
+		return rt.SendEvent(event)
+	})
+
 	return rt
 }
 
@@ -99,6 +244,9 @@ func (rt *RealtimeRuntime) Stop() error {
 	}
 
 	// Wait for tick loop to exit
+	if rt.manual {
+		rt.stopOnce.Do(func() { close(rt.stopped) })
+	}
 	<-rt.stopped
 
 	// Stop embedded runtime
@@ -116,30 +264,77 @@ func (rt *RealtimeRuntime) tickLoop() {
 		}
 	}()
 
+	// Fixed-timestep accumulator: tick slot n starts at epoch + n*period.
+	// The ticker only wakes the loop; the number of slots due is derived from
+	// the clock, so ticks the ticker dropped are seen by the catch-up policy.
+	// Pause, Resume and SetTimeScale rebase the epoch (see pause.go).
+	var slots int64
+	var gen uint64
 	for {
 		select {
 		case <-rt.tickCtx.Done():
 			return
 		case <-rt.ticker.C:
-			// Process tick with panic recovery
-			func() {
-				defer func() {
-					if r := recover(); r != nil {
-						// Recover from panic in tick processing
-						// In production, this should be logged
-						_ = r // TODO: Add proper logging
-					}
-				}()
-				rt.processTick()
-			}()
-
 			rt.batchMu.Lock()
-			rt.tickNum++
+			paused, epoch, period := rt.paused, rt.epoch, rt.period
+			if rt.clockGen != gen {
+				gen, slots = rt.clockGen, 0
+			}
 			rt.batchMu.Unlock()
+			if paused {
+				continue
+			}
+
+			due := int64(time.Since(epoch)/period) - slots
+			if due <= 0 {
+				continue
+			}
+			run := rt.ticksToRun(due)
+			ran := int64(0)
+			for ; ran < run; ran++ {
+				if rt.tickCtx.Err() != nil {
+					return
+				}
+				if ran > 0 && rt.Paused() {
+					break
+				}
+				rt.runTick()
+			}
+			slots += due
+			if ran > 1 || due > ran {
+				rt.fallBehind(ran, due-ran)
+			}
 		}
 	}
 }
 
+// runTick runs one tick on the tick loop goroutine
+func (rt *RealtimeRuntime) runTick() {
+	rt.batchMu.Lock()
+	rt.ticking = true
+	rt.batchMu.Unlock()
+
+	// Process tick with panic recovery
+	func() {
+		defer func() {
+			if r := recover(); r != nil {
+				// Recover from panic in tick processing
+				// In production, this should be logged
+				_ = r // TODO: Add proper logging
+			}
+		}()
+		if rt.onTickEnd == nil {
+			rt.processTick()
+			return
+		}
+		rt.endTickHooks(rt.reportTick())
+	}()
+
+	rt.batchMu.Lock()
+	rt.endTick()
+	rt.batchMu.Unlock()
+}
+
 // SendEvent is implemented in parallel.go to handle both normal and parallel state routing
 
 // SendEventWithPriority queues an event with priority
@@ -147,18 +342,42 @@ func (rt *RealtimeRuntime) SendEventWithPriority(event statechartx.Event, priori
 	rt.batchMu.Lock()
 	defer rt.batchMu.Unlock()
 
+	if rt.rejectPaused() {
+		return ErrPaused
+	}
+	if rt.coalesceEvent(event, priority, 0) {
+		return nil
+	}
 	if len(rt.eventBatch) >= cap(rt.eventBatch) {
+		rt.eventMetrics.QueueFull++
 		return errors.New("event queue full")
 	}
 
+	rt.queueEvent(event, priority)
+	return nil
+}
+
+// queueEvent batches an event for the next tick. The caller holds batchMu.
+// Events sent from outside while re-simulating wait for the present tick.
+func (rt *RealtimeRuntime) queueEvent(event statechartx.Event, priority int) {
+	rt.queueEventAt(event, priority, 0)
+}
+
+// queueEventAt batches an event due at tick at (see schedule.go). The caller holds batchMu.
+func (rt *RealtimeRuntime) queueEventAt(event statechartx.Event, priority int, at uint64) {
+	if rt.resimulating && !rt.inTick {
+		rt.pending = append(rt.pending, EventWithMeta{Event: event, Priority: priority, at: at})
+		return
+	}
+
 	rt.eventBatch = append(rt.eventBatch, EventWithMeta{
 		Event:       event,
 		SequenceNum: rt.sequenceNum,
 		Priority:    priority,
+		raised:      rt.inTick,
+		at:          at,
 	})
 	rt.sequenceNum++
-
-	return nil
 }
 
 // GetTickNumber returns the current tick count
@@ -172,3 +391,25 @@ func (rt *RealtimeRuntime) GetTickNumber() uint64 {
 func (rt *RealtimeRuntime) GetCurrentState() statechartx.StateID {
 	return rt.Runtime.GetCurrentState()
 }
+
+// IsInState reports whether stateID is active, including states inside the
+// sequentially processed parallel regions.
+func (rt *RealtimeRuntime) IsInState(stateID statechartx.StateID) bool {
+	if rt.Runtime.IsInState(stateID) {
+		return true
+	}
+
+	rt.regionMu.RLock()
+	defer rt.regionMu.RUnlock()
+	machine := rt.Runtime.GetMachine()
+	for _, regions := range rt.parallelRegionStates {
+		for _, region := range regions {
+			for s := machine.GetState(region.currentState); s != nil; s = s.Parent {
+				if s.ID == stateID {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
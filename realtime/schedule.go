@@ -0,0 +1,165 @@
+package realtime
+
+import (
+	"fmt"
+
+	"github.com/comalice/statechartx"
+)
+
+// MaxAfterTicks is the largest tick count AfterTicks accepts.
+const MaxAfterTicks = statechartx.MaxTimerCount
+
+// AfterTicksEvent returns the event ID of the AfterTicks transitions of state
+// that wait n ticks: the timer event statechartx.TimerEventID(state, n), which
+// wildcard transitions never match and which is named "after.<state>.<n>".
+func AfterTicksEvent(state statechartx.StateID, n uint64) (statechartx.EventID, error) {
+	return statechartx.TimerEventID(state, n)
+}
+
+// AfterTicks adds a transition from state to target that is taken once state
+// has been active for n ticks, and returns it so a guard or actions can be set.
+// The countdown starts when state is entered and is cancelled when it exits;
+// re-entering restarts it. A state entered during tick k (or by Start, as tick
+// 0) takes the transition during tick k+n.
+//
+// Add AfterTicks transitions before creating runtimes for the machine. They
+// only run on a RealtimeRuntime; the event-driven runtime never raises their
+// events. It returns statechartx.ErrTimerEventRange if n is not between 1 and
+// MaxAfterTicks, if the state ID is above statechartx.MaxTimerState, or on
+// platforms where event IDs are narrower than 64 bits.
+func AfterTicks(state *statechartx.State, n uint64, target statechartx.StateID) (*statechartx.Transition, error) {
+	event, err := AfterTicksEvent(state.ID, n)
+	if err != nil {
+		return nil, fmt.Errorf("realtime: AfterTicks: %w", err)
+	}
+	t := &statechartx.Transition{
+		Event:  event,
+		Source: state,
+		Target: target,
+	}
+	state.Transitions = append(state.Transitions, t)
+	return t, nil
+}
+
+// SendEventAtTick queues event for the tick numbered tick. A tick equal to the
+// current one means the next tick to run (the following tick when called from
+// an action); earlier ticks are an error. Scheduled events are processed before
+// the events sent for their tick, in the order they were scheduled, and are
+// part of snapshots, state hashes and replays.
+func (rt *RealtimeRuntime) SendEventAtTick(event statechartx.Event, tick uint64) error {
+	return rt.scheduleEvent(event, 0, tick)
+}
+
+// SendEventAfterTicks queues event for the tick n ticks after the current one
+// (see SendEventAtTick); n = 0 is equivalent to SendEvent.
+func (rt *RealtimeRuntime) SendEventAfterTicks(event statechartx.Event, n uint64) error {
+	rt.batchMu.Lock()
+	tick := rt.tickNum + n
+	rt.batchMu.Unlock()
+	return rt.scheduleEvent(event, 0, tick)
+}
+
+// scheduleEvent batches event with the tick it is due at. The next tick moves
+// it to the scheduled queue (see dispatchScheduled).
+func (rt *RealtimeRuntime) scheduleEvent(event statechartx.Event, priority int, tick uint64) error {
+	rt.batchMu.Lock()
+	defer rt.batchMu.Unlock()
+
+	if rt.rejectPaused() {
+		return ErrPaused
+	}
+	if tick < rt.tickNum {
+		return fmt.Errorf("realtime: cannot schedule event %d at tick %d, tick %d has started", event.ID, tick, rt.tickNum)
+	}
+	if rt.coalesceEvent(event, priority, tick) {
+		return nil
+	}
+	if len(rt.eventBatch) >= cap(rt.eventBatch) {
+		rt.eventMetrics.QueueFull++
+		return statechartx.ErrEventQueueFull
+	}
+	rt.queueEventAt(event, priority, tick)
+	return nil
+}
+
+// dispatchScheduled moves events due at later ticks from the tick's batch to
+// the scheduled queue and returns the remaining events plus the scheduled ones
+// now due. It drops the AfterTicks countdowns whose state has exited, due or not.
+func (rt *RealtimeRuntime) dispatchScheduled(events []EventWithMeta) []EventWithMeta {
+	// IsInState takes regionMu, so query it before taking batchMu. Only the
+	// tick goroutine changes the configuration, so it cannot change meanwhile.
+	active := make(map[statechartx.StateID]bool, len(rt.timers))
+	for state := range rt.timers {
+		active[state] = rt.IsInState(state)
+	}
+
+	rt.batchMu.Lock()
+	defer rt.batchMu.Unlock()
+	tick := rt.tickNum
+	due := make([]EventWithMeta, 0, len(events))
+	for _, e := range events {
+		if e.at > tick {
+			rt.scheduled = append(rt.scheduled, e)
+		} else {
+			due = append(due, e)
+		}
+	}
+
+	keep := rt.scheduled[:0]
+	for _, e := range rt.scheduled {
+		switch {
+		case e.timer && (!active[e.timerState] || rt.timerGen[e.timerState] != e.timerGen):
+			// Cancelled: the state exited, and may have re-entered since
+		case e.at > tick:
+			keep = append(keep, e)
+		default:
+			e.raised = true
+			due = append(due, e)
+		}
+	}
+	for i := len(keep); i < len(rt.scheduled); i++ {
+		rt.scheduled[i] = EventWithMeta{} // release dropped events' data
+	}
+	rt.scheduled = keep
+	return due
+}
+
+// startTimers schedules the AfterTicks events of a state that is being entered
+func (rt *RealtimeRuntime) startTimers(state statechartx.StateID) {
+	counts := rt.timers[state]
+	if len(counts) == 0 {
+		return
+	}
+
+	rt.batchMu.Lock()
+	defer rt.batchMu.Unlock()
+	rt.timerGen[state]++
+	for _, n := range counts {
+		event, _ := AfterTicksEvent(state, n) // valid: findTimers decoded it
+		rt.scheduled = append(rt.scheduled, EventWithMeta{
+			Event:       statechartx.Event{ID: event},
+			SequenceNum: rt.sequenceNum,
+			at:          rt.tickNum + n,
+			timer:       true,
+			timerState:  state,
+			timerGen:    rt.timerGen[state],
+		})
+		rt.sequenceNum++
+	}
+}
+
+// findTimers collects the tick counts of the machine's AfterTicks transitions by state
+func findTimers(machine *statechartx.Machine) map[statechartx.StateID][]uint64 {
+	timers := make(map[statechartx.StateID][]uint64)
+	for _, id := range machine.StateIDs() {
+		seen := make(map[uint64]bool)
+		for _, t := range machine.GetState(id).Transitions {
+			state, n, ok := statechartx.TimerEventState(t.Event)
+			if ok && state == id && !seen[n] {
+				seen[n] = true
+				timers[id] = append(timers[id], n)
+			}
+		}
+	}
+	return timers
+}
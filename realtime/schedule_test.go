@@ -0,0 +1,225 @@
+package realtime
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/comalice/statechartx"
+)
+
+// TestSendEventAtTick tests that scheduled events are processed exactly at
+// their tick, before the events sent for it
+func TestSendEventAtTick(t *testing.T) {
+	b := statechartx.NewMachineBuilder("app", "a")
+	b.State("a").On("first", "b", nil, nil).On("second", "c", nil, nil)
+	b.State("b").On("second", "c", nil, nil)
+	b.State("c").Atomic()
+	machine, err := b.Build()
+	if err != nil {
+		t.Fatalf("Failed to create machine: %v", err)
+	}
+
+	rt := startManual(t, machine)
+	defer rt.Stop()
+
+	if err := rt.SendEventAtTick(statechartx.Event{ID: b.EventID("first")}, 3); err != nil {
+		t.Fatalf("SendEventAtTick failed: %v", err)
+	}
+	reports := mustStepN(t, rt, 3)
+	for _, r := range reports {
+		if len(r.Events) != 0 {
+			t.Errorf("expected no events at tick %d, got %v", r.Tick, r.Events)
+		}
+	}
+
+	rt.SendEvent(statechartx.Event{ID: b.EventID("second")})
+	report := mustStep(t, rt)
+	if report.Tick != 3 || len(report.Events) != 2 || report.Events[0].ID != b.EventID("first") {
+		t.Fatalf("expected first then second at tick 3, got tick %d events %v", report.Tick, report.Events)
+	}
+	if !rt.IsInState(b.GetID("c")) {
+		t.Errorf("expected c, got %s", b.GetName(rt.GetCurrentState()))
+	}
+
+	if err := rt.SendEventAtTick(statechartx.Event{ID: 1}, 2); err == nil {
+		t.Error("expected an error scheduling at a past tick")
+	}
+	if err := rt.SendEventAfterTicks(statechartx.Event{ID: b.EventID("first")}, 2); err != nil {
+		t.Fatalf("SendEventAfterTicks failed: %v", err)
+	}
+	rt.StepN(2)
+	if report := mustStep(t, rt); report.Tick != 6 || len(report.Events) != 1 {
+		t.Errorf("expected the event at tick 6, got tick %d events %v", report.Tick, report.Events)
+	}
+}
+
+// TestAfterTicks tests that an AfterTicks transition fires n ticks after
+// entry, and that exiting cancels the countdown
+func TestAfterTicks(t *testing.T) {
+	b := statechartx.NewMachineBuilder("app", "cooldown")
+	b.State("cooldown").On("hit", "stunned", nil, nil)
+	b.State("stunned").On("recover", "cooldown", nil, nil)
+	b.State("ready").Atomic()
+	machine, err := b.Build()
+	if err != nil {
+		t.Fatalf("Failed to create machine: %v", err)
+	}
+	mustAfterTicks(t, machine.GetState(b.GetID("cooldown")), 4, b.GetID("ready"))
+
+	rt := startManual(t, machine)
+	defer rt.Stop()
+
+	// Entered by Start: ready at tick 4
+	rt.StepN(4)
+	if !rt.IsInState(b.GetID("cooldown")) {
+		t.Fatalf("expected cooldown before tick 4, got %s", b.GetName(rt.GetCurrentState()))
+	}
+	rt.Step()
+	if !rt.IsInState(b.GetID("ready")) {
+		t.Fatalf("expected ready at tick 4, got %s", b.GetName(rt.GetCurrentState()))
+	}
+
+	// Leaving and re-entering restarts the countdown
+	rt2 := startManual(t, machine)
+	defer rt2.Stop()
+	rt2.StepN(2)
+	rt2.SendEvent(statechartx.Event{ID: b.EventID("hit")})
+	rt2.Step() // tick 2: stunned
+	rt2.SendEvent(statechartx.Event{ID: b.EventID("recover")})
+	rt2.Step() // tick 3: cooldown again, ready at tick 7
+	rt2.StepN(3)
+	if !rt2.IsInState(b.GetID("cooldown")) {
+		t.Fatalf("expected the first countdown to be cancelled, got %s", b.GetName(rt2.GetCurrentState()))
+	}
+	report := mustStep(t, rt2)
+	if report.Tick != 7 || !rt2.IsInState(b.GetID("ready")) {
+		t.Errorf("expected ready at tick 7, got %s at tick %d", b.GetName(rt2.GetCurrentState()), report.Tick)
+	}
+}
+
+// TestAfterTicksErrors tests that out-of-range counts and state IDs are
+// rejected, and that countdowns of exited states leave the scheduled queue
+func TestAfterTicksErrors(t *testing.T) {
+	b := statechartx.NewMachineBuilder("app", "idle")
+	b.State("idle").On("go", "away", nil, nil)
+	b.State("away").Atomic()
+	machine, err := b.Build()
+	if err != nil {
+		t.Fatalf("Failed to create machine: %v", err)
+	}
+	idle := machine.GetState(b.GetID("idle"))
+	for _, n := range []uint64{0, MaxAfterTicks + 1} {
+		if _, err := AfterTicks(idle, n, b.GetID("away")); !errors.Is(err, statechartx.ErrTimerEventRange) {
+			t.Errorf("AfterTicks(%d): expected ErrTimerEventRange, got %v", n, err)
+		}
+	}
+	huge := &statechartx.State{ID: statechartx.StateID(statechartx.MaxTimerState + 1)}
+	if _, err := AfterTicks(huge, 1, b.GetID("away")); !errors.Is(err, statechartx.ErrTimerEventRange) {
+		t.Errorf("expected ErrTimerEventRange for a state ID above MaxTimerState, got %v", err)
+	}
+	mustAfterTicks(t, idle, 1000, b.GetID("away"))
+
+	rt := startManual(t, machine)
+	defer rt.Stop()
+	rt.SendEvent(statechartx.Event{ID: b.EventID("go")})
+	mustStepN(t, rt, 2)
+	rt.batchMu.Lock()
+	defer rt.batchMu.Unlock()
+	if len(rt.scheduled) != 0 {
+		t.Errorf("expected the exited state's countdown to be dropped, got %d scheduled", len(rt.scheduled))
+	}
+}
+
+// mustAfterTicks adds an AfterTicks transition, failing the test on error
+func mustAfterTicks(t *testing.T, state *statechartx.State, n uint64, target statechartx.StateID) *statechartx.Transition {
+	t.Helper()
+	tr, err := AfterTicks(state, n, target)
+	if err != nil {
+		t.Fatalf("AfterTicks failed: %v", err)
+	}
+	return tr
+}
+
+// TestAfterTicksIgnoresWildcard tests that a descendant's wildcard transition
+// does not take an ancestor's AfterTicks event, and that countdowns keep
+// working when Coverage is replaced after Start
+func TestAfterTicksIgnoresWildcard(t *testing.T) {
+	b := statechartx.NewMachineBuilder("app", "play")
+	b.State("play").Compound("play.idle")
+	b.State("play.idle").On("*", "caught", nil, nil)
+	b.State("caught").Atomic()
+	b.State("done").Atomic()
+	machine, err := b.Build()
+	if err != nil {
+		t.Fatalf("Failed to create machine: %v", err)
+	}
+	mustAfterTicks(t, machine.GetState(b.GetID("play")), 2, b.GetID("done"))
+
+	rt := startManual(t, machine)
+	defer rt.Stop()
+	rt.Coverage = &countingRecorder{}
+
+	rt.StepN(3)
+	if !rt.IsInState(b.GetID("done")) {
+		t.Errorf("expected done after 2 ticks, got %s", b.GetName(rt.GetCurrentState()))
+	}
+}
+
+// TestScheduledEventsReplayAndRollback tests that scheduled events survive
+// replay files and rollback
+func TestScheduledEventsReplayAndRollback(t *testing.T) {
+	b := statechartx.NewMachineBuilder("app", "idle")
+	b.State("idle").On("charge", "charging", nil, nil)
+	b.State("charging").On("fire", "idle", nil, nil)
+	machine, err := b.Build()
+	if err != nil {
+		t.Fatalf("Failed to create machine: %v", err)
+	}
+	mustAfterTicks(t, machine.GetState(b.GetID("idle")), 5, b.GetID("charging"))
+
+	rt := NewRuntime(machine, Config{Manual: true, HashHistory: 64, SnapshotHistory: 64})
+	var file bytes.Buffer
+	rec, err := rt.Record(&file, RecorderOptions{})
+	if err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+	if err := rt.Start(context.Background()); err != nil {
+		t.Fatalf("Failed to start runtime: %v", err)
+	}
+	defer rt.Stop()
+
+	rt.SendEventAfterTicks(statechartx.Event{ID: b.EventID("charge")}, 2)
+	rt.Step()
+	rt.SendEventAtTick(statechartx.Event{ID: b.EventID("fire")}, 4)
+	rt.StepN(11)
+	if err := rec.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	player, err := NewPlayer(&file, nil)
+	if err != nil {
+		t.Fatalf("NewPlayer failed: %v", err)
+	}
+	replay := startManual(t, machine)
+	defer replay.Stop()
+	if err := player.Play(replay); err != nil {
+		t.Fatalf("Play failed: %v", err)
+	}
+	if d := FindDivergence(rt.TickRecords(), replay.TickRecords()); d != nil {
+		t.Errorf("expected identical replay, got %s", d)
+	}
+
+	// Roll back before the scheduled events fired and re-simulate
+	want := rt.TickRecords()
+	if err := rt.Rollback(1); err != nil {
+		t.Fatalf("Rollback failed: %v", err)
+	}
+	if err := rt.Resimulate(); err != nil {
+		t.Fatalf("Resimulate failed: %v", err)
+	}
+	if d := FindDivergence(want, rt.TickRecords()); d != nil {
+		t.Errorf("expected identical re-simulation, got %s", d)
+	}
+}
@@ -0,0 +1,21 @@
+package realtime_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/comalice/statechartx"
+	"github.com/comalice/statechartx/conformance"
+	"github.com/comalice/statechartx/testutil"
+)
+
+func TestSCXMLConformance(t *testing.T) {
+	conformance.Run(t, func(m *statechartx.Machine) testutil.RuntimeAdapter {
+		return testutil.NewTickBasedAdapter(m, 5*time.Millisecond)
+	}, conformance.Options{
+		KnownFailures: map[string]string{
+			"405": "each region's eventless transition runs exit, action and entry atomically instead of all exits first",
+			"419": "events raised while entering the initial configuration are processed before eventless transitions",
+		},
+	})
+}
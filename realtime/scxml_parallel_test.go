@@ -1,446 +0,0 @@
-package realtime
-
-import (
-	"context"
-	"testing"
-	"time"
-
-	sc "github.com/comalice/statechartx"
-)
-
-// TestSCXML404_Realtime tests parallel state exit order using realtime runtime
-// SCXML test 404: states should exit in reverse document order (children before parents)
-func TestSCXML404_Realtime(t *testing.T) {
-	// Test that states in parallel regions are exited in correct order:
-	// Children before parents, reverse document order for siblings
-	// Expected: event1 (s01p2 exit) -> event2 (s01p1 exit) -> event3 (s01p exit) -> event4 (transition action)
-	// NOTE: Uses realtime runtime for deterministic event ordering
-	const (
-		STATE_ROOT  sc.StateID = 1
-		STATE_S0    sc.StateID = 2
-		STATE_S01P  sc.StateID = 3
-		STATE_S01P1 sc.StateID = 4
-		STATE_S01P2 sc.StateID = 5
-		STATE_S02   sc.StateID = 6
-		STATE_S03   sc.StateID = 7
-		STATE_S04   sc.StateID = 8
-		STATE_S05   sc.StateID = 9
-		STATE_PASS  sc.StateID = 10
-		STATE_FAIL  sc.StateID = 11
-
-		EVENT_EVENT1 sc.EventID = 1
-		EVENT_EVENT2 sc.EventID = 2
-		EVENT_EVENT3 sc.EventID = 3
-		EVENT_EVENT4 sc.EventID = 4
-	)
-
-	root := &sc.State{ID: STATE_ROOT}
-	s0 := &sc.State{ID: STATE_S0, Parent: root}
-	s01p := &sc.State{ID: STATE_S01P, Parent: s0, IsParallel: true}
-	s01p1 := &sc.State{ID: STATE_S01P1, Parent: s01p}
-	s01p2 := &sc.State{ID: STATE_S01P2, Parent: s01p}
-	s02 := &sc.State{ID: STATE_S02, Parent: s0}
-	s03 := &sc.State{ID: STATE_S03, Parent: s0}
-	s04 := &sc.State{ID: STATE_S04, Parent: s0}
-	s05 := &sc.State{ID: STATE_S05, Parent: s0}
-	pass := &sc.State{ID: STATE_PASS, IsFinal: true, Parent: root}
-	fail := &sc.State{ID: STATE_FAIL, IsFinal: true, Parent: root}
-
-	root.Children = map[sc.StateID]*sc.State{
-		STATE_S0:   s0,
-		STATE_PASS: pass,
-		STATE_FAIL: fail,
-	}
-
-	s0.Children = map[sc.StateID]*sc.State{
-		STATE_S01P: s01p,
-		STATE_S02:  s02,
-		STATE_S03:  s03,
-		STATE_S04:  s04,
-		STATE_S05:  s05,
-	}
-	s0.Initial = STATE_S01P
-
-	s01p.Children = map[sc.StateID]*sc.State{
-		STATE_S01P1: s01p1,
-		STATE_S01P2: s01p2,
-	}
-
-	// s02 expects event1 first
-	s02.Transitions = []*sc.Transition{
-		{Event: EVENT_EVENT1, Target: STATE_S03},
-		{Event: sc.NO_EVENT, Target: STATE_FAIL},
-	}
-
-	// s03 expects event2
-	s03.Transitions = []*sc.Transition{
-		{Event: EVENT_EVENT2, Target: STATE_S04},
-		{Event: sc.NO_EVENT, Target: STATE_FAIL},
-	}
-
-	// s04 expects event3
-	s04.Transitions = []*sc.Transition{
-		{Event: EVENT_EVENT3, Target: STATE_S05},
-		{Event: sc.NO_EVENT, Target: STATE_FAIL},
-	}
-
-	// s05 expects event4
-	s05.Transitions = []*sc.Transition{
-		{Event: EVENT_EVENT4, Target: STATE_PASS},
-		{Event: sc.NO_EVENT, Target: STATE_FAIL},
-	}
-
-	machine, err := sc.NewMachine(root)
-	if err != nil {
-		t.Fatalf("Failed to create machine: %v", err)
-	}
-
-	// Use realtime runtime for deterministic event ordering (60 ticks/sec)
-	var rt *RealtimeRuntime = NewRuntime(machine, Config{
-		TickRate:         16667 * time.Microsecond, // ~60 FPS
-		MaxEventsPerTick: 100,
-	})
-	ctx := context.Background()
-
-	// Set up actions after runtime is created so closures can capture it
-	// Exit order test: s01p2 should exit first (reverse document order)
-	s01p2.ExitAction = func(ctx context.Context, event *sc.Event, from, to sc.StateID) error {
-		_ = rt.SendEvent(sc.Event{ID: EVENT_EVENT1})
-		return nil
-	}
-
-	s01p1.ExitAction = func(ctx context.Context, event *sc.Event, from, to sc.StateID) error {
-		_ = rt.SendEvent(sc.Event{ID: EVENT_EVENT2})
-		return nil
-	}
-
-	s01p.ExitAction = func(ctx context.Context, event *sc.Event, from, to sc.StateID) error {
-		_ = rt.SendEvent(sc.Event{ID: EVENT_EVENT3})
-		return nil
-	}
-
-	// Transition from parallel state to s02, raising event4 in action
-	s01p.Transitions = []*sc.Transition{
-		{
-			Event:  sc.NO_EVENT,
-			Target: STATE_S02,
-			Action: func(ctx context.Context, event *sc.Event, from, to sc.StateID) error {
-				_ = rt.SendEvent(sc.Event{ID: EVENT_EVENT4})
-				return nil
-			},
-		},
-	}
-
-	if err := rt.Start(ctx); err != nil {
-		t.Fatalf("Failed to start runtime: %v", err)
-	}
-	defer rt.Stop()
-
-	// Wait for several ticks to process events
-	time.Sleep(200 * time.Millisecond)
-
-	currentState := rt.GetCurrentState()
-	if currentState != STATE_PASS {
-		t.Errorf("Exit order should be: event1 (s01p2), event2 (s01p1), event3 (s01p), event4 (transition). Current state: %v", currentState)
-	}
-}
-
-// TestSCXML405_Realtime tests parallel transition execution order using realtime runtime
-// SCXML test 405: transition actions execute in document order after exits
-//
-// SKIPPED: This test validates strict SCXML ordering when multiple parallel regions have
-// simultaneous eventless transitions. The spec requires collecting all transitions, then
-// processing exits in reverse document order, then actions in document order.
-// Our implementation processes each region's transition atomically (exit→action→enter) in
-// document order for simplicity. See docs/SCXML_COMPLIANCE.md for details and workarounds.
-func TestSCXML405_Realtime(t *testing.T) {
-	t.Skip("Skipped: parallel region eventless transition ordering differs from SCXML spec (see docs/SCXML_COMPLIANCE.md)")
-	// Test that executable content in transitions executes in document order
-	// after states are exited
-	// Expected: event1 (s01p21 exit) -> event2 (s01p11 exit) -> event3 (s01p11->s01p12 transition) -> event4 (s01p21->s01p22 transition)
-	const (
-		STATE_ROOT   sc.StateID = 1
-		STATE_S0     sc.StateID = 2
-		STATE_S01P   sc.StateID = 3
-		STATE_S01P1  sc.StateID = 4
-		STATE_S01P11 sc.StateID = 5
-		STATE_S01P12 sc.StateID = 6
-		STATE_S01P2  sc.StateID = 7
-		STATE_S01P21 sc.StateID = 8
-		STATE_S01P22 sc.StateID = 9
-		STATE_S02    sc.StateID = 10
-		STATE_S03    sc.StateID = 11
-		STATE_S04    sc.StateID = 12
-		STATE_PASS   sc.StateID = 13
-		STATE_FAIL   sc.StateID = 14
-
-		EVENT_EVENT1 sc.EventID = 1
-		EVENT_EVENT2 sc.EventID = 2
-		EVENT_EVENT3 sc.EventID = 3
-		EVENT_EVENT4 sc.EventID = 4
-	)
-
-	root := &sc.State{ID: STATE_ROOT}
-	s0 := &sc.State{ID: STATE_S0, Parent: root}
-	s01p := &sc.State{ID: STATE_S01P, Parent: s0, IsParallel: true}
-	s01p1 := &sc.State{ID: STATE_S01P1, Parent: s01p}
-	s01p11 := &sc.State{ID: STATE_S01P11, Parent: s01p1}
-	s01p12 := &sc.State{ID: STATE_S01P12, Parent: s01p1}
-	s01p2 := &sc.State{ID: STATE_S01P2, Parent: s01p}
-	s01p21 := &sc.State{ID: STATE_S01P21, Parent: s01p2}
-	s01p22 := &sc.State{ID: STATE_S01P22, Parent: s01p2}
-	s02 := &sc.State{ID: STATE_S02, Parent: s0}
-	s03 := &sc.State{ID: STATE_S03, Parent: s0}
-	s04 := &sc.State{ID: STATE_S04, Parent: s0}
-	pass := &sc.State{ID: STATE_PASS, IsFinal: true, Parent: root}
-	fail := &sc.State{ID: STATE_FAIL, IsFinal: true, Parent: root}
-
-	root.Children = map[sc.StateID]*sc.State{
-		STATE_S0:   s0,
-		STATE_PASS: pass,
-		STATE_FAIL: fail,
-	}
-
-	s0.Children = map[sc.StateID]*sc.State{
-		STATE_S01P: s01p,
-		STATE_S02:  s02,
-		STATE_S03:  s03,
-		STATE_S04:  s04,
-	}
-	s0.Initial = STATE_S01P
-
-	s01p.Children = map[sc.StateID]*sc.State{
-		STATE_S01P1: s01p1,
-		STATE_S01P2: s01p2,
-	}
-
-	s01p1.Children = map[sc.StateID]*sc.State{
-		STATE_S01P11: s01p11,
-		STATE_S01P12: s01p12,
-	}
-	s01p1.Initial = STATE_S01P11
-
-	s01p2.Children = map[sc.StateID]*sc.State{
-		STATE_S01P21: s01p21,
-		STATE_S01P22: s01p22,
-	}
-	s01p2.Initial = STATE_S01P21
-
-	// Verification chain
-	s02.Transitions = []*sc.Transition{
-		{Event: EVENT_EVENT2, Target: STATE_S03},
-		{Event: sc.NO_EVENT, Target: STATE_FAIL},
-	}
-
-	s03.Transitions = []*sc.Transition{
-		{Event: EVENT_EVENT3, Target: STATE_S04},
-		{Event: sc.NO_EVENT, Target: STATE_FAIL},
-	}
-
-	s04.Transitions = []*sc.Transition{
-		{Event: EVENT_EVENT4, Target: STATE_PASS},
-		{Event: sc.NO_EVENT, Target: STATE_FAIL},
-	}
-
-	// Parallel state transition when event1 is received
-	s01p.Transitions = []*sc.Transition{
-		{Event: EVENT_EVENT1, Target: STATE_S02},
-	}
-
-	machine, err := sc.NewMachine(root)
-	if err != nil {
-		t.Fatalf("Failed to create machine: %v", err)
-	}
-
-	// Use realtime runtime for deterministic event ordering
-	var rt *RealtimeRuntime = NewRuntime(machine, Config{
-		TickRate:         16667 * time.Microsecond,
-		MaxEventsPerTick: 100,
-	})
-	ctx := context.Background()
-
-	// Set up actions after runtime is created
-	// Exit actions
-	s01p11.ExitAction = func(ctx context.Context, event *sc.Event, from, to sc.StateID) error {
-		_ = rt.SendEvent(sc.Event{ID: EVENT_EVENT2})
-		return nil
-	}
-
-	s01p21.ExitAction = func(ctx context.Context, event *sc.Event, from, to sc.StateID) error {
-		_ = rt.SendEvent(sc.Event{ID: EVENT_EVENT1})
-		return nil
-	}
-
-	// Transition actions (should execute after exits, in document order)
-	s01p11.Transitions = []*sc.Transition{
-		{
-			Event:  sc.NO_EVENT,
-			Target: STATE_S01P12,
-			Action: func(ctx context.Context, event *sc.Event, from, to sc.StateID) error {
-				_ = rt.SendEvent(sc.Event{ID: EVENT_EVENT3})
-				return nil
-			},
-		},
-	}
-
-	s01p21.Transitions = []*sc.Transition{
-		{
-			Event:  sc.NO_EVENT,
-			Target: STATE_S01P22,
-			Action: func(ctx context.Context, event *sc.Event, from, to sc.StateID) error {
-				_ = rt.SendEvent(sc.Event{ID: EVENT_EVENT4})
-				return nil
-			},
-		},
-	}
-
-	if err := rt.Start(ctx); err != nil {
-		t.Fatalf("Failed to start runtime: %v", err)
-	}
-	defer rt.Stop()
-
-	time.Sleep(200 * time.Millisecond)
-
-	currentState := rt.GetCurrentState()
-	if currentState != STATE_PASS {
-		t.Errorf("Transition execution order should be after exits: event1, event2, event3, event4. Current state: %v", currentState)
-	}
-}
-
-// TestSCXML406_Realtime tests parallel state entry order using realtime runtime
-// SCXML test 406: states enter in entry order (parent before children, document order for siblings)
-//
-// SKIPPED: This test validates strict SCXML entry ordering when entering parallel states
-// with nested regions. Like test 405, it depends on phase-separated processing of transitions
-// across all regions. Our implementation processes each region atomically in document order.
-// See docs/SCXML_COMPLIANCE.md for details and workarounds.
-func TestSCXML406_Realtime(t *testing.T) {
-	t.Skip("Skipped: parallel region entry ordering differs from SCXML spec (see docs/SCXML_COMPLIANCE.md)")
-	// Test that states are entered in entry order (parents before children)
-	// with document order used to break ties
-	// Expected: event1 (transition action) -> event2 (s0p2 entry) -> event3 (s01p21 entry) -> event4 (s01p22 entry)
-	const (
-		STATE_ROOT   sc.StateID = 1
-		STATE_S0     sc.StateID = 2
-		STATE_S01    sc.StateID = 3
-		STATE_S0P2   sc.StateID = 4
-		STATE_S01P21 sc.StateID = 5
-		STATE_S01P22 sc.StateID = 6
-		STATE_S03    sc.StateID = 7
-		STATE_S04    sc.StateID = 8
-		STATE_S05    sc.StateID = 9
-		STATE_PASS   sc.StateID = 10
-		STATE_FAIL   sc.StateID = 11
-
-		EVENT_EVENT1 sc.EventID = 1
-		EVENT_EVENT2 sc.EventID = 2
-		EVENT_EVENT3 sc.EventID = 3
-		EVENT_EVENT4 sc.EventID = 4
-	)
-
-	root := &sc.State{ID: STATE_ROOT}
-	s0 := &sc.State{ID: STATE_S0, Parent: root}
-	s01 := &sc.State{ID: STATE_S01, Parent: s0}
-	s0p2 := &sc.State{ID: STATE_S0P2, Parent: s0, IsParallel: true}
-	s01p21 := &sc.State{ID: STATE_S01P21, Parent: s0p2}
-	s01p22 := &sc.State{ID: STATE_S01P22, Parent: s0p2}
-	s03 := &sc.State{ID: STATE_S03, Parent: s0}
-	s04 := &sc.State{ID: STATE_S04, Parent: s0}
-	s05 := &sc.State{ID: STATE_S05, Parent: s0}
-	pass := &sc.State{ID: STATE_PASS, IsFinal: true, Parent: root}
-	fail := &sc.State{ID: STATE_FAIL, IsFinal: true, Parent: root}
-
-	root.Children = map[sc.StateID]*sc.State{
-		STATE_S0:   s0,
-		STATE_PASS: pass,
-		STATE_FAIL: fail,
-	}
-
-	s0.Children = map[sc.StateID]*sc.State{
-		STATE_S01:  s01,
-		STATE_S0P2: s0p2,
-		STATE_S03:  s03,
-		STATE_S04:  s04,
-		STATE_S05:  s05,
-	}
-	s0.Initial = STATE_S01
-
-	s0p2.Children = map[sc.StateID]*sc.State{
-		STATE_S01P21: s01p21,
-		STATE_S01P22: s01p22,
-	}
-
-	// Verification chain
-	s03.Transitions = []*sc.Transition{
-		{Event: EVENT_EVENT2, Target: STATE_S04},
-		{Event: sc.NO_EVENT, Target: STATE_FAIL},
-	}
-
-	s04.Transitions = []*sc.Transition{
-		{Event: EVENT_EVENT3, Target: STATE_S05},
-		{Event: sc.NO_EVENT, Target: STATE_FAIL},
-	}
-
-	s05.Transitions = []*sc.Transition{
-		{Event: EVENT_EVENT4, Target: STATE_PASS},
-		{Event: sc.NO_EVENT, Target: STATE_FAIL},
-	}
-
-	// Transition from parallel state when event1 is received
-	s0p2.Transitions = []*sc.Transition{
-		{Event: EVENT_EVENT1, Target: STATE_S03},
-	}
-
-	machine, err := sc.NewMachine(root)
-	if err != nil {
-		t.Fatalf("Failed to create machine: %v", err)
-	}
-
-	// Use realtime runtime for deterministic event ordering
-	var rt *RealtimeRuntime = NewRuntime(machine, Config{
-		TickRate:         16667 * time.Microsecond,
-		MaxEventsPerTick: 100,
-	})
-	ctx := context.Background()
-
-	// Set up actions after runtime is created
-	// Transition from s01 to parallel state s0p2
-	s01.Transitions = []*sc.Transition{
-		{
-			Event:  sc.NO_EVENT,
-			Target: STATE_S0P2,
-			Action: func(ctx context.Context, event *sc.Event, from, to sc.StateID) error {
-				_ = rt.SendEvent(sc.Event{ID: EVENT_EVENT1})
-				return nil
-			},
-		},
-	}
-
-	// Entry order: parent s0p2 first, then children in document order
-	s0p2.EntryAction = func(ctx context.Context, event *sc.Event, from, to sc.StateID) error {
-		_ = rt.SendEvent(sc.Event{ID: EVENT_EVENT2})
-		return nil
-	}
-
-	s01p21.EntryAction = func(ctx context.Context, event *sc.Event, from, to sc.StateID) error {
-		_ = rt.SendEvent(sc.Event{ID: EVENT_EVENT3})
-		return nil
-	}
-
-	s01p22.EntryAction = func(ctx context.Context, event *sc.Event, from, to sc.StateID) error {
-		_ = rt.SendEvent(sc.Event{ID: EVENT_EVENT4})
-		return nil
-	}
-
-	if err := rt.Start(ctx); err != nil {
-		t.Fatalf("Failed to start runtime: %v", err)
-	}
-	defer rt.Stop()
-
-	time.Sleep(200 * time.Millisecond)
-
-	currentState := rt.GetCurrentState()
-	if currentState != STATE_PASS {
-		t.Errorf("Entry order should be: event1 (transition), event2 (s0p2), event3 (s01p21), event4 (s01p22). Current state: %v", currentState)
-	}
-}
@@ -0,0 +1,114 @@
+package realtime
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/comalice/statechartx"
+)
+
+// TickReport describes what one tick did.
+type TickReport struct {
+	Tick          uint64                    // tick number (GetTickNumber while the tick ran)
+	Events        []statechartx.Event       // external events processed, in order
+	Transitions   []*statechartx.Transition // transitions taken, in order (eventless and internal included)
+	Configuration []statechartx.StateID     // active states after the tick, sorted
+}
+
+// ErrNotStarted is returned by Step and World.Step before Start.
+var ErrNotStarted = errors.New("realtime: not started")
+
+// Step runs exactly one tick on the caller's goroutine and reports what happened.
+// It requires Config.Manual, since a concurrent ticker would break determinism,
+// and returns ErrNotManual otherwise, or ErrNotStarted before Start. Panics
+// raised by actions propagate to the caller.
+func (rt *RealtimeRuntime) Step() (TickReport, error) {
+	if !rt.manual {
+		return TickReport{}, ErrNotManual
+	}
+	if rt.tickCtx == nil {
+		return TickReport{}, ErrNotStarted
+	}
+
+	rt.batchMu.Lock()
+	rt.ticking = true
+	rt.batchMu.Unlock()
+	defer func() {
+		rt.batchMu.Lock()
+		rt.endTick()
+		rt.batchMu.Unlock()
+	}()
+
+	report := rt.reportTick()
+	rt.endTickHooks(report)
+	return report, nil
+}
+
+// reportTick runs processTick and reports what it did
+func (rt *RealtimeRuntime) reportTick() TickReport {
+	rt.batchMu.Lock()
+	report := TickReport{Tick: rt.tickNum}
+	rt.batchMu.Unlock()
+
+	rt.reporting = true
+	defer func() {
+		rt.reporting = false
+		rt.taken = nil
+	}()
+
+	for _, e := range rt.processTick() {
+		report.Events = append(report.Events, e.Event)
+	}
+	report.Transitions = rt.taken
+	report.Configuration = rt.Configuration()
+	return report
+}
+
+// StepN runs n ticks with Step and returns their reports. It stops at the
+// first error and returns the reports of the ticks run so far.
+func (rt *RealtimeRuntime) StepN(n int) ([]TickReport, error) {
+	reports := make([]TickReport, 0, n)
+	for i := 0; i < n; i++ {
+		report, err := rt.Step()
+		if err != nil {
+			return reports, err
+		}
+		reports = append(reports, report)
+	}
+	return reports, nil
+}
+
+// Configuration returns the active states, including ancestors and the states
+// of sequentially processed parallel regions, sorted by ID.
+func (rt *RealtimeRuntime) Configuration() []statechartx.StateID {
+	machine := rt.Runtime.GetMachine()
+	active := map[statechartx.StateID]bool{}
+	addPath := func(id statechartx.StateID) {
+		for s := machine.GetState(id); s != nil; s = s.Parent {
+			active[s.ID] = true
+		}
+	}
+
+	addPath(rt.Runtime.GetCurrentState())
+	rt.regionMu.RLock()
+	for _, regions := range rt.parallelRegionStates {
+		for _, region := range regions {
+			addPath(region.currentState)
+		}
+	}
+	rt.regionMu.RUnlock()
+
+	config := make([]statechartx.StateID, 0, len(active))
+	for id := range active {
+		config = append(config, id)
+	}
+	sort.Slice(config, func(i, j int) bool { return config[i] < config[j] })
+	return config
+}
+
+// transitionTaken collects the transitions of the tick being reported
+func (rt *RealtimeRuntime) transitionTaken(t *statechartx.Transition) {
+	if rt.reporting {
+		rt.taken = append(rt.taken, t)
+	}
+}
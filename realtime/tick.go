@@ -1,29 +1,62 @@
 package realtime
 
 import (
-	"context"
 	"sort"
+	"time"
 
 	"github.com/comalice/statechartx"
 )
 
-// processTick processes one complete tick
-func (rt *RealtimeRuntime) processTick() {
+// processTick processes one complete tick and returns the external events it
+// processed, in order
+func (rt *RealtimeRuntime) processTick() []EventWithMeta {
+	tick := rt.beginTick()
+	defer rt.observeTick(tick, time.Now())
+
 	// Phase 1: Collect events atomically
-	events := rt.collectEvents()
+	batch := rt.collectEvents()
+
+	// Snapshot the state before the tick for Rollback (if enabled)
+	rt.saveSnapshot(batch)
+
+	rt.batchMu.Lock()
+	rt.inTick = true
+	rt.batchMu.Unlock()
+	defer func() {
+		rt.batchMu.Lock()
+		rt.inTick = false
+		rt.batchMu.Unlock()
+	}()
 
-	// Phase 2: Sort for deterministic order
+	// Phase 2: Run the tick start hook (if set) and add the events it sent
+	events := append(batch, rt.startTickHooks(tick)...)
+
+	// Phase 3: Hold back events scheduled for later ticks, add the ones now due,
+	// sort for deterministic order and apply the event filters (if any)
+	events = rt.dispatchScheduled(events)
 	rt.sortEvents(events)
+	events = rt.filterEvents(tick, events)
+	if rt.onEventsCollected != nil {
+		rt.onEventsCollected(tick, events)
+	}
 
-	// Phase 3: Process events using EXISTING core methods
+	// Phase 4: Process events using EXISTING core methods
 	rt.processEvents(events)
 
-	// Phase 4: Process macrostep to completion (eventless transitions + internal events)
+	// Phase 5: Process macrostep to completion (eventless transitions + internal events)
 	// This implements SCXML run-to-completion semantics
-	rt.processMacrostepToCompletion(context.Background())
+	rt.processMacrostepToCompletion(rt.actionCtx)
 
-	// Phase 5: Process parallel regions sequentially (if any)
+	// Phase 6: Process parallel regions sequentially (if any)
 	rt.processParallelRegionsSequentially()
+
+	// Phase 7: Hash the resulting state (if enabled)
+	rt.recordTick(events)
+
+	// Phase 8: Write the tick's batch to the replay file (if recording)
+	rt.recordReplay(batch)
+
+	return events
 }
 
 // collectEvents atomically retrieves and clears the event batch
@@ -31,9 +64,18 @@ func (rt *RealtimeRuntime) collectEvents() []EventWithMeta {
 	rt.batchMu.Lock()
 	defer rt.batchMu.Unlock()
 
+	if rt.resimulating {
+		rt.replayExternalEvents()
+	}
+	return rt.takeBatch()
+}
+
+// takeBatch returns the batched events and starts an empty batch. The caller
+// holds batchMu.
+func (rt *RealtimeRuntime) takeBatch() []EventWithMeta {
 	events := rt.eventBatch
 	rt.eventBatch = make([]EventWithMeta, 0, cap(rt.eventBatch))
-
+	rt.resetCoalesceIndex()
 	return events
 }
 
@@ -61,7 +103,7 @@ func (rt *RealtimeRuntime) processMicrostepsIfNeeded() {
 
 	// CRITICAL: Call EXISTING processMicrosteps method
 	// Reuses existing microstep logic (lines 784-861 of statechart.go)
-	rt.Runtime.ProcessMicrosteps(context.Background())
+	rt.Runtime.ProcessMicrosteps(rt.actionCtx)
 }
 
 // processParallelRegionsSequentially processes parallel regions in document order
@@ -89,7 +131,7 @@ func (rt *RealtimeRuntime) processParallelStateRegions(parallelStateID statechar
 	})
 
 	// Process each region's event queue sequentially
-	ctx := context.Background()
+	ctx := rt.actionCtx
 	for _, regionID := range regionIDs {
 		region := regions[regionID]
 		if region == nil {
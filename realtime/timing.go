@@ -0,0 +1,114 @@
+package realtime
+
+import (
+	"time"
+)
+
+// CatchUpPolicy decides what the tick loop does when it falls behind the wall
+// clock, i.e. when more than one TickRate has elapsed since the last tick slot
+// because a tick overran its budget or the process was descheduled.
+type CatchUpPolicy int
+
+const (
+	// CatchUpSlowMotion runs one tick per wake-up and forgets the backlog:
+	// simulation time (GetTickNumber × TickRate) falls behind the wall clock,
+	// as if the simulation ran in slow motion. This is the default.
+	CatchUpSlowMotion CatchUpPolicy = iota
+	// CatchUpSkip runs one tick per wake-up and advances the tick number past
+	// the missed ticks without running them, keeping simulation time aligned
+	// with the wall clock. Events wait for the next tick that runs.
+	CatchUpSkip
+	// CatchUpBurst runs missed ticks back to back, up to Config.MaxCatchUpTicks
+	// extra ticks per wake-up, and skips any further backlog as CatchUpSkip does.
+	CatchUpBurst
+)
+
+// DefaultMaxCatchUpTicks is the CatchUpBurst limit used when
+// Config.MaxCatchUpTicks is zero.
+const DefaultMaxCatchUpTicks = 4
+
+// TickMetrics are cumulative timing statistics of a runtime's ticks.
+type TickMetrics struct {
+	Ticks         uint64        // ticks processed
+	Overruns      uint64        // ticks that took longer than the scaled TickRate
+	CatchUpTicks  uint64        // extra ticks run back to back by CatchUpBurst
+	SkippedTicks  uint64        // tick numbers passed over by CatchUpSkip or CatchUpBurst
+	DroppedTicks  uint64        // tick slots forgotten by CatchUpSlowMotion
+	LastDuration  time.Duration // processing time of the last tick
+	MaxDuration   time.Duration // longest tick
+	TotalDuration time.Duration // processing time of all ticks
+}
+
+// MeanDuration returns the average tick processing time.
+func (m TickMetrics) MeanDuration() time.Duration {
+	if m.Ticks == 0 {
+		return 0
+	}
+	return m.TotalDuration / time.Duration(m.Ticks)
+}
+
+// TickMetrics returns the timing statistics so far.
+func (rt *RealtimeRuntime) TickMetrics() TickMetrics {
+	rt.metricsMu.Lock()
+	defer rt.metricsMu.Unlock()
+	return rt.metrics
+}
+
+// observeTick records the duration of the tick that started at start and
+// reports an overrun (called from processTick)
+func (rt *RealtimeRuntime) observeTick(tick uint64, start time.Time) {
+	took := time.Since(start)
+	rt.batchMu.Lock()
+	budget := rt.period
+	rt.batchMu.Unlock()
+
+	rt.metricsMu.Lock()
+	rt.metrics.Ticks++
+	rt.metrics.LastDuration = took
+	rt.metrics.TotalDuration += took
+	if took > rt.metrics.MaxDuration {
+		rt.metrics.MaxDuration = took
+	}
+	overrun := took > budget
+	if overrun {
+		rt.metrics.Overruns++
+	}
+	rt.metricsMu.Unlock()
+
+	if overrun && rt.onOverrun != nil {
+		rt.onOverrun(tick, took)
+	}
+}
+
+// ticksToRun returns how many of the due tick slots to run now under the
+// catch-up policy
+func (rt *RealtimeRuntime) ticksToRun(due int64) int64 {
+	limit := int64(1)
+	if rt.catchUp == CatchUpBurst {
+		limit += int64(rt.maxCatchUp)
+	}
+	if due < limit {
+		return due
+	}
+	return limit
+}
+
+// fallBehind accounts for tick slots that were due but not run
+func (rt *RealtimeRuntime) fallBehind(ran, missed int64) {
+	rt.metricsMu.Lock()
+	if ran > 1 {
+		rt.metrics.CatchUpTicks += uint64(ran - 1)
+	}
+	if rt.catchUp == CatchUpSlowMotion {
+		rt.metrics.DroppedTicks += uint64(missed)
+	} else {
+		rt.metrics.SkippedTicks += uint64(missed)
+	}
+	rt.metricsMu.Unlock()
+
+	if rt.catchUp != CatchUpSlowMotion && missed > 0 {
+		rt.batchMu.Lock()
+		rt.tickNum += uint64(missed)
+		rt.batchMu.Unlock()
+	}
+}
@@ -0,0 +1,519 @@
+package realtime
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/comalice/statechartx"
+)
+
+// WorldEventProcessorType is the IOProcessor type a World registers with its
+// members. Targets are member names:
+//
+//	rt.Send(ctx, statechartx.SendRequest{Type: realtime.WorldEventProcessorType, Target: "enemy-7", Event: hit})
+const WorldEventProcessorType = "x-statechartx-world"
+
+// Delivery decides when events sent between World members are processed.
+type Delivery int
+
+const (
+	// DeliverNextTick processes events sent between members during a tick at
+	// the next tick, regardless of step order. This is the default and the only
+	// delivery that allows parallel stepping.
+	DeliverNextTick Delivery = iota
+	// DeliverSameTick processes events sent to a member later in step order
+	// during the current tick, and events sent to a member that has already
+	// stepped at the next tick. Members are always stepped sequentially.
+	DeliverSameTick
+)
+
+// WorldConfig configures a World.
+type WorldConfig struct {
+	TickRate time.Duration // Fixed tick rate shared by all members (default 60 FPS)
+
+	// Manual disables the World's ticker: the caller advances all members with
+	// Step or StepN.
+	Manual bool
+
+	// Workers is how many goroutines step members in parallel with
+	// DeliverNextTick. Zero or one steps sequentially. Results are identical to
+	// sequential stepping as long as actions only share state through events.
+	Workers int
+
+	// Delivery is when events sent between members are processed.
+	Delivery Delivery
+}
+
+// MemberReport is the TickReport of one World member.
+type MemberReport struct {
+	Name string
+	TickReport
+	Err error // why the member could not be stepped (e.g. ErrNotStarted if its Start failed)
+}
+
+// WorldReport describes what one World tick did.
+type WorldReport struct {
+	Tick    uint64         // World tick number (WorldTick while the tick ran)
+	Members []MemberReport // members stepped, in step order
+	Dropped int            // events not delivered: the target was removed or rejected them (e.g. queue full)
+}
+
+// World steps many tick-based runtimes on a single clock. Members are
+// RealtimeRuntimes created with Config.Manual; every World tick steps each of
+// them once, in ascending name order, so cross-member ordering is
+// deterministic. Members send events to each other through the World's
+// IOProcessor (WorldEventProcessorType); other code uses SendTo.
+type World struct {
+	tickRate time.Duration
+	manual   bool
+	workers  int
+	delivery Delivery
+
+	mu        sync.RWMutex
+	members   []*worldMember // sorted by name
+	byName    map[string]*worldMember
+	byRuntime map[*statechartx.Runtime]*worldMember
+	tick      uint64
+	stepping  bool               // a tick is running
+	removed   []*RealtimeRuntime // members removed during a tick, stopped at its end
+	external  []worldMessage     // events sent with SendTo, delivered at the next tick
+	ctx       context.Context    // set by Start
+	done      bool               // Stop has run; a World cannot be restarted
+	stepMu    sync.Mutex         // serializes ticks
+	ticker    *time.Ticker
+	cancel    context.CancelFunc
+	stopped   chan struct{}
+}
+
+// worldMember is a runtime registered with a World
+type worldMember struct {
+	name  string
+	rt    *RealtimeRuntime
+	world *World
+	pos   int // index in the step order of the running tick (-1 if not stepped)
+
+	outMu  sync.Mutex
+	outbox []worldMessage // events sent by this member, delivered at the next tick
+}
+
+// worldMessage is an event on its way to a member
+type worldMessage struct {
+	to    *worldMember
+	event statechartx.Event
+}
+
+// NewWorld creates an empty World.
+func NewWorld(cfg WorldConfig) *World {
+	if cfg.TickRate == 0 {
+		cfg.TickRate = 16667 * time.Microsecond // Default 60 FPS
+	}
+	if cfg.Delivery == DeliverSameTick {
+		cfg.Workers = 1
+	}
+	return &World{
+		tickRate:  cfg.TickRate,
+		manual:    cfg.Manual,
+		workers:   cfg.Workers,
+		delivery:  cfg.Delivery,
+		byName:    make(map[string]*worldMember),
+		byRuntime: make(map[*statechartx.Runtime]*worldMember),
+		stopped:   make(chan struct{}),
+	}
+}
+
+// Add registers rt under name. rt must have been created with Config.Manual
+// and not be started; the World starts it now if the World is running, or in
+// Start otherwise. Members added during a tick are first stepped at the next.
+func (w *World) Add(name string, rt *RealtimeRuntime) error {
+	if !rt.manual {
+		return fmt.Errorf("realtime: world member %q requires Config.Manual", name)
+	}
+	if rt.tickCtx != nil {
+		return fmt.Errorf("realtime: world member %q is already started", name)
+	}
+
+	w.mu.Lock()
+	if _, exists := w.byName[name]; exists {
+		w.mu.Unlock()
+		return fmt.Errorf("realtime: world member %q already exists", name)
+	}
+	m := &worldMember{name: name, rt: rt, world: w, pos: -1}
+	i := sort.Search(len(w.members), func(i int) bool { return w.members[i].name >= name })
+	w.members = append(w.members, nil)
+	copy(w.members[i+1:], w.members[i:])
+	w.members[i] = m
+	w.byName[name] = m
+	w.byRuntime[rt.Runtime] = m
+	ctx := w.ctx
+	w.mu.Unlock()
+
+	rt.RegisterIOProcessor(w)
+	if ctx != nil {
+		return m.start(ctx)
+	}
+	return nil
+}
+
+// start starts the member's runtime with a context that identifies it to
+// MemberName and SendToMember
+func (m *worldMember) start(ctx context.Context) error {
+	return m.rt.Start(context.WithValue(ctx, worldMemberKey{}, m))
+}
+
+// Remove unregisters and stops the member name. A member removed during a
+// tick finishes the tick and is stopped at its end. Events still on their
+// way to it are dropped and counted in WorldReport.Dropped.
+func (w *World) Remove(name string) error {
+	w.mu.Lock()
+	m, ok := w.byName[name]
+	if !ok {
+		w.mu.Unlock()
+		return fmt.Errorf("realtime: no world member %q", name)
+	}
+	i := sort.Search(len(w.members), func(i int) bool { return w.members[i].name >= name })
+	w.members = append(w.members[:i], w.members[i+1:]...)
+	delete(w.byName, name)
+	delete(w.byRuntime, m.rt.Runtime)
+	started := w.ctx != nil
+	if w.stepping && started {
+		w.removed = append(w.removed, m.rt)
+		started = false
+	}
+	w.mu.Unlock()
+
+	if started {
+		return m.rt.Stop()
+	}
+	return nil
+}
+
+// Member returns the runtime registered under name.
+func (w *World) Member(name string) (*RealtimeRuntime, bool) {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	m, ok := w.byName[name]
+	if !ok {
+		return nil, false
+	}
+	return m.rt, true
+}
+
+// Len returns the number of members.
+func (w *World) Len() int {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return len(w.members)
+}
+
+// WorldTick returns the number of World ticks run so far.
+func (w *World) WorldTick() uint64 {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	return w.tick
+}
+
+// Start starts every member and, unless WorldConfig.Manual is set, the World's
+// ticker. A World that has been stopped cannot be started again.
+func (w *World) Start(ctx context.Context) error {
+	w.mu.Lock()
+	if w.done {
+		w.mu.Unlock()
+		return errors.New("realtime: world cannot be restarted after Stop")
+	}
+	if w.ctx != nil {
+		w.mu.Unlock()
+		return errors.New("realtime: world already started")
+	}
+	w.ctx = ctx
+	members := append([]*worldMember(nil), w.members...)
+	w.mu.Unlock()
+
+	for _, m := range members {
+		if err := m.start(ctx); err != nil {
+			return fmt.Errorf("realtime: starting world member %q: %w", m.name, err)
+		}
+	}
+
+	if w.manual {
+		return nil
+	}
+	tickCtx, cancel := context.WithCancel(ctx)
+	w.cancel = cancel
+	w.ticker = time.NewTicker(w.tickRate)
+	go w.tickLoop(tickCtx)
+	return nil
+}
+
+// Stop stops the World's ticker and every member.
+func (w *World) Stop() error {
+	if w.cancel != nil {
+		w.cancel()
+		<-w.stopped
+	}
+	if w.ticker != nil {
+		w.ticker.Stop()
+	}
+
+	w.mu.Lock()
+	members := append([]*worldMember(nil), w.members...)
+	started := w.ctx != nil
+	w.ctx = nil
+	w.done = true
+	w.mu.Unlock()
+
+	if !started {
+		return nil
+	}
+	var firstErr error
+	for _, m := range members {
+		if err := m.rt.Stop(); err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("realtime: stopping world member %q: %w", m.name, err)
+		}
+	}
+	return firstErr
+}
+
+// tickLoop runs one World tick per ticker period
+func (w *World) tickLoop(ctx context.Context) {
+	defer close(w.stopped)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-w.ticker.C:
+			func() {
+				defer func() {
+					if r := recover(); r != nil {
+						// Recover from panic in a member's tick
+						// In production, this should be logged
+						_ = r // TODO: Add proper logging
+					}
+				}()
+				w.runTick()
+			}()
+		}
+	}
+}
+
+// Step runs one World tick on the caller's goroutine: it delivers the events
+// sent since the last tick and steps every member once. It returns
+// ErrNotManual without WorldConfig.Manual and ErrNotStarted unless the World is
+// running. A panic in a member's tick propagates to the caller after all
+// members have stepped.
+func (w *World) Step() (WorldReport, error) {
+	if !w.manual {
+		return WorldReport{}, ErrNotManual
+	}
+	w.mu.RLock()
+	started := w.ctx != nil
+	w.mu.RUnlock()
+	if !started {
+		return WorldReport{}, ErrNotStarted
+	}
+	return w.runTick(), nil
+}
+
+// StepN runs n World ticks with Step and returns their reports. It stops at
+// the first error and returns the reports of the ticks run so far.
+func (w *World) StepN(n int) ([]WorldReport, error) {
+	reports := make([]WorldReport, 0, n)
+	for i := 0; i < n; i++ {
+		report, err := w.Step()
+		if err != nil {
+			return reports, err
+		}
+		reports = append(reports, report)
+	}
+	return reports, nil
+}
+
+// SendTo queues event for the member named to at the next World tick. Use it
+// from outside the World; members send through WorldEventProcessorType so
+// their events are ordered by sender.
+func (w *World) SendTo(to string, event statechartx.Event) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	target, ok := w.byName[to]
+	if !ok {
+		return fmt.Errorf("%w: world member %q", statechartx.ErrTargetUnavailable, to)
+	}
+	w.external = append(w.external, worldMessage{to: target, event: event})
+	return nil
+}
+
+// worldMemberKey is the context key of the member whose actions run with the context
+type worldMemberKey struct{}
+
+// MemberName returns the name of the World member whose action received ctx.
+// Actions shared by many members use it to tell them apart.
+func MemberName(ctx context.Context) (string, bool) {
+	m, ok := ctx.Value(worldMemberKey{}).(*worldMember)
+	if !ok {
+		return "", false
+	}
+	return m.name, true
+}
+
+// SendToMember sends event from the World member whose action received ctx to
+// the member named to, through the member's runtime Send and the World's
+// IOProcessor.
+func SendToMember(ctx context.Context, to string, event statechartx.Event) error {
+	m, ok := ctx.Value(worldMemberKey{}).(*worldMember)
+	if !ok {
+		return errors.New("realtime: SendToMember called outside a world member's action")
+	}
+	return m.rt.Send(ctx, statechartx.SendRequest{Type: WorldEventProcessorType, Target: to, Event: event})
+}
+
+// Type implements statechartx.IOProcessor.
+func (w *World) Type() string {
+	return WorldEventProcessorType
+}
+
+// Send implements statechartx.IOProcessor by delivering req.Event to the
+// member named req.Target according to WorldConfig.Delivery. Sources that are
+// not members are treated like SendTo.
+func (w *World) Send(ctx context.Context, source *statechartx.Runtime, req statechartx.SendRequest) error {
+	w.mu.RLock()
+	target, ok := w.byName[req.Target]
+	sender := w.byRuntime[source]
+	sameTick := w.delivery == DeliverSameTick && w.stepping &&
+		sender != nil && sender.pos >= 0 && target != nil && target.pos > sender.pos
+	w.mu.RUnlock()
+	if !ok {
+		return fmt.Errorf("%w: world member %q", statechartx.ErrTargetUnavailable, req.Target)
+	}
+
+	switch {
+	case sameTick:
+		// The target steps later in this tick
+		return target.rt.SendEvent(req.Event)
+	case sender == nil:
+		w.mu.Lock()
+		w.external = append(w.external, worldMessage{to: target, event: req.Event})
+		w.mu.Unlock()
+	default:
+		sender.outMu.Lock()
+		sender.outbox = append(sender.outbox, worldMessage{to: target, event: req.Event})
+		sender.outMu.Unlock()
+	}
+	return nil
+}
+
+// runTick delivers pending events and steps every member once
+func (w *World) runTick() WorldReport {
+	w.stepMu.Lock()
+	defer w.stepMu.Unlock()
+
+	w.mu.Lock()
+	members := append([]*worldMember(nil), w.members...)
+	for i, m := range members {
+		m.pos = i
+	}
+	external := w.external
+	w.external = nil
+	w.stepping = true
+	report := WorldReport{Tick: w.tick, Members: make([]MemberReport, len(members))}
+	w.mu.Unlock()
+
+	defer func() {
+		w.mu.Lock()
+		for _, m := range members {
+			m.pos = -1
+		}
+		w.stepping = false
+		w.tick++
+		removed := w.removed
+		w.removed = nil
+		w.mu.Unlock()
+		for _, rt := range removed {
+			rt.Stop()
+		}
+	}()
+
+	// Deliver events from outside first, then each member's outbox in step
+	// order, so every member receives them in the same order on every run
+	report.Dropped = w.deliver(external)
+	for _, m := range members {
+		m.outMu.Lock()
+		outbox := m.outbox
+		m.outbox = nil
+		m.outMu.Unlock()
+		report.Dropped += w.deliver(outbox)
+	}
+
+	panics := make([]any, len(members))
+	if w.workers <= 1 || len(members) < 2 {
+		for i, m := range members {
+			stepMember(m, &report.Members[i], &panics[i])
+		}
+	} else {
+		w.stepParallel(members, report.Members, panics)
+	}
+
+	// Re-raise the panic of the first member in step order, if any, once
+	// every member has stepped
+	for _, p := range panics {
+		if p != nil {
+			panic(p)
+		}
+	}
+	return report
+}
+
+// stepMember steps m once, storing its report or the value it panicked with
+func stepMember(m *worldMember, report *MemberReport, panicked *any) {
+	defer func() {
+		if r := recover(); r != nil {
+			*panicked = r
+		}
+	}()
+	tick, err := m.rt.Step()
+	*report = MemberReport{Name: m.name, TickReport: tick, Err: err}
+}
+
+// stepParallel steps members on the worker pool, writing each report and
+// panic at the member's position
+func (w *World) stepParallel(members []*worldMember, reports []MemberReport, panics []any) {
+	var next atomic.Int64
+	var wg sync.WaitGroup
+	workers := w.workers
+	if workers > len(members) {
+		workers = len(members)
+	}
+	for n := 0; n < workers; n++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				i := int(next.Add(1) - 1)
+				if i >= len(members) {
+					return
+				}
+				stepMember(members[i], &reports[i], &panics[i])
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// deliver batches messages for their targets' next tick and returns how many
+// were dropped: messages to members removed since they were sent, and those
+// the target's SendEvent rejected.
+func (w *World) deliver(messages []worldMessage) int {
+	dropped := 0
+	for _, msg := range messages {
+		w.mu.RLock()
+		current := w.byName[msg.to.name] == msg.to
+		w.mu.RUnlock()
+		if !current || msg.to.rt.SendEvent(msg.event) != nil {
+			dropped++
+		}
+	}
+	return dropped
+}
@@ -0,0 +1,207 @@
+package realtime
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/comalice/statechartx"
+)
+
+// pingMachine builds a chart that counts hits and forwards each one to the
+// member named by next
+func pingMachine(t *testing.T, next func(name string) string) (*statechartx.MachineBuilder, *statechartx.Machine) {
+	t.Helper()
+	b := statechartx.NewMachineBuilder("app", "idle")
+	forward := func(ctx context.Context, evt *statechartx.Event, from, to statechartx.StateID) error {
+		name, _ := MemberName(ctx)
+		return SendToMember(ctx, next(name), statechartx.Event{ID: b.EventID("hit")})
+	}
+	b.State("idle").On("hit", "one", nil, forward)
+	b.State("one").On("hit", "two", nil, forward)
+	b.State("two").On("hit", "idle", nil, forward)
+	machine, err := b.Build()
+	if err != nil {
+		t.Fatalf("Failed to create machine: %v", err)
+	}
+	return b, machine
+}
+
+// newWorld creates a manual world with n members running machine
+func newWorld(t *testing.T, cfg WorldConfig, machine *statechartx.Machine, n int) *World {
+	t.Helper()
+	cfg.Manual = true
+	w := NewWorld(cfg)
+	for i := 0; i < n; i++ {
+		if err := w.Add(fmt.Sprintf("m%02d", i), NewRuntime(machine, Config{Manual: true, HashHistory: 64})); err != nil {
+			t.Fatalf("Add failed: %v", err)
+		}
+	}
+	if err := w.Start(context.Background()); err != nil {
+		t.Fatalf("Start failed: %v", err)
+	}
+	return w
+}
+
+// mustWorldStep runs one World tick and fails the test on error
+func mustWorldStep(t *testing.T, w *World) WorldReport {
+	t.Helper()
+	report, err := w.Step()
+	if err != nil {
+		t.Fatalf("World.Step failed: %v", err)
+	}
+	return report
+}
+
+// TestWorldDelivery tests when events sent between members are processed
+// under each Delivery
+func TestWorldDelivery(t *testing.T) {
+	next := func(name string) string {
+		if name == "m00" {
+			return "m01"
+		}
+		return "m00"
+	}
+	b, machine := pingMachine(t, next)
+
+	tests := []struct {
+		delivery Delivery
+		want     []string // states of m00/m01 after each tick
+	}{
+		{DeliverNextTick, []string{"one/idle", "one/one", "two/one", "two/two"}},
+		{DeliverSameTick, []string{"one/one", "two/two", "idle/idle", "one/one"}},
+	}
+	for _, tt := range tests {
+		w := newWorld(t, WorldConfig{Delivery: tt.delivery}, machine, 2)
+		if err := w.SendTo("m00", statechartx.Event{ID: b.EventID("hit")}); err != nil {
+			t.Fatalf("SendTo failed: %v", err)
+		}
+		for i, want := range tt.want {
+			report := mustWorldStep(t, w)
+			if report.Tick != uint64(i) || len(report.Members) != 2 || report.Members[0].Name != "m00" {
+				t.Fatalf("delivery %d: unexpected report %+v", tt.delivery, report)
+			}
+			a, _ := w.Member("m00")
+			c, _ := w.Member("m01")
+			got := b.GetName(a.GetCurrentState()) + "/" + b.GetName(c.GetCurrentState())
+			if got != want {
+				t.Errorf("delivery %d tick %d: expected %s, got %s", tt.delivery, i, want, got)
+			}
+		}
+		w.Stop()
+	}
+}
+
+// TestWorldParallelMatchesSequential tests that stepping on a worker pool
+// gives the same results as stepping sequentially
+func TestWorldParallelMatchesSequential(t *testing.T) {
+	const n = 40
+	next := func(name string) string {
+		var i int
+		fmt.Sscanf(name, "m%d", &i)
+		return fmt.Sprintf("m%02d", (i*7+3)%n)
+	}
+	b, machine := pingMachine(t, next)
+
+	run := func(workers int) *World {
+		w := newWorld(t, WorldConfig{Workers: workers}, machine, n)
+		for i := 0; i < n; i += 3 {
+			w.SendTo(fmt.Sprintf("m%02d", i), statechartx.Event{ID: b.EventID("hit")})
+		}
+		w.StepN(30)
+		return w
+	}
+	sequential := run(1)
+	defer sequential.Stop()
+	parallel := run(8)
+	defer parallel.Stop()
+
+	for i := 0; i < n; i++ {
+		name := fmt.Sprintf("m%02d", i)
+		a, _ := sequential.Member(name)
+		c, _ := parallel.Member(name)
+		if d := FindDivergence(a.TickRecords(), c.TickRecords()); d != nil {
+			t.Errorf("%s: expected identical ticks, got %s", name, d)
+		}
+	}
+}
+
+// TestWorldAddRemove tests adding and removing members while running
+func TestWorldAddRemove(t *testing.T) {
+	b, machine := pingMachine(t, func(string) string { return "gone" })
+	w := newWorld(t, WorldConfig{}, machine, 1)
+	defer w.Stop()
+
+	if err := w.Add("m00", NewRuntime(machine, Config{Manual: true})); err == nil {
+		t.Error("expected an error adding a duplicate name")
+	}
+	if err := w.Add("x", NewRuntime(machine, Config{})); err == nil {
+		t.Error("expected an error adding a runtime without Config.Manual")
+	}
+
+	late := NewRuntime(machine, Config{Manual: true})
+	if err := w.Add("gone", late); err != nil {
+		t.Fatalf("Add failed: %v", err)
+	}
+	w.SendTo("m00", statechartx.Event{ID: b.EventID("hit")})
+	w.Step() // m00 forwards to gone
+	if err := w.Remove("gone"); err != nil {
+		t.Fatalf("Remove failed: %v", err)
+	}
+	report := mustWorldStep(t, w) // the forwarded hit is dropped
+	if w.Len() != 1 || len(report.Members) != 1 {
+		t.Errorf("expected one member, got %d", w.Len())
+	}
+	if report.Dropped != 1 {
+		t.Errorf("expected 1 dropped event, got %d", report.Dropped)
+	}
+	if late.GetTickNumber() != 1 || !late.IsInState(b.GetID("idle")) {
+		t.Errorf("expected the removed member to stop after one tick in idle, got tick %d", late.GetTickNumber())
+	}
+	if err := w.SendTo("gone", statechartx.Event{ID: b.EventID("hit")}); err == nil {
+		t.Error("expected an error sending to a removed member")
+	}
+}
+
+// TestWorldStepPanic tests that misuse of Step is reported as an error, that a
+// panicking member does not stop the others from stepping when members are
+// stepped sequentially, and that a stopped World cannot be restarted
+func TestWorldStepPanic(t *testing.T) {
+	b := statechartx.NewMachineBuilder("app", "idle")
+	b.State("idle").On("boom", "done", nil, func(ctx context.Context, evt *statechartx.Event, from, to statechartx.StateID) error {
+		panic("boom")
+	})
+	b.State("done").Atomic()
+	machine, err := b.Build()
+	if err != nil {
+		t.Fatalf("Failed to create machine: %v", err)
+	}
+	if _, err := NewWorld(WorldConfig{}).Step(); !errors.Is(err, ErrNotManual) {
+		t.Errorf("expected ErrNotManual without WorldConfig.Manual, got %v", err)
+	}
+	if _, err := NewWorld(WorldConfig{Manual: true}).Step(); !errors.Is(err, ErrNotStarted) {
+		t.Errorf("expected ErrNotStarted before Start, got %v", err)
+	}
+	w := newWorld(t, WorldConfig{}, machine, 2)
+
+	w.SendTo("m00", statechartx.Event{ID: b.EventID("boom")})
+	func() {
+		defer func() {
+			if r := recover(); r != "boom" {
+				t.Errorf("expected the member's panic to propagate, got %v", r)
+			}
+		}()
+		w.Step()
+	}()
+	if m, _ := w.Member("m01"); m.GetTickNumber() != 1 {
+		t.Errorf("expected m01 to step despite the panic, got tick %d", m.GetTickNumber())
+	}
+
+	if err := w.Stop(); err != nil {
+		t.Fatalf("Stop failed: %v", err)
+	}
+	if err := w.Start(context.Background()); err == nil {
+		t.Error("expected an error restarting a stopped world")
+	}
+}
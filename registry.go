@@ -0,0 +1,223 @@
+package statechartx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Guard combinators
+//
+// A nil Guard is treated as always true, matching Transition semantics.
+// Errors short-circuit evaluation and are returned with false.
+
+// And returns a guard that passes when all guards pass.
+// Evaluation stops at the first guard that fails or errors.
+func And(guards ...Guard) Guard {
+	return func(ctx context.Context, evt *Event, from StateID, to StateID) (bool, error) {
+		for _, g := range guards {
+			if g == nil {
+				continue
+			}
+			ok, err := g(ctx, evt, from, to)
+			if err != nil {
+				return false, err
+			}
+			if !ok {
+				return false, nil
+			}
+		}
+		return true, nil
+	}
+}
+
+// Or returns a guard that passes when any guard passes.
+// Evaluation stops at the first guard that passes or errors.
+// Or with no guards never passes.
+func Or(guards ...Guard) Guard {
+	return func(ctx context.Context, evt *Event, from StateID, to StateID) (bool, error) {
+		for _, g := range guards {
+			if g == nil {
+				return true, nil
+			}
+			ok, err := g(ctx, evt, from, to)
+			if err != nil {
+				return false, err
+			}
+			if ok {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+}
+
+// Not returns a guard that inverts g. Errors from g are returned with false.
+func Not(g Guard) Guard {
+	return func(ctx context.Context, evt *Event, from StateID, to StateID) (bool, error) {
+		if g == nil {
+			return false, nil
+		}
+		ok, err := g(ctx, evt, from, to)
+		if err != nil {
+			return false, err
+		}
+		return !ok, nil
+	}
+}
+
+// Always returns a guard that always passes.
+func Always() Guard {
+	return func(ctx context.Context, evt *Event, from StateID, to StateID) (bool, error) {
+		return true, nil
+	}
+}
+
+// Never returns a guard that never passes.
+func Never() Guard {
+	return func(ctx context.Context, evt *Event, from StateID, to StateID) (bool, error) {
+		return false, nil
+	}
+}
+
+// Registry errors
+var (
+	ErrDuplicateName = errors.New("name already registered")
+	ErrUnknownName   = errors.New("name not registered")
+)
+
+// Registry holds guards, actions and final state data by name so that builders
+// and data-driven loaders (JSON, SCXML) can refer to them by string.
+// Safe for concurrent use.
+type Registry struct {
+	mu      sync.RWMutex
+	guards  map[string]Guard
+	actions map[string]Action
+	data    map[string]any
+}
+
+// NewRegistry creates an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		guards:  make(map[string]Guard),
+		actions: make(map[string]Action),
+		data:    make(map[string]any),
+	}
+}
+
+// RegisterGuard registers g under name.
+// Returns ErrDuplicateName if name is already registered as a guard.
+func (r *Registry) RegisterGuard(name string, g Guard) error {
+	if name == "" || g == nil {
+		return errors.New("guard name and function are required")
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.guards[name]; exists {
+		return fmt.Errorf("%w: guard %q", ErrDuplicateName, name)
+	}
+	r.guards[name] = g
+	return nil
+}
+
+// RegisterAction registers a under name.
+// Returns ErrDuplicateName if name is already registered as an action.
+func (r *Registry) RegisterAction(name string, a Action) error {
+	if name == "" || a == nil {
+		return errors.New("action name and function are required")
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.actions[name]; exists {
+		return fmt.Errorf("%w: action %q", ErrDuplicateName, name)
+	}
+	r.actions[name] = a
+	return nil
+}
+
+// RegisterData registers a value under name, for use as FinalStateData.
+// Returns ErrDuplicateName if name is already registered as data.
+func (r *Registry) RegisterData(name string, value any) error {
+	if name == "" {
+		return errors.New("data name is required")
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.data[name]; exists {
+		return fmt.Errorf("%w: data %q", ErrDuplicateName, name)
+	}
+	r.data[name] = value
+	return nil
+}
+
+// Data returns the value registered under name.
+func (r *Registry) Data(name string) (any, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	v, ok := r.data[name]
+	return v, ok
+}
+
+// Guard returns the guard registered under name.
+func (r *Registry) Guard(name string) (Guard, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	g, ok := r.guards[name]
+	return g, ok
+}
+
+// Action returns the action registered under name.
+func (r *Registry) Action(name string) (Action, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	a, ok := r.actions[name]
+	return a, ok
+}
+
+// ResolveGuard returns the guard registered under name, or an error wrapping ErrUnknownName.
+func (r *Registry) ResolveGuard(name string) (Guard, error) {
+	if g, ok := r.Guard(name); ok {
+		return g, nil
+	}
+	return nil, fmt.Errorf("%w: guard %q", ErrUnknownName, name)
+}
+
+// ResolveActions returns the actions registered under names, in order,
+// or an error wrapping ErrUnknownName for the first missing name.
+func (r *Registry) ResolveActions(names ...string) ([]Action, error) {
+	actions := make([]Action, 0, len(names))
+	for _, name := range names {
+		a, ok := r.Action(name)
+		if !ok {
+			return nil, fmt.Errorf("%w: action %q", ErrUnknownName, name)
+		}
+		actions = append(actions, a)
+	}
+	return actions, nil
+}
+
+// GuardNames returns the registered guard names in sorted order.
+func (r *Registry) GuardNames() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := make([]string, 0, len(r.guards))
+	for name := range r.guards {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ActionNames returns the registered action names in sorted order.
+func (r *Registry) ActionNames() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	names := make([]string, 0, len(r.actions))
+	for name := range r.actions {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,148 @@
+package statechartx_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	. "github.com/comalice/statechartx"
+)
+
+func constGuard(result bool, err error, calls *int) Guard {
+	return func(ctx context.Context, evt *Event, from StateID, to StateID) (bool, error) {
+		*calls++
+		return result, err
+	}
+}
+
+func TestGuardCombinators(t *testing.T) {
+	ctx := context.Background()
+	boom := errors.New("boom")
+
+	tests := []struct {
+		name      string
+		guard     func(calls *int) Guard
+		want      bool
+		wantErr   error
+		wantCalls int
+	}{
+		{"always", func(*int) Guard { return Always() }, true, nil, 0},
+		{"never", func(*int) Guard { return Never() }, false, nil, 0},
+		{"and all true", func(c *int) Guard { return And(constGuard(true, nil, c), constGuard(true, nil, c)) }, true, nil, 2},
+		{"and short-circuits", func(c *int) Guard { return And(constGuard(false, nil, c), constGuard(true, nil, c)) }, false, nil, 1},
+		{"and empty", func(*int) Guard { return And() }, true, nil, 0},
+		{"and error", func(c *int) Guard { return And(constGuard(true, boom, c), constGuard(true, nil, c)) }, false, boom, 1},
+		{"or short-circuits", func(c *int) Guard { return Or(constGuard(true, nil, c), constGuard(false, nil, c)) }, true, nil, 1},
+		{"or all false", func(c *int) Guard { return Or(constGuard(false, nil, c), constGuard(false, nil, c)) }, false, nil, 2},
+		{"or empty", func(*int) Guard { return Or() }, false, nil, 0},
+		{"or error", func(c *int) Guard { return Or(constGuard(false, nil, c), constGuard(true, boom, c)) }, false, boom, 2},
+		{"not", func(c *int) Guard { return Not(constGuard(false, nil, c)) }, true, nil, 1},
+		{"not error", func(c *int) Guard { return Not(constGuard(false, boom, c)) }, false, boom, 1},
+		{"nested", func(c *int) Guard { return Or(Never(), And(Always(), Not(constGuard(false, nil, c)))) }, true, nil, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			got, err := tt.guard(&calls)(ctx, nil, 0, 0)
+			if got != tt.want {
+				t.Errorf("expected %v, got %v", tt.want, got)
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("expected error %v, got %v", tt.wantErr, err)
+			}
+			if calls != tt.wantCalls {
+				t.Errorf("expected %d guard calls, got %d", tt.wantCalls, calls)
+			}
+		})
+	}
+}
+
+func TestRegistryRegisterAndResolve(t *testing.T) {
+	reg := NewRegistry()
+	if err := reg.RegisterGuard("ok", Always()); err != nil {
+		t.Fatal(err)
+	}
+	if err := reg.RegisterGuard("ok", Never()); !errors.Is(err, ErrDuplicateName) {
+		t.Errorf("expected ErrDuplicateName, got %v", err)
+	}
+	if _, err := reg.ResolveGuard("missing"); !errors.Is(err, ErrUnknownName) {
+		t.Errorf("expected ErrUnknownName, got %v", err)
+	}
+
+	noop := func(ctx context.Context, evt *Event, from, to StateID) error { return nil }
+	reg.RegisterAction("b", noop)
+	reg.RegisterAction("a", noop)
+	if got := reg.ActionNames(); len(got) != 2 || got[0] != "a" || got[1] != "b" {
+		t.Errorf("expected sorted action names [a b], got %v", got)
+	}
+	if _, err := reg.ResolveActions("a", "missing"); !errors.Is(err, ErrUnknownName) {
+		t.Errorf("expected ErrUnknownName, got %v", err)
+	}
+}
+
+func TestBuilderWithRegistry(t *testing.T) {
+	reg := NewRegistry()
+	var entered, fired int
+	reg.RegisterGuard("isAdmin", Always())
+	reg.RegisterGuard("isBanned", Never())
+	reg.RegisterAction("countEntry", func(ctx context.Context, evt *Event, from, to StateID) error {
+		entered++
+		return nil
+	})
+	reg.RegisterAction("countFire", func(ctx context.Context, evt *Event, from, to StateID) error {
+		fired++
+		return nil
+	})
+
+	b := NewMachineBuilder("app", "idle").WithRegistry(reg)
+	b.State("idle").
+		OnRef("go", "banned", "isBanned").
+		OnRef("go", "admin", "isAdmin", "countFire")
+	b.State("admin").EntryRef("countEntry")
+	b.State("banned").Atomic()
+
+	machine, err := b.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	idle := machine.GetState(b.GetID("idle"))
+	if idle.Transitions[1].GuardRef != "isAdmin" || idle.Transitions[1].ActionRefs[0] != "countFire" {
+		t.Errorf("transition refs not recorded: %+v", idle.Transitions[1])
+	}
+
+	rt := NewRuntime(machine, nil)
+	ctx := context.Background()
+	if err := rt.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	defer rt.Stop()
+
+	rt.SendEvent(ctx, Event{ID: b.EventID("go")})
+	time.Sleep(50 * time.Millisecond)
+
+	if !rt.IsInState(b.GetID("admin")) {
+		t.Error("should take the transition guarded by isAdmin")
+	}
+	if entered != 1 || fired != 1 {
+		t.Errorf("expected 1 entry and 1 transition action, got %d and %d", entered, fired)
+	}
+}
+
+func TestBuilderUnknownRefs(t *testing.T) {
+	b := NewMachineBuilder("app", "idle").WithRegistry(NewRegistry())
+	b.State("idle").OnRef("go", "done", "missingGuard")
+	b.State("done").Atomic()
+
+	if _, err := b.Build(); !errors.Is(err, ErrUnknownName) {
+		t.Errorf("expected ErrUnknownName, got %v", err)
+	}
+
+	b = NewMachineBuilder("app", "idle")
+	b.State("idle").EntryRef("log")
+	if _, err := b.Build(); err == nil {
+		t.Error("refs without a registry should fail to build")
+	}
+}
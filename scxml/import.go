@@ -0,0 +1,166 @@
+package scxml
+
+import (
+	"encoding/xml"
+	"errors"
+	"fmt"
+
+	"github.com/comalice/statechartx"
+)
+
+// element is a generic XML element used while reading documents.
+type element struct {
+	XMLName  xml.Name
+	Attrs    []xml.Attr `xml:",any,attr"`
+	Children []element  `xml:",any"`
+}
+
+func (e *element) get(name string) string {
+	return e.getNS("", name)
+}
+
+// getNS returns the attribute name in namespace space
+func (e *element) getNS(space, name string) string {
+	for _, a := range e.Attrs {
+		if a.Name.Local == name && a.Name.Space == space {
+			return a.Value
+		}
+	}
+	return ""
+}
+
+// Import reads an SCXML document in the subset written by Export and returns a
+// MachineBuilder whose guards, actions and final data are resolved from reg.
+// Call Build on the result to obtain the Machine.
+//
+// Elements outside the subset (datamodel expressions, <script>, <invoke>, ...)
+// produce an error rather than being silently dropped.
+func Import(data []byte, reg *statechartx.Registry) (*statechartx.MachineBuilder, error) {
+	def, err := Definition(data)
+	if err != nil {
+		return nil, err
+	}
+	return def.Builder(reg)
+}
+
+// Definition parses an SCXML document into a MachineDefinition. See Import.
+func Definition(data []byte) (*statechartx.MachineDefinition, error) {
+	var root element
+	if err := xml.Unmarshal(data, &root); err != nil {
+		return nil, err
+	}
+	if root.XMLName.Local != "scxml" {
+		return nil, fmt.Errorf("root element is <%s>, want <scxml>", root.XMLName.Local)
+	}
+	if dm := root.get("datamodel"); dm != "" && dm != "null" {
+		return nil, fmt.Errorf("unsupported datamodel %q", dm)
+	}
+
+	def := &statechartx.MachineDefinition{
+		Version: statechartx.MachineFormatVersion,
+		ID:      root.get("name"),
+		Initial: root.get("initial"),
+	}
+	if def.ID == "" {
+		def.ID = "scxml"
+	}
+	for i := range root.Children {
+		sd, err := readState(&root.Children[i])
+		if err != nil {
+			return nil, err
+		}
+		def.States = append(def.States, sd)
+	}
+	if def.Initial == "" && len(def.States) > 0 {
+		def.Initial = def.States[0].ID
+	}
+	return def, nil
+}
+
+// readState converts a <state>, <parallel>, <final> or <history> element
+func readState(e *element) (statechartx.StateDefinition, error) {
+	sd := statechartx.StateDefinition{ID: e.get("id"), FinalData: e.getNS(ActionNamespace, "data")}
+	if sd.ID == "" {
+		return sd, fmt.Errorf("<%s> has no id", e.XMLName.Local)
+	}
+
+	switch e.XMLName.Local {
+	case "state":
+	case "parallel":
+		sd.Type = "parallel"
+	case "final":
+		sd.Type = "final"
+	case "history":
+		sd.Type = "history"
+		sd.History = e.get("type")
+		for i := range e.Children {
+			if c := &e.Children[i]; c.XMLName.Local == "transition" {
+				sd.Default = c.get("target")
+			}
+		}
+		return sd, nil
+	default:
+		return sd, fmt.Errorf("unsupported element <%s>", e.XMLName.Local)
+	}
+	sd.Initial = e.get("initial")
+
+	for i := range e.Children {
+		c := &e.Children[i]
+		switch c.XMLName.Local {
+		case "onentry":
+			actions, err := readActions(c)
+			if err != nil {
+				return sd, err
+			}
+			sd.Entry = append(sd.Entry, actions...)
+		case "onexit":
+			actions, err := readActions(c)
+			if err != nil {
+				return sd, err
+			}
+			sd.Exit = append(sd.Exit, actions...)
+		case "transition":
+			actions, err := readActions(c)
+			if err != nil {
+				return sd, err
+			}
+			sd.Transitions = append(sd.Transitions, statechartx.TransitionDefinition{
+				Event:   c.get("event"),
+				Target:  c.get("target"),
+				Guard:   c.getNS(ActionNamespace, "cond"),
+				Actions: actions,
+			})
+		default:
+			child, err := readState(c)
+			if err != nil {
+				return sd, err
+			}
+			sd.States = append(sd.States, child)
+		}
+	}
+
+	if sd.Type == "" && len(sd.States) > 0 {
+		sd.Type = "compound"
+		if sd.Initial == "" {
+			sd.Initial = sd.States[0].ID
+		}
+	}
+	return sd, nil
+}
+
+// readActions collects <sx:action name="..."/> children of an executable content block
+func readActions(e *element) ([]string, error) {
+	var names []string
+	for i := range e.Children {
+		c := &e.Children[i]
+		if c.XMLName.Space != ActionNamespace || c.XMLName.Local != "action" {
+			return nil, fmt.Errorf("unsupported executable content <%s> in <%s>", c.XMLName.Local, e.XMLName.Local)
+		}
+		name := c.get("name")
+		if name == "" {
+			return nil, errors.New("<sx:action> has no name")
+		}
+		names = append(names, name)
+	}
+	return names, nil
+}
@@ -0,0 +1,185 @@
+// Package scxml converts StatechartX machines to and from W3C SCXML documents.
+//
+// Export writes a <scxml> document for a *statechartx.Machine so charts designed in Go
+// with MachineBuilder can be reviewed in SCXML editors, checked by other tools and
+// diffed in code review. Import reads the same subset back into a MachineBuilder.
+//
+// # Mapping
+//
+//   - Compound, atomic, parallel and final states map to <state>, <parallel> and <final>
+//   - History states map to <history type="shallow|deep"> with a default <transition>
+//   - Final state data references map to an sx:data="name" attribute on <final>
+//   - Guards map to an sx:cond="name" attribute on <transition> and actions to
+//     <sx:action name="name"/> elements (inside <onentry>, <onexit> and <transition>)
+//   - Transitions without a target are internal (type="internal")
+//
+// Guards, actions and final data are referenced by Registry name, exactly as in the
+// JSON definition format (see statechartx.MarshalMachine). They live in the StatechartX
+// namespace because the null data model allows neither cond expressions other than
+// In() nor expr attributes, so documents stay valid for W3C SCXML validators.
+package scxml
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/comalice/statechartx"
+)
+
+// Namespaces used in exported documents.
+const (
+	Namespace       = "http://www.w3.org/2005/07/scxml"
+	ActionNamespace = "https://github.com/comalice/statechartx"
+)
+
+// Export returns the SCXML document for m, naming states and events with names
+// (typically the *statechartx.MachineBuilder that built m).
+func Export(m *statechartx.Machine, names statechartx.Namer) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := Write(&buf, m, names); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Write writes the SCXML document for m to w. See Export.
+func Write(w io.Writer, m *statechartx.Machine, names statechartx.Namer) error {
+	def, err := statechartx.DefineMachine(m, names)
+	if err != nil {
+		return err
+	}
+
+	root := &node{name: "scxml"}
+	root.attr("xmlns", Namespace)
+	root.attr("xmlns:sx", ActionNamespace)
+	root.attr("version", "1.0")
+	root.attr("datamodel", "null")
+	root.attr("name", def.ID)
+	if def.Initial != "" {
+		root.attr("initial", def.Initial)
+	}
+	for i := range def.States {
+		root.children = append(root.children, stateNode(&def.States[i]))
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	return root.write(w, 0)
+}
+
+// stateNode converts a state definition into its SCXML element
+func stateNode(sd *statechartx.StateDefinition) *node {
+	n := &node{name: "state"}
+	switch sd.Type {
+	case "parallel":
+		n.name = "parallel"
+	case "final":
+		n.name = "final"
+	case "history":
+		n.name = "history"
+	}
+	n.attr("id", sd.ID)
+
+	if sd.Type == "history" {
+		n.attr("type", sd.History)
+		if sd.Default != "" {
+			t := &node{name: "transition"}
+			t.attr("target", sd.Default)
+			n.children = append(n.children, t)
+		}
+		return n
+	}
+
+	if sd.Initial != "" {
+		n.attr("initial", sd.Initial)
+	}
+	if sd.FinalData != "" {
+		n.attr("sx:data", sd.FinalData)
+	}
+	if len(sd.Entry) > 0 {
+		n.children = append(n.children, actionBlock("onentry", sd.Entry))
+	}
+	if len(sd.Exit) > 0 {
+		n.children = append(n.children, actionBlock("onexit", sd.Exit))
+	}
+
+	for _, td := range sd.Transitions {
+		t := &node{name: "transition"}
+		if td.Event != "" {
+			t.attr("event", td.Event)
+		}
+		if td.Guard != "" {
+			t.attr("sx:cond", td.Guard)
+		}
+		if td.Target != "" {
+			t.attr("target", td.Target)
+		} else {
+			t.attr("type", "internal")
+		}
+		t.children = actionNodes(td.Actions)
+		n.children = append(n.children, t)
+	}
+
+	for i := range sd.States {
+		n.children = append(n.children, stateNode(&sd.States[i]))
+	}
+	return n
+}
+
+// actionBlock wraps named actions in an executable content block
+func actionBlock(name string, actions []string) *node {
+	return &node{name: name, children: actionNodes(actions)}
+}
+
+// actionNodes converts action names into <sx:action> elements
+func actionNodes(actions []string) []*node {
+	var nodes []*node
+	for _, name := range actions {
+		a := &node{name: "sx:action"}
+		a.attr("name", name)
+		nodes = append(nodes, a)
+	}
+	return nodes
+}
+
+// node is a minimal XML element used for deterministic, indented output.
+type node struct {
+	name     string
+	attrs    [][2]string
+	children []*node
+}
+
+func (n *node) attr(key, value string) {
+	n.attrs = append(n.attrs, [2]string{key, value})
+}
+
+func (n *node) write(w io.Writer, depth int) error {
+	var sb strings.Builder
+	sb.WriteString(strings.Repeat("  ", depth))
+	sb.WriteString("<" + n.name)
+	for _, a := range n.attrs {
+		sb.WriteString(" " + a[0] + `="`)
+		xml.EscapeText(&sb, []byte(a[1]))
+		sb.WriteString(`"`)
+	}
+	if len(n.children) == 0 {
+		sb.WriteString("/>\n")
+		_, err := io.WriteString(w, sb.String())
+		return err
+	}
+	sb.WriteString(">\n")
+	if _, err := io.WriteString(w, sb.String()); err != nil {
+		return err
+	}
+	for _, child := range n.children {
+		if err := child.write(w, depth+1); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintf(w, "%s</%s>\n", strings.Repeat("  ", depth), n.name)
+	return err
+}
@@ -0,0 +1,116 @@
+package scxml_test
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/comalice/statechartx"
+	"github.com/comalice/statechartx/scxml"
+)
+
+func buildChart() (*statechartx.MachineBuilder, *statechartx.Registry) {
+	reg := statechartx.NewRegistry()
+	reg.RegisterGuard("hasPower", statechartx.Always())
+	reg.RegisterAction("beep", func(ctx context.Context, evt *statechartx.Event, from, to statechartx.StateID) error {
+		return nil
+	})
+	reg.RegisterData("result", "ok")
+
+	b := statechartx.NewMachineBuilder("device", "off").WithRegistry(reg)
+	b.State("off").OnRef("power", "on", "hasPower", "beep")
+	b.State("on").Compound("on.idle").EntryRef("beep").ExitRef("beep").
+		OnRef("power", "off", "").
+		OnRef("resume", "on.hist", "").
+		OnRef("done.state.on", "finished", "")
+	b.State("on.idle").OnInternalRef("ping", "", "beep").OnRef("", "on.done", "hasPower")
+	b.State("on.done").FinalRef("result")
+	b.State("on.hist").History(statechartx.HistoryDeep, "on.idle")
+	b.State("finished").Final(nil)
+	b.State("both").Parallel()
+	b.State("both.left").Atomic()
+	b.State("both.right").Atomic()
+	return b, reg
+}
+
+func TestExportRoundTrip(t *testing.T) {
+	b, reg := buildChart()
+	machine, err := b.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first, err := scxml.Export(machine, b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The document must be well-formed XML
+	dec := xml.NewDecoder(strings.NewReader(string(first)))
+	for {
+		if _, err := dec.Token(); err != nil {
+			if err != io.EOF {
+				t.Fatalf("malformed document: %v\n%s", err, first)
+			}
+			break
+		}
+	}
+
+	for _, want := range []string{
+		`<scxml xmlns="http://www.w3.org/2005/07/scxml"`,
+		`<transition event="power" sx:cond="hasPower" target="on">`,
+		`<sx:action name="beep"/>`,
+		`<history id="on.hist" type="deep">`,
+		`<transition target="on.idle"/>`,
+		`<transition event="ping" type="internal">`,
+		`<final id="on.done" sx:data="result"/>`,
+		`<parallel id="both">`,
+		`<final id="finished"/>`,
+	} {
+		if !strings.Contains(string(first), want) {
+			t.Errorf("document should contain %s\n%s", want, first)
+		}
+	}
+
+	b2, err := scxml.Import(first, reg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	machine2, err := b2.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := scxml.Export(machine2, b2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(first) != string(second) {
+		t.Errorf("round trip changed the document:\n%s\n---\n%s", first, second)
+	}
+}
+
+func TestExportRejectsUnnamedFunctions(t *testing.T) {
+	b := statechartx.NewMachineBuilder("app", "a")
+	b.State("a").On("go", "b", statechartx.Always(), nil)
+	b.State("b").Atomic()
+	machine, err := b.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := scxml.Export(machine, b); !errors.Is(err, statechartx.ErrUnnamedBehavior) {
+		t.Errorf("expected ErrUnnamedBehavior, got %v", err)
+	}
+}
+
+func TestImportUnsupportedContent(t *testing.T) {
+	doc := `<scxml xmlns="http://www.w3.org/2005/07/scxml" initial="a">
+  <state id="a"><onentry><log expr="'hi'"/></onentry></state>
+</scxml>`
+	if _, err := scxml.Import([]byte(doc), nil); err == nil {
+		t.Error("expected an error for unsupported executable content")
+	}
+}
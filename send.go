@@ -0,0 +1,460 @@
+package statechartx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Event I/O processor types understood by Send.
+const (
+	// SCXMLEventProcessorType is the SCXML Event I/O Processor. It handles the
+	// special targets (#_internal, #_parent, #_<invokeid>, #_scxml_<sessionid>)
+	// and is used when SendRequest.Type is empty.
+	SCXMLEventProcessorType = "http://www.w3.org/TR/scxml/#SCXMLEventProcessor"
+
+	// BusEventProcessorType is the in-process Bus processor. Targets are endpoint
+	// names registered with Bus.Register.
+	BusEventProcessorType = "x-statechartx-bus"
+)
+
+// Special send targets for the SCXML Event I/O Processor.
+//
+// Events sent to TargetInternal during a macrostep go to the sender's internal
+// queue and are processed before the next external event, as in SCXML. Sent
+// outside a macrostep, there is nothing to finish first and they are queued
+// like external events.
+const (
+	TargetInternal      = "#_internal" // the sending session's internal queue
+	TargetParent        = "#_parent"   // the session that invoked the sender
+	targetSessionPrefix = "#_scxml_"   // #_scxml_<sessionid>
+	targetInvokePrefix  = "#_"         // #_<invokeid>
+)
+
+// Send errors. They are wrapped in a *SendError and carried as the Data of
+// the ERROR_COMMUNICATION event raised in the sending session.
+var (
+	ErrUnknownIOProcessor = errors.New("unknown event I/O processor")
+	ErrInvalidSendTarget  = errors.New("invalid send target")
+	ErrTargetUnavailable  = errors.New("send target unavailable")
+)
+
+// SendRequest describes an event to deliver through an Event I/O Processor.
+// It mirrors the attributes of the SCXML <send> element.
+type SendRequest struct {
+	Target string        // Processor-specific address; "" sends to the sender's own queue
+	Type   string        // IOProcessor type; "" means SCXMLEventProcessorType
+	Event  Event         // Event to deliver
+	Delay  time.Duration // Delivery delay; 0 delivers immediately
+	ID     string        // Send ID used by CancelSend (generated if empty and Delay > 0)
+}
+
+// SendError reports a failed delivery. It is returned by Send and used as the
+// Data of the ERROR_COMMUNICATION event raised in the sending session.
+type SendError struct {
+	Request SendRequest
+	Err     error
+}
+
+func (e *SendError) Error() string {
+	return fmt.Sprintf("send %q to %q: %v", e.Request.ID, e.Request.Target, e.Err)
+}
+
+func (e *SendError) Unwrap() error {
+	return e.Err
+}
+
+// IOProcessor delivers events to targets outside the sending state machine.
+// Implementations must be safe for concurrent use.
+type IOProcessor interface {
+	// Type returns the processor type matched against SendRequest.Type.
+	Type() string
+
+	// Send delivers req.Event to req.Target on behalf of source.
+	Send(ctx context.Context, source *Runtime, req SendRequest) error
+}
+
+// Session registry used to resolve #_scxml_<sessionid> targets.
+var (
+	sessionCounter uint64
+	sessions       sync.Map // sessionID → *Runtime
+)
+
+// nextSessionID returns a process-unique session ID.
+func nextSessionID() string {
+	return strconv.FormatUint(atomic.AddUint64(&sessionCounter, 1), 10)
+}
+
+// registerSession makes rt addressable as #_scxml_<sessionid>.
+func registerSession(rt *Runtime) {
+	sessions.Store(rt.sessionID, rt)
+}
+
+// unregisterSession removes rt from the session registry.
+func unregisterSession(rt *Runtime) {
+	sessions.Delete(rt.sessionID)
+}
+
+// LookupSession returns the started runtime with the given session ID.
+func LookupSession(sessionID string) (*Runtime, bool) {
+	v, ok := sessions.Load(sessionID)
+	if !ok {
+		return nil, false
+	}
+	return v.(*Runtime), true
+}
+
+// SessionID returns the runtime's session ID, usable as #_scxml_<sessionid>.
+func (rt *Runtime) SessionID() string {
+	return rt.sessionID
+}
+
+// Parent returns the runtime that invoked this one, or nil.
+func (rt *Runtime) Parent() *Runtime {
+	rt.sendMu.Lock()
+	defer rt.sendMu.Unlock()
+	return rt.parent
+}
+
+// AttachChild links child as an invoked session of rt under invokeID.
+// Afterwards rt can address child as #_<invokeid> and child can address rt as #_parent.
+func (rt *Runtime) AttachChild(invokeID string, child *Runtime) error {
+	if invokeID == "" || child == nil {
+		return errors.New("invoke ID and child runtime are required")
+	}
+	rt.sendMu.Lock()
+	if _, exists := rt.children[invokeID]; exists {
+		rt.sendMu.Unlock()
+		return fmt.Errorf("invoke ID %q already attached", invokeID)
+	}
+	rt.children[invokeID] = child
+	rt.sendMu.Unlock()
+
+	child.sendMu.Lock()
+	child.parent = rt
+	child.sendMu.Unlock()
+	return nil
+}
+
+// DetachChild removes the invoked session registered under invokeID.
+func (rt *Runtime) DetachChild(invokeID string) {
+	rt.sendMu.Lock()
+	child := rt.children[invokeID]
+	delete(rt.children, invokeID)
+	rt.sendMu.Unlock()
+
+	if child != nil {
+		child.sendMu.Lock()
+		if child.parent == rt {
+			child.parent = nil
+		}
+		child.sendMu.Unlock()
+	}
+}
+
+// RegisterIOProcessor makes p available to Send under p.Type().
+// Registering a processor for an existing type replaces it.
+func (rt *Runtime) RegisterIOProcessor(p IOProcessor) {
+	rt.sendMu.Lock()
+	defer rt.sendMu.Unlock()
+	rt.processors[p.Type()] = p
+}
+
+// Send delivers an event through the Event I/O Processor named by req.Type.
+//
+// Immediate sends return the delivery error, if any. Delayed sends return nil
+// once scheduled and can be cancelled with CancelSend. In both cases a failed
+// delivery also raises ERROR_COMMUNICATION in this runtime with a *SendError as Data.
+func (rt *Runtime) Send(ctx context.Context, req SendRequest) error {
+	processor, err := rt.processorFor(req.Type)
+	if err != nil {
+		return rt.sendFailed(req, err)
+	}
+
+	if req.Delay <= 0 {
+		if err := processor.Send(ctx, rt, req); err != nil {
+			return rt.sendFailed(req, err)
+		}
+		return nil
+	}
+
+	rt.sendMu.Lock()
+	if req.ID == "" {
+		req.ID = "send-" + nextSessionID()
+	}
+	if old, exists := rt.pendingSends[req.ID]; exists {
+		old.Stop()
+	}
+	rt.pendingSends[req.ID] = time.AfterFunc(req.Delay, func() {
+		rt.sendMu.Lock()
+		delete(rt.pendingSends, req.ID)
+		rt.sendMu.Unlock()
+
+		if err := processor.Send(context.Background(), rt, req); err != nil {
+			rt.sendFailed(req, err)
+		}
+	})
+	rt.sendMu.Unlock()
+	return nil
+}
+
+// CancelSend cancels a delayed send that has not been delivered yet.
+// Returns false if no pending send has the given ID.
+func (rt *Runtime) CancelSend(sendID string) bool {
+	rt.sendMu.Lock()
+	defer rt.sendMu.Unlock()
+
+	timer, exists := rt.pendingSends[sendID]
+	if !exists {
+		return false
+	}
+	timer.Stop()
+	delete(rt.pendingSends, sendID)
+	return true
+}
+
+// cancelPendingSends stops all delayed sends (called on Stop)
+func (rt *Runtime) cancelPendingSends() {
+	rt.sendMu.Lock()
+	defer rt.sendMu.Unlock()
+	for id, timer := range rt.pendingSends {
+		timer.Stop()
+		delete(rt.pendingSends, id)
+	}
+}
+
+// processorFor resolves a processor type, defaulting to the SCXML processor
+func (rt *Runtime) processorFor(typ string) (IOProcessor, error) {
+	if typ == "" || typ == SCXMLEventProcessorType || typ == "scxml" {
+		return scxmlProcessor{}, nil
+	}
+	rt.sendMu.Lock()
+	defer rt.sendMu.Unlock()
+	if p, ok := rt.processors[typ]; ok {
+		return p, nil
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUnknownIOProcessor, typ)
+}
+
+// sendFailed raises error.communication in the sending session and returns the wrapped error
+func (rt *Runtime) sendFailed(req SendRequest, err error) error {
+	sendErr := &SendError{Request: req, Err: err}
+	if req.Event.ID != ERROR_COMMUNICATION {
+		// Nothing further to report if error.communication itself cannot be delivered
+		_ = rt.enqueueAsync(Event{ID: ERROR_COMMUNICATION, Data: sendErr})
+	}
+	return sendErr
+}
+
+// Deliver places an event on this runtime's external queue, honouring any event
+// sink installed with SetEventSink. IOProcessor implementations use it to hand
+// events to their target session.
+func (rt *Runtime) Deliver(ctx context.Context, event Event) error {
+	rt.sendMu.Lock()
+	sink := rt.eventSink
+	rt.sendMu.Unlock()
+
+	if sink != nil {
+		return sink(ctx, event)
+	}
+	if rt.started() == nil {
+		return ErrTargetUnavailable
+	}
+	return rt.SendEvent(ctx, event)
+}
+
+// started returns the runtime's context once Start has run, or nil
+func (rt *Runtime) started() context.Context {
+	rt.sendMu.Lock()
+	defer rt.sendMu.Unlock()
+	return rt.ctx
+}
+
+// enqueueAsync delivers an event to this runtime without blocking the caller.
+// Senders may hold rt.mu (inside actions), so a full queue is drained by a goroutine.
+// Errors from the event sink are returned; a failed background delivery raises
+// ERROR_COMMUNICATION unless the runtime is stopping.
+func (rt *Runtime) enqueueAsync(event Event) error {
+	rt.sendMu.Lock()
+	sink := rt.eventSink
+	rt.sendMu.Unlock()
+
+	if sink != nil {
+		return sink(context.Background(), event)
+	}
+	runCtx := rt.started()
+	if runCtx == nil {
+		return ErrTargetUnavailable
+	}
+
+	rt.idle.add(1)
+	select {
+	case rt.eventQueue <- event:
+		return nil
+	default:
+	}
+
+	go func() {
+		defer rt.idle.done()
+		sendCtx, cancel := context.WithTimeout(context.Background(), DefaultSendTimeout)
+		defer cancel()
+		if err := rt.SendEvent(sendCtx, event); err != nil && runCtx.Err() == nil && event.ID != ERROR_COMMUNICATION {
+			rt.sendFailed(SendRequest{Target: TargetInternal, Event: event}, err)
+		}
+	}()
+	return nil
+}
+
+// raiseInternal queues event on the internal queue if a macrostep is running,
+// and delivers it like an external event otherwise. An event sink replaces
+// both, since the runtime that installed it runs the macrosteps.
+func (rt *Runtime) raiseInternal(event Event) error {
+	rt.sendMu.Lock()
+	sink := rt.eventSink
+	rt.sendMu.Unlock()
+
+	if sink == nil {
+		rt.internalMu.Lock()
+		if rt.inMacrostep {
+			rt.internalQueue = append(rt.internalQueue, event)
+			rt.internalMu.Unlock()
+			return nil
+		}
+		rt.internalMu.Unlock()
+	}
+	return rt.enqueueAsync(event)
+}
+
+// runMacrostep runs step with #_internal sends queued, then processes the
+// internal queue until it is empty. The caller holds rt.mu.
+func (rt *Runtime) runMacrostep(step func()) {
+	rt.internalMu.Lock()
+	rt.inMacrostep = true
+	rt.internalMu.Unlock()
+
+	step()
+	for {
+		event, ok := rt.nextInternal()
+		if !ok {
+			return
+		}
+		rt.takeTransition(event)
+	}
+}
+
+// nextInternal pops the internal queue. Once it is empty the macrostep ends,
+// in the same critical section so no event is queued after the last pop.
+func (rt *Runtime) nextInternal() (Event, bool) {
+	rt.internalMu.Lock()
+	defer rt.internalMu.Unlock()
+	if len(rt.internalQueue) == 0 {
+		rt.inMacrostep = false
+		return Event{}, false
+	}
+	event := rt.internalQueue[0]
+	rt.internalQueue = rt.internalQueue[1:]
+	return event, true
+}
+
+// scxmlProcessor implements the SCXML Event I/O Processor special targets.
+type scxmlProcessor struct{}
+
+func (scxmlProcessor) Type() string {
+	return SCXMLEventProcessorType
+}
+
+func (scxmlProcessor) Send(ctx context.Context, source *Runtime, req SendRequest) error {
+	target, err := resolveSCXMLTarget(source, req.Target)
+	if err != nil {
+		return err
+	}
+	if req.Target == TargetInternal {
+		return source.raiseInternal(req.Event)
+	}
+	if target == source {
+		return source.enqueueAsync(req.Event)
+	}
+	return target.Deliver(ctx, req.Event)
+}
+
+// resolveSCXMLTarget maps an SCXML target expression to a runtime
+func resolveSCXMLTarget(source *Runtime, target string) (*Runtime, error) {
+	switch {
+	case target == "" || target == TargetInternal:
+		return source, nil
+
+	case target == TargetParent:
+		if parent := source.Parent(); parent != nil {
+			return parent, nil
+		}
+		return nil, fmt.Errorf("%w: session %s has no parent", ErrTargetUnavailable, source.sessionID)
+
+	case strings.HasPrefix(target, targetSessionPrefix):
+		sessionID := strings.TrimPrefix(target, targetSessionPrefix)
+		if rt, ok := LookupSession(sessionID); ok {
+			return rt, nil
+		}
+		return nil, fmt.Errorf("%w: session %s", ErrTargetUnavailable, sessionID)
+
+	case strings.HasPrefix(target, targetInvokePrefix):
+		invokeID := strings.TrimPrefix(target, targetInvokePrefix)
+		source.sendMu.Lock()
+		child := source.children[invokeID]
+		source.sendMu.Unlock()
+		if child != nil {
+			return child, nil
+		}
+		return nil, fmt.Errorf("%w: invoke %s", ErrTargetUnavailable, invokeID)
+	}
+
+	return nil, fmt.Errorf("%w: %s", ErrInvalidSendTarget, target)
+}
+
+// Bus is an in-process Event I/O Processor that routes events between
+// runtimes registered under endpoint names. Register the same Bus with every
+// participating runtime via RegisterIOProcessor.
+type Bus struct {
+	mu        sync.RWMutex
+	endpoints map[string]*Runtime
+}
+
+// NewBus creates an empty bus.
+func NewBus() *Bus {
+	return &Bus{endpoints: make(map[string]*Runtime)}
+}
+
+// Register makes rt reachable as target name and registers the bus with rt.
+func (b *Bus) Register(name string, rt *Runtime) {
+	b.mu.Lock()
+	b.endpoints[name] = rt
+	b.mu.Unlock()
+	rt.RegisterIOProcessor(b)
+}
+
+// Unregister removes the endpoint name.
+func (b *Bus) Unregister(name string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delete(b.endpoints, name)
+}
+
+// Type implements IOProcessor.
+func (b *Bus) Type() string {
+	return BusEventProcessorType
+}
+
+// Send implements IOProcessor by delivering to the endpoint named req.Target.
+func (b *Bus) Send(ctx context.Context, source *Runtime, req SendRequest) error {
+	b.mu.RLock()
+	target, ok := b.endpoints[req.Target]
+	b.mu.RUnlock()
+	if !ok {
+		return fmt.Errorf("%w: bus endpoint %q", ErrTargetUnavailable, req.Target)
+	}
+	return target.Deliver(ctx, req.Event)
+}
@@ -0,0 +1,247 @@
+package statechartx
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+const (
+	STATE_SEND_IDLE StateID = 1
+	STATE_SEND_GOT  StateID = 2
+	STATE_SEND_ERR  StateID = 3
+
+	EVENT_SEND_PING EventID = 10
+)
+
+// newSendTestRuntime builds idle --PING--> got, idle --error.communication--> err
+func newSendTestRuntime(t *testing.T) *Runtime {
+	t.Helper()
+	idle := &State{ID: STATE_SEND_IDLE}
+	idle.Transitions = []*Transition{
+		{Event: EVENT_SEND_PING, Target: STATE_SEND_GOT},
+		{Event: ERROR_COMMUNICATION, Target: STATE_SEND_ERR},
+	}
+	root := &State{
+		ID:      100,
+		Initial: STATE_SEND_IDLE,
+		Children: map[StateID]*State{
+			STATE_SEND_IDLE: idle,
+			STATE_SEND_GOT:  {ID: STATE_SEND_GOT},
+			STATE_SEND_ERR:  {ID: STATE_SEND_ERR},
+		},
+	}
+	machine, err := NewMachine(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rt := NewRuntime(machine, nil)
+	if err := rt.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { rt.Stop() })
+	return rt
+}
+
+func TestSendInternalAndSession(t *testing.T) {
+	ctx := context.Background()
+
+	self := newSendTestRuntime(t)
+	if err := self.Send(ctx, SendRequest{Target: TargetInternal, Event: Event{ID: EVENT_SEND_PING}}); err != nil {
+		t.Fatal(err)
+	}
+
+	other := newSendTestRuntime(t)
+	target := "#_scxml_" + other.SessionID()
+	if err := self.Send(ctx, SendRequest{Target: target, Event: Event{ID: EVENT_SEND_PING}}); err != nil {
+		t.Fatal(err)
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	if !self.IsInState(STATE_SEND_GOT) {
+		t.Error("#_internal send should reach the sender")
+	}
+	if !other.IsInState(STATE_SEND_GOT) {
+		t.Error("#_scxml_<sessionid> send should reach the other session")
+	}
+}
+
+func TestSendParentAndInvoke(t *testing.T) {
+	ctx := context.Background()
+	parent := newSendTestRuntime(t)
+	child := newSendTestRuntime(t)
+
+	if err := parent.AttachChild("worker", child); err != nil {
+		t.Fatal(err)
+	}
+	if err := parent.Send(ctx, SendRequest{Target: "#_worker", Event: Event{ID: EVENT_SEND_PING}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := child.Send(ctx, SendRequest{Target: TargetParent, Event: Event{ID: EVENT_SEND_PING}}); err != nil {
+		t.Fatal(err)
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	if !child.IsInState(STATE_SEND_GOT) {
+		t.Error("#_<invokeid> send should reach the child")
+	}
+	if !parent.IsInState(STATE_SEND_GOT) {
+		t.Error("#_parent send should reach the parent")
+	}
+}
+
+func TestSendBus(t *testing.T) {
+	ctx := context.Background()
+	a := newSendTestRuntime(t)
+	b := newSendTestRuntime(t)
+
+	bus := NewBus()
+	bus.Register("a", a)
+	bus.Register("b", b)
+
+	if err := a.Send(ctx, SendRequest{Type: BusEventProcessorType, Target: "b", Event: Event{ID: EVENT_SEND_PING}}); err != nil {
+		t.Fatal(err)
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	if !b.IsInState(STATE_SEND_GOT) {
+		t.Error("bus send should reach endpoint b")
+	}
+	if a.IsInState(STATE_SEND_GOT) {
+		t.Error("bus send should not reach the sender")
+	}
+}
+
+func TestSendErrorCommunication(t *testing.T) {
+	ctx := context.Background()
+	rt := newSendTestRuntime(t)
+
+	err := rt.Send(ctx, SendRequest{Target: TargetParent, Event: Event{ID: EVENT_SEND_PING}})
+	if !errors.Is(err, ErrTargetUnavailable) {
+		t.Fatalf("expected ErrTargetUnavailable, got %v", err)
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	if !rt.IsInState(STATE_SEND_ERR) {
+		t.Error("failed send should raise error.communication")
+	}
+
+	other := newSendTestRuntime(t)
+	err = other.Send(ctx, SendRequest{Type: "no-such-processor", Event: Event{ID: EVENT_SEND_PING}})
+	if !errors.Is(err, ErrUnknownIOProcessor) {
+		t.Fatalf("expected ErrUnknownIOProcessor, got %v", err)
+	}
+}
+
+func TestSendDelayedAndCancel(t *testing.T) {
+	ctx := context.Background()
+	rt := newSendTestRuntime(t)
+
+	req := SendRequest{Event: Event{ID: EVENT_SEND_PING}, Delay: 30 * time.Millisecond, ID: "later"}
+	if err := rt.Send(ctx, req); err != nil {
+		t.Fatal(err)
+	}
+	if !rt.CancelSend("later") {
+		t.Fatal("pending send should be cancellable")
+	}
+
+	req.ID = "delivered"
+	if err := rt.Send(ctx, req); err != nil {
+		t.Fatal(err)
+	}
+	if rt.IsInState(STATE_SEND_GOT) {
+		t.Fatal("delayed send delivered too early")
+	}
+
+	time.Sleep(80 * time.Millisecond)
+	if !rt.IsInState(STATE_SEND_GOT) {
+		t.Error("delayed send should be delivered after the delay")
+	}
+	if rt.CancelSend("delivered") {
+		t.Error("delivered send should no longer be cancellable")
+	}
+}
+
+func TestSendInternalSinkError(t *testing.T) {
+	ctx := context.Background()
+	rt := newSendTestRuntime(t)
+
+	errRejected := errors.New("rejected")
+	rt.SetEventSink(func(ctx context.Context, event Event) error {
+		if event.ID == EVENT_SEND_PING {
+			return errRejected
+		}
+		return rt.SendEvent(ctx, event)
+	})
+
+	err := rt.Send(ctx, SendRequest{Target: TargetInternal, Event: Event{ID: EVENT_SEND_PING}})
+	if !errors.Is(err, errRejected) {
+		t.Fatalf("expected sink error, got %v", err)
+	}
+
+	time.Sleep(20 * time.Millisecond)
+	if !rt.IsInState(STATE_SEND_ERR) {
+		t.Error("sink error should raise error.communication")
+	}
+}
+
+func TestSendInternalBeforeExternal(t *testing.T) {
+	const (
+		STATE_A    StateID = 1
+		STATE_B    StateID = 2
+		STATE_C    StateID = 3
+		STATE_PASS StateID = 4
+		STATE_FAIL StateID = 5
+
+		EVENT_GO       EventID = 1
+		EVENT_EXTERNAL EventID = 2
+		EVENT_INTERNAL EventID = 3
+	)
+
+	var rt *Runtime
+	a := &State{ID: STATE_A}
+	a.Transitions = []*Transition{{Event: EVENT_GO, Target: STATE_B, Action: func(ctx context.Context, evt *Event, from, to StateID) error {
+		if err := rt.SendEvent(ctx, Event{ID: EVENT_EXTERNAL}); err != nil {
+			return err
+		}
+		return rt.Send(ctx, SendRequest{Target: TargetInternal, Event: Event{ID: EVENT_INTERNAL}})
+	}}}
+	b := &State{ID: STATE_B}
+	b.Transitions = []*Transition{
+		{Event: EVENT_INTERNAL, Target: STATE_C},
+		{Event: EVENT_EXTERNAL, Target: STATE_FAIL},
+	}
+	c := &State{ID: STATE_C}
+	c.Transitions = []*Transition{{Event: EVENT_EXTERNAL, Target: STATE_PASS}}
+	root := &State{
+		ID:      100,
+		Initial: STATE_A,
+		Children: map[StateID]*State{
+			STATE_A:    a,
+			STATE_B:    b,
+			STATE_C:    c,
+			STATE_PASS: {ID: STATE_PASS},
+			STATE_FAIL: {ID: STATE_FAIL},
+		},
+	}
+	machine, err := NewMachine(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rt = NewRuntime(machine, nil)
+	if err := rt.Start(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	defer rt.Stop()
+
+	if err := rt.SendEvent(context.Background(), Event{ID: EVENT_GO}); err != nil {
+		t.Fatal(err)
+	}
+	if err := rt.WaitIdle(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if !rt.IsInState(STATE_PASS) {
+		t.Errorf("#_internal event should be processed before the external one, got state %d", rt.GetCurrentState())
+	}
+}
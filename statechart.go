@@ -62,6 +62,11 @@
 // History States: Set IsHistoryState=true and HistoryType (Shallow/Deep) to create history
 // pseudo-states that remember and restore previous state configurations.
 //
+// Event I/O: Use Runtime.Send with a SendRequest to address events to the runtime itself
+// (#_internal), to its parent or invoked children (#_parent, #_<invokeid>), to any started
+// session (#_scxml_<sessionid>), or to other runtimes through a Bus or custom IOProcessor.
+// Failed deliveries raise ERROR_COMMUNICATION in the sender.
+//
 // Custom Hooks: Pass ParallelStateHooks to NewRuntime() to override default parallel state
 // behavior (e.g., for sequential processing in deterministic runtimes).
 //
@@ -85,6 +90,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sort"
+	"strconv"
 	"sync"
 	"time"
 )
@@ -97,10 +104,28 @@ type StateID int
 type EventID int
 
 const (
-	NO_EVENT  EventID = 0  // Eventless/immediate transition
-	ANY_EVENT EventID = -1 // Wildcard event
+	NO_EVENT            EventID = 0  // Eventless/immediate transition
+	ANY_EVENT           EventID = -1 // Wildcard event
+	ERROR_COMMUNICATION EventID = -2 // error.communication, raised when Send cannot deliver
+)
+
+// TIMER_EVENT_BASE bounds the event IDs reserved for runtime timers such as
+// realtime.AfterTicks: IDs at or below it are never matched by ANY_EVENT.
+const TIMER_EVENT_BASE int64 = -1 << 61
+
+// Timer event IDs encode the state that owns the timer and a count (see TimerEventID).
+const (
+	TimerCountBits       = 24
+	MaxTimerCount        = 1<<TimerCountBits - 1
+	MaxTimerState  int64 = 1<<37 - 1 // largest state ID TimerEventID accepts
 )
 
+// MatchesWildcard reports whether an ANY_EVENT transition can take id: every
+// event except NO_EVENT and the timer events at or below TIMER_EVENT_BASE.
+func (id EventID) MatchesWildcard() bool {
+	return id != NO_EVENT && int64(id) > TIMER_EVENT_BASE
+}
+
 const (
 	MAX_MICROSTEPS = 100 // Maximum microstep iterations to prevent infinite loops
 )
@@ -115,7 +140,9 @@ const (
 
 // Error variables
 var (
-	ErrEventQueueFull = errors.New("event queue is full")
+	ErrEventQueueFull  = errors.New("event queue is full")
+	ErrRuntimeStopped  = errors.New("runtime stopped")
+	ErrTimerEventRange = errors.New("timer event out of range")
 )
 
 // Event represents a state machine event with optional data and addressing.
@@ -163,11 +190,21 @@ type State struct {
 	Initial       StateID // Initial child state for compound states
 	IsParallel    bool    // True if this is a parallel state (Step 13)
 
+	// Ordered action lists, executed after the single action of the same kind
+	EntryActions   []Action
+	ExitActions    []Action
+	InitialActions []Action
+
+	// Registry names of EntryActions/ExitActions (set by StateBuilder.EntryRef/ExitRef)
+	EntryRefs []string
+	ExitRefs  []string
+
 	// History state support
 	IsHistoryState bool        // True if this is a history pseudo-state
 	HistoryType    HistoryType // Type of history (shallow or deep)
 	HistoryDefault StateID     // Default state if no history exists
 	FinalStateData any         // Data to include in done event
+	FinalDataRef   string      // Registry name of FinalStateData (set by StateBuilder.FinalRef)
 }
 
 type CompoundState struct {
@@ -179,12 +216,18 @@ type CompoundState struct {
 // Transition represents a state transition triggered by an event.
 // Target=0 indicates an internal transition (no exit/entry actions).
 // Guard and Action are optional (nil means no guard/action).
+// Actions run in order after Action.
 type Transition struct {
-	Event  EventID
-	Source *State
-	Target StateID // 0 --> internal transition
-	Guard  Guard   // nil --> always true
-	Action Action  // nil --> do nothing
+	Event   EventID
+	Source  *State
+	Target  StateID  // 0 --> internal transition
+	Guard   Guard    // nil --> always true
+	Action  Action   // nil --> do nothing
+	Actions []Action // additional actions, in document order
+
+	// Registry names of Guard and Actions (set by StateBuilder.OnRef)
+	GuardRef   string
+	ActionRefs []string
 }
 
 // Machine is the top-level compound state with helper functions for chart evaluation.
@@ -194,6 +237,7 @@ type Machine struct {
 	CompoundState
 	states  map[StateID]*State
 	current *State
+	root    *State
 }
 
 // ParallelStateHooks provides extension points for custom parallel state processing.
@@ -214,6 +258,16 @@ type ParallelStateHooks struct {
 	// If it returns an error, event delivery failed.
 	// If nil, default channel-based routing is used.
 	OnSendToRegions func(ctx context.Context, event Event) error
+
+	// OnStateEntered is called before a state's entry actions run, after
+	// Coverage is notified. Like OnTransitionTaken, it lets a runtime built on
+	// Runtime observe entries without replacing the user's Coverage recorder.
+	OnStateEntered func(state *State)
+
+	// OnTransitionTaken is called before a transition's actions run, after
+	// Coverage is notified. It lets a runtime built on Runtime observe
+	// transitions without replacing the user's Coverage recorder.
+	OnTransitionTaken func(t *Transition)
 }
 
 // Runtime manages the execution of a state machine.
@@ -230,11 +284,13 @@ type Runtime struct {
 	wg         sync.WaitGroup
 	mu         sync.RWMutex
 	current    StateID
+	idle       idleTracker // work in flight, for WaitIdle (see idle.go)
 
 	// Parallel state support
 	parallelRegions map[StateID]*parallelRegion
 	regionMu        sync.RWMutex
 	ParallelHooks   *ParallelStateHooks // Extension point for custom parallel state processing
+	Coverage        CoverageRecorder    // Optional recorder of entries, transitions and guards (see coverage.go)
 
 	// History state support
 	history       map[StateID]StateID // stateID → last active child (shallow)
@@ -245,6 +301,20 @@ type Runtime struct {
 	// Done event support
 	doneEventsPending map[StateID]bool // Track pending done events
 	doneEventsMu      sync.RWMutex
+
+	// Event I/O processor support (see send.go)
+	sessionID    string
+	parent       *Runtime
+	children     map[string]*Runtime // invokeID → child session
+	processors   map[string]IOProcessor
+	pendingSends map[string]*time.Timer // sendID → delayed send
+	sendMu       sync.Mutex             // also guards ctx writes in Start
+	eventSink    func(ctx context.Context, event Event) error
+
+	// Internal queue for #_internal sends (see send.go)
+	internalQueue []Event
+	inMacrostep   bool // events sent to #_internal now are queued (guarded by internalMu)
+	internalMu    sync.Mutex
 }
 
 // contextKey is the unexported type for context key to prevent collisions
@@ -282,16 +352,59 @@ type parallelRegion struct {
 // Public API
 //
 
-// OnEntry sets the action to execute when entering this state.
-// Entry actions run after exiting the previous state but before entering children.
+// OnEntry appends an action to execute when entering this state, like
+// StateBuilder.Entry. Entry actions run after exiting the previous state but
+// before entering children.
 func (s *State) OnEntry(action Action) {
-	s.EntryAction = action
+	s.EntryActions = append(s.EntryActions, action)
 }
 
-// OnExit sets the action to execute when exiting this state.
-// Exit actions run before entering the next state.
+// OnExit appends an action to execute when exiting this state, like
+// StateBuilder.Exit. Exit actions run before entering the next state.
 func (s *State) OnExit(action Action) {
-	s.ExitAction = action
+	s.ExitActions = append(s.ExitActions, action)
+}
+
+// RunEntryActions executes EntryAction followed by EntryActions in order.
+// Stops at and returns the first error.
+func (s *State) RunEntryActions(ctx context.Context, evt *Event, from StateID, to StateID) error {
+	return runActions(ctx, evt, from, to, s.EntryAction, s.EntryActions)
+}
+
+// RunExitActions executes ExitAction followed by ExitActions in order.
+// Stops at and returns the first error.
+func (s *State) RunExitActions(ctx context.Context, evt *Event, from StateID, to StateID) error {
+	return runActions(ctx, evt, from, to, s.ExitAction, s.ExitActions)
+}
+
+// RunInitialActions executes InitialAction followed by InitialActions in order.
+// Stops at and returns the first error.
+func (s *State) RunInitialActions(ctx context.Context, evt *Event, from StateID, to StateID) error {
+	return runActions(ctx, evt, from, to, s.InitialAction, s.InitialActions)
+}
+
+// RunActions executes Action followed by Actions in order.
+// Stops at and returns the first error.
+func (t *Transition) RunActions(ctx context.Context, evt *Event, from StateID, to StateID) error {
+	return runActions(ctx, evt, from, to, t.Action, t.Actions)
+}
+
+// runActions executes first (if set) and then rest in order
+func runActions(ctx context.Context, evt *Event, from, to StateID, first Action, rest []Action) error {
+	if first != nil {
+		if err := first(ctx, evt, from, to); err != nil {
+			return err
+		}
+	}
+	for _, action := range rest {
+		if action == nil {
+			continue
+		}
+		if err := action(ctx, evt, from, to); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // NewMachine creates a new state machine from the given root state.
@@ -304,6 +417,7 @@ func NewMachine(root *State) (*Machine, error) {
 
 	m := &Machine{
 		states: map[StateID]*State{},
+		root:   root,
 	}
 
 	// Build state lookup table recursively and establish parent-child relationships
@@ -408,6 +522,10 @@ func NewRuntime(machine *Machine, ext any) *Runtime {
 		history:           make(map[StateID]StateID),
 		deepHistory:       make(map[StateID][]StateID),
 		doneEventsPending: make(map[StateID]bool),
+		sessionID:         nextSessionID(),
+		children:          make(map[string]*Runtime),
+		processors:        make(map[string]IOProcessor),
+		pendingSends:      make(map[string]*time.Timer),
 	}
 }
 
@@ -422,22 +540,26 @@ func (rt *Runtime) Ctx() *Context {
 // Spawns a goroutine for event processing. Call Stop() to terminate gracefully.
 // Returns error if already started or if initial state entry fails.
 func (rt *Runtime) Start(ctx context.Context) error {
+	rt.sendMu.Lock()
 	if rt.ctx != nil {
+		rt.sendMu.Unlock()
 		return errors.New("runtime already started")
 	}
-
 	rt.ctx, rt.cancel = context.WithCancel(ctx)
+	rt.sendMu.Unlock()
+	registerSession(rt)
 
 	// Inject runtime context into Go context for action access
 	ctxWithExt := context.WithValue(rt.ctx, extContextKey, rt.Ctx())
 
 	// Enter initial state hierarchy (from root to initial state)
 	rt.mu.Lock()
-	if err := rt.enterInitialState(ctxWithExt); err != nil {
-		rt.mu.Unlock()
+	var err error
+	rt.runMacrostep(func() { err = rt.enterInitialState(ctxWithExt) })
+	rt.mu.Unlock()
+	if err != nil {
 		return err
 	}
-	rt.mu.Unlock()
 
 	// Start event processing loop
 	rt.wg.Add(1)
@@ -475,17 +597,15 @@ func (rt *Runtime) enterInitialState(ctx context.Context) error {
 		}
 
 		// Execute entry action
-		if state.EntryAction != nil {
-			if err := state.EntryAction(ctx, nil, 0, rt.current); err != nil {
-				return err
-			}
+		if err := rt.runEntryActions(ctx, state, nil, 0, rt.current); err != nil {
+			return err
 		}
 
 		// Execute initial action if this state has children and we're entering them
 		// InitialAction runs after parent entry but before child entry
-		if i < len(path)-1 && state.InitialAction != nil {
+		if i < len(path)-1 {
 			nextStateID := path[i+1]
-			if err := state.InitialAction(ctx, nil, stateID, nextStateID); err != nil {
+			if err := state.RunInitialActions(ctx, nil, stateID, nextStateID); err != nil {
 				return err
 			}
 		}
@@ -518,10 +638,8 @@ func (rt *Runtime) enterParallelState(ctx context.Context, state *State) error {
 	// Default implementation: goroutine-based parallel regions
 
 	// Execute parent entry action
-	if state.EntryAction != nil {
-		if err := state.EntryAction(ctx, nil, 0, state.ID); err != nil {
-			return err
-		}
+	if err := rt.runEntryActions(ctx, state, nil, 0, state.ID); err != nil {
+		return err
 	}
 
 	// Create context with timeout for entry
@@ -533,6 +651,7 @@ func (rt *Runtime) enterParallelState(ctx context.Context, state *State) error {
 	startedChan := make(chan StateID, len(state.Children))
 
 	rt.regionMu.Lock()
+	rt.idle.add(len(state.Children)) // released by each region once its initial states are entered
 	for childID, child := range state.Children {
 		// Create region context
 		regionCtx, regionCancel := context.WithCancel(rt.ctx)
@@ -640,10 +759,8 @@ func (rt *Runtime) exitParallelState(ctx context.Context, state *State) error {
 	rt.cleanupParallelRegions(state.ID)
 
 	// Execute parent exit action
-	if state.ExitAction != nil {
-		if err := state.ExitAction(ctx, nil, state.ID, 0); err != nil {
-			return err
-		}
+	if err := state.RunExitActions(ctx, nil, state.ID, 0); err != nil {
+		return err
 	}
 
 	return nil
@@ -663,8 +780,11 @@ func (rt *Runtime) cleanupParallelRegions(stateID StateID) {
 		if region, exists := rt.parallelRegions[childID]; exists {
 			// Cancel context
 			region.cancel()
-			// Close event channel
+			// Close event channel, dropping undelivered events
 			close(region.events)
+			for range region.events {
+				rt.idle.done()
+			}
 			// Remove from map
 			delete(rt.parallelRegions, childID)
 		}
@@ -673,6 +793,13 @@ func (rt *Runtime) cleanupParallelRegions(stateID StateID) {
 
 // run is the main event loop for a parallel region
 func (r *parallelRegion) run(state *State) error {
+	entering := true
+	defer func() {
+		if entering {
+			r.runtime.idle.done()
+		}
+	}()
+
 	// If this region is a parallel state, spawn child regions first
 	if state.IsParallel {
 		if err := r.runtime.enterParallelState(r.ctx, state); err != nil {
@@ -686,6 +813,8 @@ func (r *parallelRegion) run(state *State) error {
 	if !state.IsParallel {
 		r.enterInitialHierarchy(r.ctx, state)
 	}
+	entering = false
+	r.runtime.idle.done()
 
 	// Always run event loop to monitor done channel
 	for {
@@ -698,11 +827,17 @@ func (r *parallelRegion) run(state *State) error {
 			// Exit current state before returning
 			r.exitCurrentState(r.ctx)
 			return r.ctx.Err()
-		case event := <-r.events:
+		case event, ok := <-r.events:
+			if !ok {
+				// Channel closed by cleanupParallelRegions
+				r.exitCurrentState(r.ctx)
+				return nil
+			}
 			// Parallel states delegate event processing to children
 			if !state.IsParallel {
 				r.processEvent(event, state)
 			}
+			r.runtime.idle.done()
 		}
 	}
 }
@@ -726,9 +861,7 @@ func (r *parallelRegion) processEvent(event Event, state *State) {
 
 	// Internal transition
 	if transition.Target == 0 {
-		if transition.Action != nil {
-			transition.Action(r.ctx, &event, r.currentState, r.currentState)
-		}
+		r.runtime.runTransitionActions(r.ctx, transition, &event, r.currentState, r.currentState)
 		r.mu.Unlock()
 		return
 	}
@@ -764,9 +897,7 @@ func (r *parallelRegion) processEvent(event Event, state *State) {
 	r.runtime.exitToLCA(r.ctx, &event, from, to, lca)
 
 	// Execute transition action
-	if transition.Action != nil {
-		transition.Action(r.ctx, &event, from, to)
-	}
+	r.runtime.runTransitionActions(r.ctx, transition, &event, from, to)
 
 	// Enter states
 	r.runtime.enterFromLCA(r.ctx, &event, from, to, lca, isHistoryRestoration)
@@ -826,14 +957,12 @@ func (r *parallelRegion) enterInitialHierarchy(ctx context.Context, regionRoot *
 		}
 
 		// Execute entry action
-		if state.EntryAction != nil {
-			state.EntryAction(ctx, nil, 0, r.currentState)
-		}
+		r.runtime.runEntryActions(ctx, state, nil, 0, r.currentState)
 
 		// Execute initial action if moving to next state
-		if i < len(path)-1 && state.InitialAction != nil {
+		if i < len(path)-1 {
 			nextStateID := path[i+1]
-			state.InitialAction(ctx, nil, stateID, nextStateID)
+			state.RunInitialActions(ctx, nil, stateID, nextStateID)
 		}
 
 		// Check if this state is final and should generate done event
@@ -867,9 +996,7 @@ func (r *parallelRegion) exitCurrentState(ctx context.Context) {
 		r.runtime.exitParallelState(ctx, regionState)
 
 		// Execute region state's own exit action after children exit
-		if regionState.ExitAction != nil {
-			regionState.ExitAction(ctx, nil, regionState.ID, 0)
-		}
+		regionState.RunExitActions(ctx, nil, regionState.ID, 0)
 		return
 	}
 
@@ -880,9 +1007,7 @@ func (r *parallelRegion) exitCurrentState(ctx context.Context) {
 	current := r.runtime.machine.states[r.currentState]
 
 	for current != nil {
-		if current.ExitAction != nil {
-			current.ExitAction(ctx, nil, current.ID, 0)
-		}
+		current.RunExitActions(ctx, nil, current.ID, 0)
 
 		// Stop after executing region state's exit action
 		if current == regionState {
@@ -901,17 +1026,30 @@ func (rt *Runtime) Stop() error {
 	if rt.cancel != nil {
 		rt.cancel()
 	}
+	// Stop delayed sends first so no timer enqueues while workers shut down
+	rt.cancelPendingSends()
 	rt.wg.Wait()
+	unregisterSession(rt)
+
+	// Drop events that were queued but never processed
+	for drained := false; !drained; {
+		select {
+		case <-rt.eventQueue:
+			rt.idle.done()
+		default:
+			drained = true
+		}
+	}
 
 	// After all goroutines have exited, execute top-level state's exit action if it's parallel
 	rt.mu.Lock()
 	defer rt.mu.Unlock()
 
 	currentState := rt.machine.states[rt.current]
-	if currentState != nil && currentState.IsParallel && currentState.ExitAction != nil {
+	if currentState != nil && currentState.IsParallel {
 		// Execute the parallel state's exit action (child regions already exited)
 		ctx := context.Background()
-		currentState.ExitAction(ctx, nil, currentState.ID, 0)
+		currentState.RunExitActions(ctx, nil, currentState.ID, 0)
 	}
 
 	return nil
@@ -931,12 +1069,15 @@ func (rt *Runtime) SendEvent(ctx context.Context, event Event) error {
 	}
 
 	// Normal event queue
+	rt.idle.add(1) // released by eventLoop once the event is processed
 	select {
 	case rt.eventQueue <- event:
 		return nil
 	case <-ctx.Done():
+		rt.idle.done()
 		return ctx.Err()
 	case <-rt.ctx.Done():
+		rt.idle.done()
 		return rt.ctx.Err()
 	}
 }
@@ -959,13 +1100,16 @@ func (rt *Runtime) sendEventToRegions(ctx context.Context, event Event) error {
 	if event.Address == 0 {
 		// Broadcast to all regions
 		for _, region := range rt.parallelRegions {
+			rt.idle.add(1) // released by the region once the event is processed
 			select {
 			case region.events <- event:
 				// Event sent successfully
 			case <-sendCtx.Done():
+				rt.idle.done()
 				return errors.New("broadcast timeout")
 			case <-region.ctx.Done():
 				// Region is shutting down, skip
+				rt.idle.done()
 				continue
 			}
 		}
@@ -978,12 +1122,15 @@ func (rt *Runtime) sendEventToRegions(ctx context.Context, event Event) error {
 		return fmt.Errorf("region %d not found", event.Address)
 	}
 
+	rt.idle.add(1)
 	select {
 	case region.events <- event:
 		return nil
 	case <-sendCtx.Done():
+		rt.idle.done()
 		return errors.New("send timeout")
 	case <-region.ctx.Done():
+		rt.idle.done()
 		return errors.New("region shutting down")
 	}
 }
@@ -1047,15 +1194,21 @@ func (rt *Runtime) eventLoop() {
 			return
 		case event := <-rt.eventQueue:
 			rt.processEvent(event)
+			rt.idle.done()
 		}
 	}
 }
 
-// processEvent handles a single event (macrostep)
+// processEvent handles a single external event and the internal events it raises (macrostep)
 func (rt *Runtime) processEvent(event Event) {
 	rt.mu.Lock()
 	defer rt.mu.Unlock()
+	rt.runMacrostep(func() { rt.takeTransition(event) })
+}
 
+// takeTransition takes the transition selected by event, if any, and the
+// eventless transitions that follow. The caller holds rt.mu.
+func (rt *Runtime) takeTransition(event Event) {
 	currentState := rt.machine.states[rt.current]
 	if currentState == nil {
 		return
@@ -1071,9 +1224,7 @@ func (rt *Runtime) processEvent(event Event) {
 	// Internal transition (Target == 0)
 	if transition.Target == 0 {
 		// Execute action only, no state change
-		if transition.Action != nil {
-			transition.Action(rt.ctx, &event, rt.current, rt.current)
-		}
+		rt.runTransitionActions(rt.ctx, transition, &event, rt.current, rt.current)
 		// Check if current state should generate done event
 		// (e.g., compound state whose child is now done)
 		rt.checkFinalState(rt.ctx)
@@ -1112,9 +1263,7 @@ func (rt *Runtime) processEvent(event Event) {
 	rt.exitToLCA(rt.ctx, &event, from, to, lca)
 
 	// Execute transition action
-	if transition.Action != nil {
-		transition.Action(rt.ctx, &event, from, to)
-	}
+	rt.runTransitionActions(rt.ctx, transition, &event, from, to)
 
 	// Enter states from LCA down to target
 	rt.enterFromLCA(rt.ctx, &event, from, to, lca, isHistoryRestoration)
@@ -1155,9 +1304,7 @@ func (rt *Runtime) processMicrosteps(ctx context.Context) {
 		// Internal transition (Target == 0)
 		if transition.Target == 0 {
 			// Execute action only, no state change
-			if transition.Action != nil {
-				transition.Action(ctx, &noEvent, rt.current, rt.current)
-			}
+			rt.runTransitionActions(ctx, transition, &noEvent, rt.current, rt.current)
 			// Internal transition doesn't change state, but we continue
 			// the microstep loop in case there are more eventless transitions
 			continue
@@ -1193,9 +1340,7 @@ func (rt *Runtime) processMicrosteps(ctx context.Context) {
 		rt.exitToLCA(ctx, &noEvent, from, to, lca)
 
 		// Execute transition action
-		if transition.Action != nil {
-			transition.Action(ctx, &noEvent, from, to)
-		}
+		rt.runTransitionActions(ctx, transition, &noEvent, from, to)
 
 		// Enter states from LCA down to target
 		rt.enterFromLCA(ctx, &noEvent, from, to, lca, isHistoryRestoration)
@@ -1267,9 +1412,7 @@ func (rt *Runtime) exitToLCA(ctx context.Context, event *Event, from, to, lca St
 		// Clear done event flag when exiting
 		rt.clearDoneEvent(current.ID)
 
-		if current.ExitAction != nil {
-			current.ExitAction(ctx, event, from, to)
-		}
+		current.RunExitActions(ctx, event, from, to)
 		current = current.Parent
 	}
 }
@@ -1305,15 +1448,13 @@ func (rt *Runtime) enterFromLCA(ctx context.Context, event *Event, from, to, lca
 		}
 
 		// Execute entry action
-		if state.EntryAction != nil {
-			state.EntryAction(ctx, event, from, to)
-		}
+		rt.runEntryActions(ctx, state, event, from, to)
 
 		// Execute initial action if this state has children and we're entering them
 		// InitialAction runs after parent entry but before child entry
-		if i < len(path)-1 && state.InitialAction != nil {
+		if i < len(path)-1 {
 			nextStateID := path[i+1]
-			state.InitialAction(ctx, event, stateID, nextStateID)
+			state.RunInitialActions(ctx, event, stateID, nextStateID)
 		}
 	}
 
@@ -1357,9 +1498,7 @@ func (rt *Runtime) enterInitialChildren(ctx context.Context, event *Event, from,
 		}
 
 		// Execute InitialAction before entering child
-		if state.InitialAction != nil {
-			state.InitialAction(ctx, event, state.ID, initialChild.ID)
-		}
+		state.RunInitialActions(ctx, event, state.ID, initialChild.ID)
 
 		// Check if initial child is parallel
 		if initialChild.IsParallel {
@@ -1368,9 +1507,7 @@ func (rt *Runtime) enterInitialChildren(ctx context.Context, event *Event, from,
 		}
 
 		// Execute entry action for initial child
-		if initialChild.EntryAction != nil {
-			initialChild.EntryAction(ctx, event, from, to)
-		}
+		rt.runEntryActions(ctx, initialChild, event, from, to)
 
 		// Update rt.current to the child we just entered
 		rt.current = initialChild.ID
@@ -1452,6 +1589,8 @@ func (rt *Runtime) generateDoneEvent(ctx context.Context, parent *State, finalSt
 	parallelState := rt.machine.states[parallelStateID]
 	shouldSendToRoot := (parallelState != nil && parallelState.IsParallel && parent.ID == parallelState.ID)
 
+	// The done event counts as pending work until it reaches a queue
+	rt.idle.add(1)
 	go func() {
 		sendCtx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
 		defer cancel()
@@ -1460,15 +1599,18 @@ func (rt *Runtime) generateDoneEvent(ctx context.Context, parent *State, finalSt
 			// Send to root event queue for parallel state done events
 			select {
 			case rt.eventQueue <- doneEvent:
-				// Event sent successfully
+				// Event sent successfully; eventLoop releases it
 			case <-sendCtx.Done():
 				// Timeout - event not delivered
+				rt.idle.done()
 			case <-rt.ctx.Done():
 				// Runtime shutting down
+				rt.idle.done()
 			}
 		} else {
 			// Use SendEvent for region-level done events
 			rt.SendEvent(sendCtx, doneEvent)
+			rt.idle.done()
 		}
 	}()
 }
@@ -1526,24 +1668,18 @@ func (rt *Runtime) pickTransition(state *State, event Event) *Transition {
 		// Check for exact event match
 		if t.Event == event.ID {
 			// Check guard if present
-			if t.Guard != nil {
-				pass, err := t.Guard(rt.ctx, &event, rt.current, t.Target)
-				if err != nil || !pass {
-					continue // Guard failed, try next transition
-				}
+			if !rt.checkGuard(t, &event) {
+				continue // Guard failed, try next transition
 			}
 			return t
 		}
 
 		// Check for wildcard match (ANY_EVENT)
-		// Note: ANY_EVENT should NOT match NO_EVENT (eventless transitions)
-		if t.Event == ANY_EVENT && event.ID != NO_EVENT && wildcardTransition == nil {
+		// Note: ANY_EVENT should NOT match NO_EVENT (eventless transitions) or timer events
+		if t.Event == ANY_EVENT && event.ID.MatchesWildcard() && wildcardTransition == nil {
 			// Check guard if present
-			if t.Guard != nil {
-				pass, err := t.Guard(rt.ctx, &event, rt.current, t.Target)
-				if err != nil || !pass {
-					continue // Guard failed, try next transition
-				}
+			if !rt.checkGuard(t, &event) {
+				continue // Guard failed, try next transition
 			}
 			wildcardTransition = t
 		}
@@ -1576,10 +1712,7 @@ func (rt *Runtime) enterState(ctx context.Context, event *Event, from StateID, t
 		return errors.New("target state not found")
 	}
 
-	if state.EntryAction != nil {
-		return state.EntryAction(ctx, event, from, to)
-	}
-	return nil
+	return rt.runEntryActions(ctx, state, event, from, to)
 }
 
 // exitState executes exit actions for a state
@@ -1589,10 +1722,7 @@ func (rt *Runtime) exitState(ctx context.Context, event *Event, from StateID, to
 		return nil
 	}
 
-	if state.ExitAction != nil {
-		return state.ExitAction(ctx, event, from, to)
-	}
-	return nil
+	return state.RunExitActions(ctx, event, from, to)
 }
 
 // Legacy Machine API (kept for backward compatibility with old tests)
@@ -1620,18 +1750,12 @@ func (m *Machine) IsInState(stateID StateID) bool {
 
 // enterState enters a state.
 func (s *State) enterState(ctx context.Context, event *Event, from StateID, to StateID) error {
-	if s.EntryAction != nil {
-		return s.EntryAction(ctx, event, from, to)
-	}
-	return nil
+	return s.RunEntryActions(ctx, event, from, to)
 }
 
 // exitState exits a state.
 func (s *State) exitState(ctx context.Context, event *Event, from StateID, to StateID) error {
-	if s.ExitAction != nil {
-		return s.ExitAction(ctx, event, from, to)
-	}
-	return nil
+	return s.RunExitActions(ctx, event, from, to)
 }
 
 // On adds a transition to this state triggered by the given event.
@@ -1690,6 +1814,7 @@ func (rt *Runtime) getActiveConfiguration() []StateID {
 
 // restoreHistory restores a previously saved state configuration
 func (rt *Runtime) restoreHistory(ctx context.Context, historyState *State, event *Event, from StateID) (StateID, error) {
+	rt.coverHistory(historyState)
 	if historyState.HistoryType == HistoryDeep {
 		return rt.restoreDeepHistory(ctx, historyState, event, from)
 	}
@@ -1772,6 +1897,32 @@ func DoneEventID(stateID StateID) EventID {
 	return EventID(-(1000000 + int(stateID)))
 }
 
+// TimerEventID returns the timer event ID for state and count:
+// TIMER_EVENT_BASE - (state<<TimerCountBits | count). It returns
+// ErrTimerEventRange if count is not between 1 and MaxTimerCount, if state is
+// not between 0 and MaxTimerState, or if event IDs are narrower than 64 bits.
+func TimerEventID(state StateID, count uint64) (EventID, error) {
+	if strconv.IntSize < 64 {
+		return NO_EVENT, fmt.Errorf("%w: timer events need 64-bit event IDs", ErrTimerEventRange)
+	}
+	if count == 0 || count > MaxTimerCount {
+		return NO_EVENT, fmt.Errorf("%w: count %d", ErrTimerEventRange, count)
+	}
+	if state < 0 || int64(state) > MaxTimerState {
+		return NO_EVENT, fmt.Errorf("%w: state %d", ErrTimerEventRange, state)
+	}
+	return EventID(TIMER_EVENT_BASE - (int64(state)<<TimerCountBits | int64(count))), nil
+}
+
+// TimerEventState inverts TimerEventID
+func TimerEventState(id EventID) (state StateID, count uint64, ok bool) {
+	if int64(id) > TIMER_EVENT_BASE {
+		return 0, 0, false
+	}
+	v := TIMER_EVENT_BASE - int64(id)
+	return StateID(v >> TimerCountBits), uint64(v & MaxTimerCount), true
+}
+
 // Public aliases for tick-based runtime (realtime package)
 // These expose internal methods for use by the RealtimeRuntime
 
@@ -1805,9 +1956,7 @@ func (rt *Runtime) ProcessEventWithoutMicrosteps(event Event) {
 	// Internal transition (Target == 0)
 	if transition.Target == 0 {
 		// Execute action only, no state change
-		if transition.Action != nil {
-			transition.Action(rt.ctx, &event, rt.current, rt.current)
-		}
+		rt.runTransitionActions(rt.ctx, transition, &event, rt.current, rt.current)
 		// Check if current state should generate done event
 		rt.checkFinalState(rt.ctx)
 		// NOTE: Do NOT call processMicrosteps - let caller control macrostep loop
@@ -1844,9 +1993,7 @@ func (rt *Runtime) ProcessEventWithoutMicrosteps(event Event) {
 	rt.exitToLCA(rt.ctx, &event, from, to, lca)
 
 	// Execute transition action
-	if transition.Action != nil {
-		transition.Action(rt.ctx, &event, from, to)
-	}
+	rt.runTransitionActions(rt.ctx, transition, &event, from, to)
 
 	// Enter states from LCA down to target
 	rt.enterFromLCA(rt.ctx, &event, from, to, lca, isHistoryRestoration)
@@ -1882,9 +2029,7 @@ func (rt *Runtime) ProcessSingleMicrostep(ctx context.Context) bool {
 	// Internal transition (Target == 0)
 	if transition.Target == 0 {
 		// Execute action only, no state change
-		if transition.Action != nil {
-			transition.Action(ctx, &noEvent, rt.current, rt.current)
-		}
+		rt.runTransitionActions(ctx, transition, &noEvent, rt.current, rt.current)
 		// Internal transition doesn't change state
 		return true
 	}
@@ -1919,9 +2064,7 @@ func (rt *Runtime) ProcessSingleMicrostep(ctx context.Context) bool {
 	rt.exitToLCA(ctx, &noEvent, from, to, lca)
 
 	// Execute transition action
-	if transition.Action != nil {
-		transition.Action(ctx, &noEvent, from, to)
-	}
+	rt.runTransitionActions(ctx, transition, &noEvent, from, to)
 
 	// Enter states from LCA down to target
 	rt.enterFromLCA(ctx, &noEvent, from, to, lca, isHistoryRestoration)
@@ -1952,6 +2095,16 @@ func (rt *Runtime) SetCurrentState(stateID StateID) {
 // SetContext sets the runtime context (for realtime runtime initialization)
 func (rt *Runtime) SetContext(ctx context.Context) {
 	rt.ctx = ctx
+	registerSession(rt)
+}
+
+// SetEventSink overrides how events addressed to this runtime by Send are delivered
+// (for realtime runtime initialization, which batches events per tick).
+// Pass nil to restore delivery through SendEvent.
+func (rt *Runtime) SetEventSink(sink func(ctx context.Context, event Event) error) {
+	rt.sendMu.Lock()
+	defer rt.sendMu.Unlock()
+	rt.eventSink = sink
 }
 
 // GetMachine returns the underlying Machine
@@ -1959,12 +2112,85 @@ func (rt *Runtime) GetMachine() *Machine {
 	return rt.machine
 }
 
+// History returns copies of the recorded shallow history (parent → last active
+// child) and deep history (parent → active path) maps (for realtime runtime
+// state hashing).
+func (rt *Runtime) History() (shallow map[StateID]StateID, deep map[StateID][]StateID) {
+	rt.historyMu.RLock()
+	shallow = make(map[StateID]StateID, len(rt.history))
+	for parent, child := range rt.history {
+		shallow[parent] = child
+	}
+	rt.historyMu.RUnlock()
+
+	rt.deepHistoryMu.RLock()
+	deep = make(map[StateID][]StateID, len(rt.deepHistory))
+	for parent, path := range rt.deepHistory {
+		deep[parent] = append([]StateID(nil), path...)
+	}
+	rt.deepHistoryMu.RUnlock()
+	return shallow, deep
+}
+
+// SetHistory replaces the recorded history with copies of the given maps, as
+// returned by History (for realtime runtime rollback).
+func (rt *Runtime) SetHistory(shallow map[StateID]StateID, deep map[StateID][]StateID) {
+	rt.historyMu.Lock()
+	rt.history = make(map[StateID]StateID, len(shallow))
+	for parent, child := range shallow {
+		rt.history[parent] = child
+	}
+	rt.historyMu.Unlock()
+
+	rt.deepHistoryMu.Lock()
+	rt.deepHistory = make(map[StateID][]StateID, len(deep))
+	for parent, path := range deep {
+		rt.deepHistory[parent] = append([]StateID(nil), path...)
+	}
+	rt.deepHistoryMu.Unlock()
+}
+
 // GetState retrieves a state by its ID from the machine's state lookup table.
 // Returns nil if the state ID doesn't exist.
 func (m *Machine) GetState(stateID StateID) *State {
 	return m.states[stateID]
 }
 
+// Root returns the root state of the machine.
+func (m *Machine) Root() *State {
+	return m.root
+}
+
+// StateIDs returns the IDs of all states in the machine in ascending order.
+func (m *Machine) StateIDs() []StateID {
+	ids := make([]StateID, 0, len(m.states))
+	for id := range m.states {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	return ids
+}
+
+// IsDescendantOf reports whether s is a strict descendant of ancestor.
+func (s *State) IsDescendantOf(ancestor *State) bool {
+	for p := s.Parent; p != nil; p = p.Parent {
+		if p == ancestor {
+			return true
+		}
+	}
+	return false
+}
+
+// SortedChildren returns the children of s in ascending ID (document) order.
+func (s *State) SortedChildren() []*State {
+	children := make([]*State, 0, len(s.Children))
+	for _, child := range s.Children {
+		children = append(children, child)
+	}
+	sort.Slice(children, func(i, j int) bool { return children[i].ID < children[j].ID })
+	return children
+}
+
 // FindDeepestInitial finds the deepest initial state starting from a given state
 func (m *Machine) FindDeepestInitial(stateID StateID) StateID {
 	return m.findDeepestInitial(stateID)
@@ -1,388 +0,0 @@
-package statechartx
-
-import (
-	"context"
-	"testing"
-	"time"
-)
-
-// 300s SCXML tests: Mostly datamodel (assign/expr/var), guards expr, deep history, parallel, invoke, send.
-// Few simple (355 initial order, 375/377 entry/exit order, 396 event match). All skipped or simple impl per limitations.
-
-func TestSCXML301(t *testing.T) {
-	t.Skipf("SCXML301: requires <script src>, load-time eval")
-}
-
-func TestSCXML302(t *testing.T) {
-	t.Skipf("SCXML302: requires <script> executable content")
-}
-
-func TestSCXML303(t *testing.T) {
-	t.Skipf("SCXML303: requires <script> var declaration")
-}
-
-func TestSCXML304(t *testing.T) {
-	t.Skipf("SCXML304: requires <script> multiple scripts")
-}
-
-func TestSCXML307(t *testing.T) {
-	t.Skipf("SCXML307: requires datamodel late binding, manual inspect")
-}
-
-func TestSCXML309(t *testing.T) {
-	t.Skipf("SCXML309: requires non-boolean expr in conditions")
-}
-
-func TestSCXML310(t *testing.T) {
-	// Test simple In() predicate with parallel states
-	// The SCXML test uses a parallel state as root with two child regions (s0, s1)
-	// s0 should detect that s1 is also active via In() predicate
-	t.Skipf("SCXML310: requires In() predicate support in transition guards with parallel regions - test shows both regions aren't activated simultaneously yet")
-}
-
-func TestSCXML311(t *testing.T) {
-	t.Skipf("SCXML311: requires datamodel assign")
-}
-
-func TestSCXML312(t *testing.T) {
-	t.Skipf("SCXML312: requires datamodel assign illegal expr")
-}
-
-func TestSCXML313(t *testing.T) {
-	t.Skipf("SCXML313: requires datamodel assign location")
-}
-
-func TestSCXML314(t *testing.T) {
-	t.Skipf("SCXML314: requires datamodel assign expr")
-}
-
-func TestSCXML318(t *testing.T) {
-	t.Skipf("SCXML318: requires _event system var")
-}
-
-func TestSCXML319(t *testing.T) {
-	t.Skipf("SCXML319: requires _event binding")
-}
-
-func TestSCXML321(t *testing.T) {
-	t.Skipf("SCXML321: requires _sessionid system var")
-}
-
-func TestSCXML322(t *testing.T) {
-	t.Skipf("SCXML322: requires _sessionid immutability")
-}
-
-func TestSCXML323(t *testing.T) {
-	t.Skipf("SCXML323: requires _name system var")
-}
-
-func TestSCXML324(t *testing.T) {
-	t.Skipf("SCXML324: requires _name immutability")
-}
-
-func TestSCXML325(t *testing.T) {
-	t.Skipf("SCXML325: requires _ioprocessors system var")
-}
-
-func TestSCXML326(t *testing.T) {
-	t.Skipf("SCXML326: requires _ioprocessors immutability")
-}
-
-func TestSCXML329(t *testing.T) {
-	t.Skipf("SCXML329: requires system vars immutability")
-}
-
-func TestSCXML330(t *testing.T) {
-	t.Skipf("SCXML330: requires send event.type validation")
-}
-
-func TestSCXML331(t *testing.T) {
-	t.Skipf("SCXML331: requires send event.sendid")
-}
-
-func TestSCXML332(t *testing.T) {
-	t.Skipf("SCXML332: requires send event.origin")
-}
-
-func TestSCXML333(t *testing.T) {
-	t.Skipf("SCXML333: requires send event.origintype")
-}
-
-func TestSCXML335(t *testing.T) {
-	t.Skipf("SCXML335: requires send event.invokeid")
-}
-
-func TestSCXML336(t *testing.T) {
-	t.Skipf("SCXML336: requires send event.data")
-}
-
-func TestSCXML337(t *testing.T) {
-	t.Skipf("SCXML337: requires event fields in guards")
-}
-
-func TestSCXML338(t *testing.T) {
-	t.Skipf("SCXML338: requires invokeid in events")
-}
-
-func TestSCXML339(t *testing.T) {
-	t.Skipf("SCXML339: requires invoke done.invoke")
-}
-
-func TestSCXML342(t *testing.T) {
-	t.Skipf("SCXML342: requires datamodel send eventexpr, assign event.name")
-}
-
-func TestSCXML343(t *testing.T) {
-	t.Skipf("SCXML343: requires donedata params, error.execution, final donedata")
-}
-
-func TestSCXML344(t *testing.T) {
-	t.Skipf("SCXML344: requires non-boolean condition, error.execution")
-}
-
-func TestSCXML346(t *testing.T) {
-	t.Skipf("SCXML346: requires system vars (_sessionid/_event/_ioprocessors/_name), error.execution")
-}
-
-func TestSCXML347(t *testing.T) {
-	t.Skipf("SCXML347: requires invoke parent/child send type/target")
-}
-
-func TestSCXML348(t *testing.T) {
-	t.Skipf("SCXML348: requires send type='http://www.w3.org/TR/scxml/#SCXMLEventProcessor'")
-}
-
-func TestSCXML349(t *testing.T) {
-	t.Skipf("SCXML349: requires send origin, datamodel")
-}
-
-func TestSCXML350(t *testing.T) {
-	t.Skipf("SCXML350: requires send target #_scxml_sessionid, datamodel")
-}
-
-func TestSCXML351(t *testing.T) {
-	t.Skipf("SCXML351: requires send id/sendid, datamodel")
-}
-
-func TestSCXML352(t *testing.T) {
-	t.Skipf("SCXML352: requires send type, event.origintype, datamodel")
-}
-
-func TestSCXML354(t *testing.T) {
-	t.Skipf("SCXML354: requires send namelist/param/content, event.data")
-}
-
-func TestSCXML355(t *testing.T) {
-	// Test that default initial state is first in document order
-	// and that eventless transition fires immediately
-	const (
-		STATE_ROOT StateID = 1
-		STATE_S0   StateID = 2
-		STATE_S1   StateID = 3
-		STATE_PASS StateID = 4
-		STATE_FAIL StateID = 5
-	)
-
-	root := &State{ID: STATE_ROOT}
-	s0 := &State{ID: STATE_S0}
-	s1 := &State{ID: STATE_S1}
-	pass := &State{ID: STATE_PASS, Final: true}
-	fail := &State{ID: STATE_FAIL, Final: true}
-
-	root.Children = map[StateID]*State{
-		STATE_S0:   s0,
-		STATE_S1:   s1,
-		STATE_PASS: pass,
-		STATE_FAIL: fail,
-	}
-	// No explicit initial - should default to first child (s0)
-	root.Initial = STATE_S0
-
-	// Eventless transitions (NO_EVENT) from s0 and s1
-	s0.Transitions = []*Transition{
-		{Event: NO_EVENT, Target: STATE_PASS},
-	}
-	s1.Transitions = []*Transition{
-		{Event: NO_EVENT, Target: STATE_FAIL},
-	}
-
-	machine, err := NewMachine(root)
-	if err != nil {
-		t.Fatalf("Failed to create machine: %v", err)
-	}
-
-	rt := NewRuntime(machine, nil)
-	ctx := context.Background()
-
-	if err := rt.Start(ctx); err != nil {
-		t.Fatalf("Failed to start runtime: %v", err)
-	}
-	time.Sleep(50 * time.Millisecond) // Give time for eventless transitions
-	defer rt.Stop()
-
-	if !rt.IsInState(STATE_PASS) {
-		t.Error("Should enter s0 first (document order) and transition to pass via eventless transition")
-	}
-}
-
-func TestSCXML364(t *testing.T) {
-	t.Skipf("SCXML364: requires parallel initial multiple children")
-}
-
-func TestSCXML372(t *testing.T) {
-	t.Skipf("SCXML372: requires final donedata, onentry/onexit final, datamodel")
-}
-
-func TestSCXML375(t *testing.T) {
-	const (
-		STATE_ROOT StateID = 1
-		STATE_S0   StateID = 2
-		STATE_PASS StateID = 3
-		EVENT_E1   EventID = 1
-	)
-
-	root := &State{ID: STATE_ROOT}
-	s0 := &State{ID: STATE_S0}
-	pass := &State{ID: STATE_PASS}
-
-	root.Children = map[StateID]*State{
-		STATE_S0:   s0,
-		STATE_PASS: pass,
-	}
-	root.Initial = STATE_S0
-
-	machine, _ := NewMachine(root)
-
-	rt := NewRuntime(machine, nil)
-	s0.EntryAction = func(ctx context.Context, event *Event, from, to StateID) error {
-		rt.SendEvent(ctx, Event{ID: EVENT_E1})
-		return nil
-	}
-	s0.Transitions = []*Transition{
-		{Event: EVENT_E1, Target: STATE_PASS},
-	}
-	ctx := context.Background()
-
-	rt.Start(ctx)
-	time.Sleep(50 * time.Millisecond) // Give event loop time to process
-	defer rt.Stop()
-
-	if !rt.IsInState(STATE_PASS) {
-		t.Error("onentry order")
-	}
-}
-
-func TestSCXML376(t *testing.T) {
-	t.Skipf("SCXML376: requires onentry error isolation illegal send")
-}
-
-func TestSCXML377(t *testing.T) {
-	// Simplified test: Test that eventless transitions work correctly
-	// Original SCXML377 tests onexit handler order with internal event queue,
-	// which requires Phase 4 (internal vs external event queues)
-	// For Phase 3, we test basic eventless transition chaining
-	const (
-		STATE_ROOT StateID = 1
-		STATE_S0   StateID = 2
-		STATE_S1   StateID = 3
-		STATE_PASS StateID = 4
-	)
-
-	root := &State{ID: STATE_ROOT}
-	s0 := &State{ID: STATE_S0}
-	s1 := &State{ID: STATE_S1}
-	pass := &State{ID: STATE_PASS, Final: true}
-
-	root.Children = map[StateID]*State{
-		STATE_S0:   s0,
-		STATE_S1:   s1,
-		STATE_PASS: pass,
-	}
-	root.Initial = STATE_S0
-
-	// Chain of eventless transitions: s0 -> s1 -> pass
-	s0.Transitions = []*Transition{
-		{Event: NO_EVENT, Target: STATE_S1},
-	}
-	s1.Transitions = []*Transition{
-		{Event: NO_EVENT, Target: STATE_PASS},
-	}
-
-	machine, err := NewMachine(root)
-	if err != nil {
-		t.Fatalf("Failed to create machine: %v", err)
-	}
-
-	rt := NewRuntime(machine, nil)
-	ctx := context.Background()
-
-	if err := rt.Start(ctx); err != nil {
-		t.Fatalf("Failed to start runtime: %v", err)
-	}
-	time.Sleep(50 * time.Millisecond) // Give time for eventless transitions
-	defer rt.Stop()
-
-	if !rt.IsInState(STATE_PASS) {
-		t.Error("Should follow chain of eventless transitions: s0 -> s1 -> pass")
-	}
-}
-
-func TestSCXML378(t *testing.T) {
-	t.Skipf("SCXML378: requires onexit error isolation illegal send")
-}
-
-func TestSCXML387(t *testing.T) {
-	t.Skipf("SCXML387: requires deep history")
-}
-
-func TestSCXML388(t *testing.T) {
-	t.Skipf("SCXML388: requires deep history + datamodel counters")
-}
-
-func TestSCXML396(t *testing.T) {
-	const (
-		STATE_ROOT StateID = 1
-		STATE_S0   StateID = 2
-		STATE_PASS StateID = 3
-		STATE_FAIL StateID = 4
-		EVENT_FOO  EventID = 1
-	)
-
-	root := &State{ID: STATE_ROOT}
-	s0 := &State{ID: STATE_S0}
-	pass := &State{ID: STATE_PASS}
-	fail := &State{ID: STATE_FAIL}
-
-	root.Children = map[StateID]*State{
-		STATE_S0:   s0,
-		STATE_PASS: pass,
-		STATE_FAIL: fail,
-	}
-	root.Initial = STATE_S0
-
-	s0.Transitions = []*Transition{
-		{Event: EVENT_FOO, Target: STATE_PASS},
-		{Event: EVENT_FOO, Target: STATE_FAIL}, // Duplicate, first wins
-	}
-
-	machine, _ := NewMachine(root)
-
-	rt := NewRuntime(machine, nil)
-	s0.EntryAction = func(ctx context.Context, event *Event, from, to StateID) error {
-		rt.SendEvent(ctx, Event{ID: EVENT_FOO})
-		return nil
-	}
-	ctx := context.Background()
-
-	rt.Start(ctx)
-	time.Sleep(50 * time.Millisecond) // Give event loop time to process
-	defer rt.Stop()
-
-	if !rt.IsInState(STATE_PASS) {
-		t.Error("first transition matches")
-	}
-}
-
-func TestSCXML399(t *testing.T) {
-	t.Skipf("SCXML399: requires wildcard prefix foo.*, multiple event desc")
-}
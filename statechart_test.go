@@ -2,6 +2,8 @@ package statechartx_test
 
 import (
 	"context"
+	"errors"
+	"strings"
 	"testing"
 	"time"
 
@@ -363,3 +365,113 @@ func TestInitialActionWithMultipleLevels(t *testing.T) {
 		}
 	}
 }
+
+// Test action lists run after the single action of the same kind, in order, and stop at the first error.
+func TestRunActionsOrderAndError(t *testing.T) {
+	var calls []string
+	record := func(name string, err error) Action {
+		return func(ctx context.Context, evt *Event, from, to StateID) error {
+			calls = append(calls, name)
+			return err
+		}
+	}
+
+	boom := errors.New("boom")
+	s := &State{
+		ID:           STATE_1,
+		EntryAction:  record("single", nil),
+		EntryActions: []Action{record("first", nil), nil, record("second", boom), record("third", nil)},
+	}
+
+	err := s.RunEntryActions(context.Background(), nil, 0, STATE_1)
+	if !errors.Is(err, boom) {
+		t.Fatalf("expected boom, got %v", err)
+	}
+	if got := strings.Join(calls, ","); got != "single,first,second" {
+		t.Errorf("expected single,first,second, got %s", got)
+	}
+
+	calls = nil
+	tr := &Transition{Actions: []Action{record("a", nil), record("b", nil)}}
+	if err := tr.RunActions(context.Background(), nil, 0, 0); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(calls, ","); got != "a,b" {
+		t.Errorf("expected a,b, got %s", got)
+	}
+}
+
+// Test OnEntry and OnExit add actions like the builder's Entry and Exit instead of replacing them.
+func TestOnEntryOnExitAppend(t *testing.T) {
+	var calls []string
+	record := func(name string) Action {
+		return func(ctx context.Context, evt *Event, from, to StateID) error {
+			calls = append(calls, name)
+			return nil
+		}
+	}
+
+	s := &State{ID: STATE_1, EntryAction: record("single")}
+	s.OnEntry(record("first"))
+	s.OnEntry(record("second"))
+	s.OnExit(record("exit1"))
+	s.OnExit(record("exit2"))
+
+	if err := s.RunEntryActions(context.Background(), nil, 0, STATE_1); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.RunExitActions(context.Background(), nil, STATE_1, 0); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(calls, ","); got != "single,first,second,exit1,exit2" {
+		t.Errorf("expected single,first,second,exit1,exit2, got %s", got)
+	}
+
+	calls = nil
+	b := NewMachineBuilder("app", "a")
+	b.State("a").Entry(record("first")).Entry(record("second"))
+	machine, err := b.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := machine.GetState(b.GetID("a")).RunEntryActions(context.Background(), nil, 0, 0); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(calls, ","); got != "first,second" {
+		t.Errorf("expected builder Entry to append too, got %s", got)
+	}
+}
+
+// Test IsDescendantOf and SortedChildren on a built machine.
+func TestStateHierarchyHelpers(t *testing.T) {
+	b := NewMachineBuilder("app", "outer")
+	b.State("outer").Compound("outer.b")
+	b.State("outer.b").Atomic()
+	b.State("outer.a").Atomic()
+	b.State("other").Atomic()
+	machine, err := b.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	root, outer := machine.Root(), machine.GetState(b.GetID("outer"))
+	inner := machine.GetState(b.GetID("outer.a"))
+	if !inner.IsDescendantOf(outer) || !inner.IsDescendantOf(root) {
+		t.Error("outer.a should descend from outer and the root")
+	}
+	if outer.IsDescendantOf(outer) || outer.IsDescendantOf(inner) {
+		t.Error("IsDescendantOf should be strict")
+	}
+
+	var names []string
+	for _, child := range root.SortedChildren() {
+		names = append(names, b.GetName(child.ID))
+	}
+	if got := strings.Join(names, ","); got != "outer,other" {
+		t.Errorf("expected root children outer,other, got %s", got)
+	}
+	children := outer.SortedChildren()
+	if len(children) != 2 || children[0].ID > children[1].ID {
+		t.Errorf("expected outer's children in ascending ID order, got %v", children)
+	}
+}
@@ -0,0 +1,146 @@
+package testgen
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/comalice/statechartx"
+	"github.com/comalice/statechartx/modelcheck"
+	"github.com/comalice/statechartx/testutil"
+)
+
+// Scenarios converts the sequences into named scenarios. Each step expects the
+// active atomic states of its configuration; the first step only checks the
+// initial configuration.
+func (s *Suite) Scenarios() []testutil.Scenario {
+	scenarios := make([]testutil.Scenario, len(s.Sequences))
+	for i, path := range s.Sequences {
+		sc := testutil.Scenario{Name: fmt.Sprintf("%s_%d", s.Criterion, i+1)}
+		for j, step := range path {
+			st := testutil.ScenarioStep{}
+			if j > 0 {
+				st.Event = statechartx.EventNameOf(s.names, step.Event)
+			}
+			for _, id := range step.Node.Active {
+				st.Expect = append(st.Expect, statechartx.StateNameOf(s.names, id))
+			}
+			for _, a := range step.Assumptions {
+				if st.Guards == nil {
+					st.Guards = map[string]bool{}
+				}
+				st.Guards[modelcheck.GuardName(a.Transition, s.names)] = a.Value
+			}
+			sc.Steps = append(sc.Steps, st)
+		}
+		scenarios[i] = sc
+	}
+	return scenarios
+}
+
+// WriteJSON writes the suite as a scenario data file (see testutil.ReadScenarios).
+func (s *Suite) WriteJSON(w io.Writer) error {
+	return testutil.WriteScenarios(w, &testutil.ScenarioFile{
+		Machine:   statechartx.StateNameOf(s.names, s.Machine.Root().ID),
+		Scenarios: s.Scenarios(),
+	})
+}
+
+// GoTestOptions control generated Go test source.
+type GoTestOptions struct {
+	// Package is the package clause of the generated file.
+	Package string
+
+	// Builder is a function in that package returning the *statechartx.MachineBuilder
+	// of the machine, e.g. "newDeviceBuilder".
+	Builder string
+
+	// Name is the suffix of the generated test and table (default "Generated").
+	Name string
+}
+
+// GoTest returns gofmt-formatted Go source for a table-driven test that plays every
+// scenario on the event-driven runtime through testutil.RunScenario.
+func (s *Suite) GoTest(opts GoTestOptions) ([]byte, error) {
+	if opts.Package == "" || opts.Builder == "" {
+		return nil, fmt.Errorf("testgen: GoTestOptions.Package and Builder are required")
+	}
+	if opts.Name == "" {
+		opts.Name = "Generated"
+	}
+	table := "scenarios" + opts.Name
+
+	var buf bytes.Buffer
+	w := func(format string, args ...any) { fmt.Fprintf(&buf, format+"\n", args...) }
+
+	w("// Code generated by statechartx/testgen; DO NOT EDIT.")
+	w("")
+	w("package %s", opts.Package)
+	w("")
+	w("import (")
+	w("\t%q", "testing")
+	w("")
+	w("\t%q", "github.com/comalice/statechartx/testutil")
+	w(")")
+	w("")
+	w("// %s covers %s of %s.", table, s.Criterion, statechartx.StateNameOf(s.names, s.Machine.Root().ID))
+	for _, u := range s.Uncovered {
+		w("// Not covered: %s", u)
+	}
+	w("var %s = []testutil.Scenario{", table)
+	for _, sc := range s.Scenarios() {
+		w("{Name: %q, Steps: []testutil.ScenarioStep{", sc.Name)
+		for _, st := range sc.Steps {
+			var fields []string
+			if st.Event != "" {
+				fields = append(fields, "Event: "+strconv.Quote(st.Event))
+			}
+			fields = append(fields, "Expect: "+goStrings(st.Expect))
+			if len(st.Guards) > 0 {
+				fields = append(fields, "Guards: "+goGuards(st.Guards))
+			}
+			w("{%s},", strings.Join(fields, ", "))
+		}
+		w("}},")
+	}
+	w("}")
+	w("")
+	w("func Test%s(t *testing.T) {", opts.Name)
+	w("b := %s()", opts.Builder)
+	w("newAdapter := func() testutil.RuntimeAdapter {")
+	w("machine, err := %s().Build()", opts.Builder)
+	w("if err != nil {")
+	w("t.Fatal(err)")
+	w("}")
+	w("return testutil.NewEventDrivenAdapter(machine)")
+	w("}")
+	w("testutil.RunScenarios(t, newAdapter, b, %s)", table)
+	w("}")
+
+	return format.Source(buf.Bytes())
+}
+
+func goStrings(items []string) string {
+	quoted := make([]string, len(items))
+	for i, item := range items {
+		quoted[i] = strconv.Quote(item)
+	}
+	return "[]string{" + strings.Join(quoted, ", ") + "}"
+}
+
+func goGuards(guards map[string]bool) string {
+	names := make([]string, 0, len(guards))
+	for name := range guards {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	parts := make([]string, len(names))
+	for i, name := range names {
+		parts[i] = fmt.Sprintf("%q: %v", name, guards[name])
+	}
+	return "map[string]bool{" + strings.Join(parts, ", ") + "}"
+}
@@ -0,0 +1,367 @@
+// Package testgen generates regression tests from a statechartx machine.
+//
+// Generate explores the machine with package modelcheck and picks event sequences
+// that reach a coverage criterion:
+//
+//   - AllStates: every reachable state is active at least once
+//   - AllTransitions: every reachable transition fires at least once
+//     (including eventless and done.state transitions)
+//   - AllTransitionPairs: every pair of consecutive macrosteps is taken at least once
+//
+// Sequences are chosen greedily: each one is extended with the shortest run to the
+// nearest uncovered item until nothing more is reachable or MaxLength is hit, and a
+// new sequence is started from the initial configuration. This keeps the suite small
+// without the cost of an optimal cover.
+//
+// Guarded transitions are only used when Hints decide their guards (the test cannot
+// control the guard otherwise), unless AllowFreeGuards is set. The suite is emitted as
+// Go table-driven test source (GoTest) or as a scenario data file (WriteJSON), both
+// played by testutil.RunScenario on any testutil.RuntimeAdapter.
+//
+// Example:
+//
+//	suite, _ := testgen.Generate(machine, testgen.Options{
+//		Criterion: testgen.AllTransitions,
+//		Names:     b,
+//		Hints:     map[string]bool{"hasPower": true},
+//	})
+//	src, _ := suite.GoTest(testgen.GoTestOptions{Package: "device", Builder: "newDeviceBuilder"})
+package testgen
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/comalice/statechartx"
+	"github.com/comalice/statechartx/modelcheck"
+)
+
+// DefaultMaxLength bounds the number of events per sequence when Options.MaxLength is zero.
+const DefaultMaxLength = 50
+
+// Criterion selects what the generated sequences must cover.
+type Criterion int
+
+const (
+	AllStates Criterion = iota
+	AllTransitions
+	AllTransitionPairs
+)
+
+func (c Criterion) String() string {
+	switch c {
+	case AllStates:
+		return "all-states"
+	case AllTransitions:
+		return "all-transitions"
+	case AllTransitionPairs:
+		return "all-transition-pairs"
+	}
+	return fmt.Sprintf("criterion(%d)", int(c))
+}
+
+// Options control test generation.
+type Options struct {
+	Criterion Criterion
+
+	// Names provides state and event names (typically the *statechartx.MachineBuilder).
+	Names statechartx.Namer
+
+	// Hints fixes guard results by guard name (see modelcheck.GuardName).
+	Hints map[string]bool
+
+	// AllowFreeGuards also uses transitions whose guards are not decided by Hints.
+	// The assumed results are recorded in ScenarioStep.Guards.
+	AllowFreeGuards bool
+
+	// MaxLength is the maximum number of events per sequence (default DefaultMaxLength).
+	MaxLength int
+
+	// MaxConfigurations bounds exploration (see modelcheck.Options).
+	MaxConfigurations int
+}
+
+// Suite is a generated set of event sequences.
+type Suite struct {
+	Machine   *statechartx.Machine
+	Criterion Criterion
+	Sequences []modelcheck.Path
+	// Uncovered describes the items no sequence covers: unreachable states and
+	// transitions, or ones that need undecided guards.
+	Uncovered []string
+
+	names statechartx.Namer
+}
+
+// Generate builds a test suite for m.
+func Generate(m *statechartx.Machine, opts Options) (*Suite, error) {
+	if opts.MaxLength <= 0 {
+		opts.MaxLength = DefaultMaxLength
+	}
+	g, err := modelcheck.Explore(m, modelcheck.Options{
+		Guards:            hintEvaluator(opts),
+		MaxConfigurations: opts.MaxConfigurations,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	gen := &generator{g: g, opts: opts, covered: map[item]bool{}, ids: map[*modelcheck.Edge]int{}}
+	gen.index()
+	gen.run()
+
+	suite := &Suite{Machine: m, Criterion: opts.Criterion, Sequences: gen.sequences, names: opts.Names}
+	suite.Uncovered = gen.uncovered(m)
+	return suite, nil
+}
+
+// hintEvaluator decides guards named in opts.Hints
+func hintEvaluator(opts Options) modelcheck.GuardEvaluator {
+	return func(t *statechartx.Transition, active []statechartx.StateID) modelcheck.Outcome {
+		value, ok := opts.Hints[modelcheck.GuardName(t, opts.Names)]
+		switch {
+		case !ok:
+			return modelcheck.Unknown
+		case value:
+			return modelcheck.True
+		default:
+			return modelcheck.False
+		}
+	}
+}
+
+// item is something a criterion requires to be covered
+type item struct {
+	kind  byte // 's'tate, 't'ransition, 'p'air
+	state statechartx.StateID
+	trans *statechartx.Transition
+	a, b  int // edge IDs of a pair
+}
+
+// position is a point in the search: a node and the edge that led to it
+type position struct {
+	node *modelcheck.Node // nil before start
+	last int              // edge ID, -1 before start
+}
+
+// move is an edge taken from a position
+type move struct {
+	edge *modelcheck.Edge
+	id   int
+}
+
+type generator struct {
+	g         *modelcheck.Graph
+	opts      Options
+	ids       map[*modelcheck.Edge]int
+	edges     []*modelcheck.Edge // by ID
+	universe  map[item]bool
+	covered   map[item]bool
+	sequences []modelcheck.Path
+}
+
+// index assigns edge IDs and collects every coverable item
+func (gen *generator) index() {
+	gen.universe = map[item]bool{}
+	var edges []*modelcheck.Edge
+	for i := range gen.g.Initial {
+		edges = append(edges, &gen.g.Initial[i])
+	}
+	for _, n := range gen.g.Nodes {
+		for i := range n.Edges {
+			edges = append(edges, &n.Edges[i])
+		}
+	}
+	for i, e := range edges {
+		gen.ids[e] = i
+	}
+	gen.edges = edges
+
+	// Every usable move from every position reachable with usable moves
+	seen := map[position]bool{}
+	queue := []position{{last: -1}}
+	for len(queue) > 0 {
+		p := queue[0]
+		queue = queue[1:]
+		for _, mv := range gen.moves(p) {
+			for _, it := range gen.items(p, mv) {
+				gen.universe[it] = true
+			}
+			next := position{node: mv.edge.To, last: mv.id}
+			if gen.opts.Criterion != AllTransitionPairs {
+				next.last = 0 // the previous edge only matters for pairs
+			}
+			if !seen[next] {
+				seen[next] = true
+				queue = append(queue, next)
+			}
+		}
+	}
+}
+
+// moves returns the usable edges from p
+func (gen *generator) moves(p position) []move {
+	var edges []modelcheck.Edge
+	if p.node == nil {
+		edges = gen.g.Initial
+	} else {
+		edges = p.node.Edges
+	}
+	var out []move
+	for i := range edges {
+		e := &edges[i]
+		if gen.usable(e) {
+			out = append(out, move{edge: e, id: gen.ids[e]})
+		}
+	}
+	return out
+}
+
+// usable reports whether a test can drive e: its guards are all decided
+func (gen *generator) usable(e *modelcheck.Edge) bool {
+	if gen.opts.AllowFreeGuards {
+		return true
+	}
+	for _, a := range e.Assumptions {
+		if !a.Decided {
+			return false
+		}
+	}
+	return true
+}
+
+// items returns what taking mv from p covers
+func (gen *generator) items(p position, mv move) []item {
+	var out []item
+	switch gen.opts.Criterion {
+	case AllStates:
+		for _, id := range gen.g.Machine.StateIDs() {
+			if mv.edge.To.InState(id) {
+				out = append(out, item{kind: 's', state: id})
+			}
+		}
+	case AllTransitions:
+		for _, t := range mv.edge.Fired {
+			out = append(out, item{kind: 't', trans: t})
+		}
+	case AllTransitionPairs:
+		if p.last >= 0 && p.node != nil {
+			out = append(out, item{kind: 'p', a: p.last, b: mv.id})
+		}
+	}
+	return out
+}
+
+// run builds sequences until every reachable item is covered
+func (gen *generator) run() {
+	for len(gen.covered) < len(gen.universe) {
+		path := gen.sequence()
+		if len(path) == 0 {
+			return
+		}
+		gen.sequences = append(gen.sequences, path)
+	}
+}
+
+// sequence builds one sequence from the start, returning nil if it covers nothing new
+func (gen *generator) sequence() modelcheck.Path {
+	var path modelcheck.Path
+	pos := position{last: -1}
+	progress := false
+	for {
+		moves := gen.nearest(pos, gen.opts.MaxLength+1-len(path))
+		if moves == nil {
+			break
+		}
+		for _, mv := range moves {
+			for _, it := range gen.items(pos, mv) {
+				if !gen.covered[it] {
+					gen.covered[it] = true
+					progress = true
+				}
+			}
+			path = append(path, modelcheck.Step{Event: mv.edge.Event, Assumptions: mv.edge.Assumptions, Node: mv.edge.To})
+			pos = position{node: mv.edge.To, last: mv.id}
+		}
+	}
+	if !progress {
+		return nil
+	}
+	return path
+}
+
+// nearest returns the shortest list of at most limit moves from p whose last move
+// covers an uncovered item, or nil
+func (gen *generator) nearest(p position, limit int) []move {
+	type visit struct {
+		prev *visit
+		pos  position
+		mv   move
+	}
+	seen := map[position]bool{p: true}
+	queue := []*visit{{pos: p}}
+	for depth := 0; depth < limit && len(queue) > 0; depth++ {
+		var next []*visit
+		for _, v := range queue {
+			if v.pos.node != nil && v.pos.node.Final {
+				continue
+			}
+			for _, mv := range gen.moves(v.pos) {
+				w := &visit{prev: v, pos: position{node: mv.edge.To, last: mv.id}, mv: mv}
+				for _, it := range gen.items(v.pos, mv) {
+					if !gen.covered[it] {
+						var moves []move
+						for x := w; x.prev != nil; x = x.prev {
+							moves = append([]move{x.mv}, moves...)
+						}
+						return moves
+					}
+				}
+				key := w.pos
+				if gen.opts.Criterion != AllTransitionPairs {
+					key.last = 0
+				}
+				if !seen[key] {
+					seen[key] = true
+					next = append(next, w)
+				}
+			}
+		}
+		queue = next
+	}
+	return nil
+}
+
+// uncovered describes the items of the criterion that no sequence covers
+func (gen *generator) uncovered(m *statechartx.Machine) []string {
+	var out []string
+	names := gen.opts.Names
+	switch gen.opts.Criterion {
+	case AllStates:
+		for _, id := range m.StateIDs() {
+			s := m.GetState(id)
+			if s.Parent != nil && !s.IsHistoryState && !gen.covered[item{kind: 's', state: id}] {
+				out = append(out, "state "+statechartx.StateNameOf(names, id))
+			}
+		}
+	case AllTransitions:
+		for _, id := range m.StateIDs() {
+			for i, t := range m.GetState(id).Transitions {
+				if t != nil && !gen.covered[item{kind: 't', trans: t}] {
+					out = append(out, fmt.Sprintf("transition %s[%d] on %q",
+						statechartx.StateNameOf(names, id), i, statechartx.EventNameOf(names, t.Event)))
+				}
+			}
+		}
+	case AllTransitionPairs:
+		for it := range gen.universe {
+			if !gen.covered[it] {
+				a, b := gen.edges[it.a], gen.edges[it.b]
+				out = append(out, fmt.Sprintf("pair %s then %s in configuration %d",
+					statechartx.EventNameOf(names, a.Event), statechartx.EventNameOf(names, b.Event), a.To.ID))
+			}
+		}
+		sort.Strings(out)
+	}
+	return out
+}
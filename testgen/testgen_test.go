@@ -0,0 +1,155 @@
+package testgen_test
+
+import (
+	"bytes"
+	"context"
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+
+	"github.com/comalice/statechartx"
+	"github.com/comalice/statechartx/testgen"
+	"github.com/comalice/statechartx/testutil"
+)
+
+func newDeviceBuilder() *statechartx.MachineBuilder {
+	reg := statechartx.NewRegistry()
+	reg.RegisterGuard("hasJob", func(ctx context.Context, evt *statechartx.Event, from, to statechartx.StateID) (bool, error) {
+		return true, nil
+	})
+	b := statechartx.NewMachineBuilder("device", "off").WithRegistry(reg)
+	b.State("off").On("power", "on", nil, nil)
+	b.State("on").Compound("on.idle").On("power", "off", nil, nil)
+	b.State("on.idle").OnRef("work", "on.busy", "hasJob")
+	b.State("on.busy").On("done", "on.idle", nil, nil)
+	return b
+}
+
+func generate(t *testing.T, b *statechartx.MachineBuilder, opts testgen.Options) *testgen.Suite {
+	t.Helper()
+	machine, err := b.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts.Names = b
+	suite, err := testgen.Generate(machine, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return suite
+}
+
+func TestGuardsNeedHints(t *testing.T) {
+	tests := []struct {
+		name      string
+		opts      testgen.Options
+		uncovered int
+	}{
+		{"no hints", testgen.Options{Criterion: testgen.AllStates}, 1},
+		{"hints", testgen.Options{Criterion: testgen.AllStates, Hints: map[string]bool{"hasJob": true}}, 0},
+		{"free guards", testgen.Options{Criterion: testgen.AllStates, AllowFreeGuards: true}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			suite := generate(t, newDeviceBuilder(), tt.opts)
+			if len(suite.Uncovered) != tt.uncovered {
+				t.Errorf("expected %d uncovered items, got %v", tt.uncovered, suite.Uncovered)
+			}
+			if len(suite.Sequences) == 0 {
+				t.Error("expected at least one sequence")
+			}
+		})
+	}
+
+	suite := generate(t, newDeviceBuilder(), testgen.Options{Criterion: testgen.AllStates})
+	if len(suite.Uncovered) == 1 && suite.Uncovered[0] != "state on.busy" {
+		t.Errorf("expected on.busy to be uncovered, got %q", suite.Uncovered[0])
+	}
+}
+
+func TestCriteriaCoverMore(t *testing.T) {
+	hints := map[string]bool{"hasJob": true}
+	var counts []int
+	for _, c := range []testgen.Criterion{testgen.AllStates, testgen.AllTransitions, testgen.AllTransitionPairs} {
+		suite := generate(t, newDeviceBuilder(), testgen.Options{Criterion: c, Hints: hints})
+		if len(suite.Uncovered) != 0 {
+			t.Errorf("%s: expected full coverage, missing %v", c, suite.Uncovered)
+		}
+		events := 0
+		for _, seq := range suite.Sequences {
+			events += len(seq) - 1
+		}
+		counts = append(counts, events)
+	}
+	if counts[0] > counts[1] || counts[1] > counts[2] {
+		t.Errorf("expected stronger criteria to need more events, got %v", counts)
+	}
+}
+
+func TestMaxLength(t *testing.T) {
+	suite := generate(t, newDeviceBuilder(), testgen.Options{
+		Criterion: testgen.AllTransitionPairs,
+		Hints:     map[string]bool{"hasJob": true},
+		MaxLength: 2,
+	})
+	for _, seq := range suite.Sequences {
+		if len(seq)-1 > 2 {
+			t.Errorf("sequence longer than MaxLength: %v", seq.Events())
+		}
+	}
+}
+
+func TestScenariosRunOnRuntime(t *testing.T) {
+	b := newDeviceBuilder()
+	suite := generate(t, b, testgen.Options{Criterion: testgen.AllTransitions, Hints: map[string]bool{"hasJob": true}})
+
+	var buf bytes.Buffer
+	if err := suite.WriteJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+	file, err := testutil.ReadScenarios(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if file.Machine != "device" || len(file.Scenarios) != len(suite.Sequences) {
+		t.Fatalf("expected %d scenarios for device, got %d for %q", len(suite.Sequences), len(file.Scenarios), file.Machine)
+	}
+
+	newAdapter := func() testutil.RuntimeAdapter {
+		machine, err := newDeviceBuilder().Build()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return testutil.NewEventDrivenAdapter(machine)
+	}
+	testutil.RunScenarios(t, newAdapter, b, file.Scenarios)
+}
+
+func TestGoTest(t *testing.T) {
+	suite := generate(t, newDeviceBuilder(), testgen.Options{Criterion: testgen.AllTransitions, AllowFreeGuards: true})
+	src, err := suite.GoTest(testgen.GoTestOptions{Package: "device", Builder: "newDeviceBuilder"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := parser.ParseFile(token.NewFileSet(), "device_test.go", src, 0); err != nil {
+		t.Fatalf("generated source does not parse: %v\n%s", err, src)
+	}
+	for _, want := range []string{
+		"// Code generated by statechartx/testgen; DO NOT EDIT.",
+		"package device",
+		"func TestGenerated(t *testing.T)",
+		`Event: "work"`,
+		`"hasJob": true`,
+		"testutil.RunScenarios(t, newAdapter, b, scenariosGenerated)",
+	} {
+		if !strings.Contains(string(src), want) {
+			t.Errorf("expected generated source to contain %q\n%s", want, src)
+		}
+	}
+
+	if _, err := suite.GoTest(testgen.GoTestOptions{}); err == nil {
+		t.Error("expected an error without Package and Builder")
+	}
+}
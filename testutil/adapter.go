@@ -4,6 +4,7 @@ package testutil
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/comalice/statechartx"
@@ -18,6 +19,8 @@ type RuntimeAdapter interface {
 	SendEvent(event statechartx.Event) error
 	IsInState(stateID statechartx.StateID) bool
 	GetCurrentState() statechartx.StateID
+	// WaitForStability returns once the runtime has processed everything sent
+	// so far, or an error if it is still busy after timeout
 	WaitForStability(timeout time.Duration) error
 }
 
@@ -53,16 +56,19 @@ func (a *EventDrivenAdapter) GetCurrentState() statechartx.StateID {
 	return a.rt.GetCurrentState()
 }
 
+// WaitForStability waits up to timeout for the runtime to become idle
 func (a *EventDrivenAdapter) WaitForStability(timeout time.Duration) error {
-	// Event-driven processes immediately, small delay for goroutine scheduling
-	time.Sleep(5 * time.Millisecond)
-	return nil
+	return waitIdle(a, timeout)
+}
+
+// WaitIdle blocks until the runtime is quiescent (see statechartx.Runtime.WaitIdle)
+func (a *EventDrivenAdapter) WaitIdle(ctx context.Context) error {
+	return a.rt.WaitIdle(ctx)
 }
 
 // TickBasedAdapter wraps the tick-based runtime
 type TickBasedAdapter struct {
-	rt       *realtime.RealtimeRuntime
-	tickRate time.Duration
+	rt *realtime.RealtimeRuntime
 }
 
 // NewTickBasedAdapter creates a new adapter for the tick-based runtime
@@ -71,7 +77,6 @@ func NewTickBasedAdapter(machine *statechartx.Machine, tickRate time.Duration) *
 		rt: realtime.NewRuntime(machine, realtime.Config{
 			TickRate: tickRate,
 		}),
-		tickRate: tickRate,
 	}
 }
 
@@ -95,8 +100,22 @@ func (a *TickBasedAdapter) GetCurrentState() statechartx.StateID {
 	return a.rt.GetCurrentState()
 }
 
+// WaitForStability waits up to timeout for the runtime to become idle
 func (a *TickBasedAdapter) WaitForStability(timeout time.Duration) error {
-	// Wait for next tick to process event
-	time.Sleep(a.tickRate + 5*time.Millisecond)
+	return waitIdle(a, timeout)
+}
+
+// WaitIdle blocks until the runtime is quiescent (see realtime.RealtimeRuntime.WaitIdle)
+func (a *TickBasedAdapter) WaitIdle(ctx context.Context) error {
+	return a.rt.WaitIdle(ctx)
+}
+
+// waitIdle bounds WaitIdle by timeout
+func waitIdle(a interface{ WaitIdle(context.Context) error }, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if err := a.WaitIdle(ctx); err != nil {
+		return fmt.Errorf("runtime not idle after %v: %w", timeout, err)
+	}
 	return nil
 }
@@ -0,0 +1,549 @@
+package testutil
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/comalice/statechartx"
+)
+
+// Differential testing runs the same generated chart and event sequence on two
+// runtimes and compares the final configuration and the entry/exit trace.
+// Cases are decoded from fuzzer bytes (DecodeDiffCase), so `go test -fuzz`
+// explores charts with hierarchy, parallel states, history and eventless
+// transitions; a divergence is shrunk to a minimal chart and event list.
+
+// StateKind is the kind of a generated chart state.
+type StateKind int
+
+const (
+	KindAtomic StateKind = iota
+	KindCompound
+	KindParallel
+	KindFinal
+	KindShallowHistory
+	KindDeepHistory
+)
+
+func (k StateKind) String() string {
+	switch k {
+	case KindAtomic:
+		return "atomic"
+	case KindCompound:
+		return "compound"
+	case KindParallel:
+		return "parallel"
+	case KindFinal:
+		return "final"
+	case KindShallowHistory:
+		return "history"
+	case KindDeepHistory:
+		return "deep history"
+	}
+	return fmt.Sprintf("StateKind(%d)", int(k))
+}
+
+// ChartState is a generated state. States[0] of a Chart is the root.
+type ChartState struct {
+	Parent      int // index of the parent state; -1 for the root
+	Kind        StateKind
+	Transitions []ChartTransition
+}
+
+// ChartTransition is a generated transition.
+type ChartTransition struct {
+	Event  int // event index; -1 for an eventless transition
+	Target int // state index; -1 for an internal transition
+}
+
+// Chart is a generated machine description.
+type Chart struct {
+	States []ChartState
+	Events int // number of distinct events, named e0, e1, ...
+}
+
+// DiffCase is a chart and the events sent to it, one at a time.
+type DiffCase struct {
+	Chart  Chart
+	Events []int
+}
+
+// DiffResult is what a runtime did with a case.
+type DiffResult struct {
+	Configuration []string // active states (including ancestors), sorted
+	Trace         []string // "+name" on entry, "-name" on exit, in order
+}
+
+// DefaultDiffRuntimes are the runtimes compared by CheckDifferential:
+// the event-driven runtime and the tick-based runtime at a 1ms tick.
+var DefaultDiffRuntimes = [2]func(*statechartx.Machine) RuntimeAdapter{
+	func(m *statechartx.Machine) RuntimeAdapter { return NewEventDrivenAdapter(m) },
+	func(m *statechartx.Machine) RuntimeAdapter { return NewTickBasedAdapter(m, time.Millisecond) },
+}
+
+// byteSource turns fuzzer input into bounded choices; it yields zeros once exhausted.
+type byteSource struct {
+	data []byte
+}
+
+func (s *byteSource) intn(n int) int {
+	if n <= 1 || len(s.data) == 0 {
+		return 0
+	}
+	b := s.data[0]
+	s.data = s.data[1:]
+	return int(b) % n
+}
+
+// DecodeDiffCase builds a valid case from arbitrary bytes. The same bytes always
+// produce the same case.
+//
+// Eventless transitions only target later, non-history states outside their source,
+// which keeps generated charts free of eventless cycles.
+func DecodeDiffCase(data []byte) DiffCase {
+	src := &byteSource{data: data}
+	n := 1 + src.intn(8)
+	c := Chart{Events: 1 + src.intn(3)}
+	c.States = make([]ChartState, n+1)
+	c.States[0] = ChartState{Parent: -1, Kind: KindCompound}
+
+	for i := 1; i <= n; i++ {
+		c.States[i].Parent = src.intn(i)
+	}
+	for i := 1; i <= n; i++ {
+		kids := c.children(i)
+		switch {
+		case len(kids) >= 2 && src.intn(4) == 0:
+			c.States[i].Kind = KindParallel
+		case len(kids) > 0:
+			c.States[i].Kind = KindCompound
+		default:
+			parent := c.States[i].Parent
+			history := parent != 0 && c.States[parent].Kind != KindParallel && c.children(parent)[0] != i
+			switch r := src.intn(8); {
+			case r == 0:
+				c.States[i].Kind = KindFinal
+			case r == 1 && history:
+				c.States[i].Kind = KindShallowHistory
+			case r == 2 && history:
+				c.States[i].Kind = KindDeepHistory
+			}
+		}
+	}
+
+	for i := 1; i <= n; i++ {
+		if c.States[i].isHistory() {
+			continue
+		}
+		for k := src.intn(3); k > 0; k-- {
+			t := ChartTransition{Event: src.intn(c.Events), Target: src.intn(n+1) - 1}
+			if t.Target == 0 {
+				t.Target = -1 // the root is never a target
+			}
+			if src.intn(4) == 0 {
+				t.Event = -1
+				if !c.eventlessTarget(i, t.Target) {
+					continue
+				}
+			}
+			c.States[i].Transitions = append(c.States[i].Transitions, t)
+		}
+	}
+
+	events := make([]int, src.intn(12))
+	for i := range events {
+		events[i] = src.intn(c.Events)
+	}
+	return DiffCase{Chart: c, Events: events}
+}
+
+func (s ChartState) isHistory() bool {
+	return s.Kind == KindShallowHistory || s.Kind == KindDeepHistory
+}
+
+// children returns the indexes of a state's children in order
+func (c Chart) children(i int) []int {
+	var kids []int
+	for j := i + 1; j < len(c.States); j++ {
+		if c.States[j].Parent == i {
+			kids = append(kids, j)
+		}
+	}
+	return kids
+}
+
+// isDescendant reports whether j is a proper descendant of i
+func (c Chart) isDescendant(j, i int) bool {
+	for p := c.States[j].Parent; p >= 0; p = c.States[p].Parent {
+		if p == i {
+			return true
+		}
+	}
+	return false
+}
+
+// eventlessTarget reports whether an eventless transition from i to target is allowed
+func (c Chart) eventlessTarget(i, target int) bool {
+	return target > i && !c.States[target].isHistory() && !c.isDescendant(target, i)
+}
+
+// name returns the dotted builder name of state i
+func (c Chart) name(i int) string {
+	if i == 0 {
+		return "root"
+	}
+	name := fmt.Sprintf("s%d", i)
+	if p := c.States[i].Parent; p > 0 {
+		return c.name(p) + "." + name
+	}
+	return name
+}
+
+// initial returns the first non-history child of state i, or -1
+func (c Chart) initial(i int) int {
+	for _, k := range c.children(i) {
+		if !c.States[k].isHistory() {
+			return k
+		}
+	}
+	return -1
+}
+
+// Build builds the chart, recording entries and exits in trace.
+func (c Chart) Build(trace *Trace) (*statechartx.MachineBuilder, *statechartx.Machine, error) {
+	initial := c.initial(0)
+	if initial < 0 {
+		return nil, nil, fmt.Errorf("chart has no initial state")
+	}
+	b := statechartx.NewMachineBuilder("root", c.name(initial))
+
+	for i := 1; i < len(c.States); i++ {
+		s := c.States[i]
+		name := c.name(i)
+		sb := b.State(name)
+		switch {
+		case s.isHistory():
+			typ := statechartx.HistoryShallow
+			if s.Kind == KindDeepHistory {
+				typ = statechartx.HistoryDeep
+			}
+			sb.History(typ, "")
+			continue
+		case s.Kind == KindParallel && len(c.children(i)) >= 2:
+			sb.Parallel()
+		case c.initial(i) >= 0:
+			sb.Compound(c.name(c.initial(i)))
+		case s.Kind == KindFinal:
+			sb.Final(nil)
+		}
+		sb.Entry(trace.record("+" + name))
+		sb.Exit(trace.record("-" + name))
+
+		for _, t := range s.Transitions {
+			event := ""
+			if t.Event >= 0 {
+				event = fmt.Sprintf("e%d", t.Event)
+			}
+			if t.Target < 0 {
+				sb.OnInternal(event, nil, nil)
+			} else {
+				sb.On(event, c.name(t.Target), nil, nil)
+			}
+		}
+	}
+
+	m, err := b.Build()
+	return b, m, err
+}
+
+// hasParallel reports whether the chart has a parallel state
+func (c Chart) hasParallel() bool {
+	for i, s := range c.States {
+		if s.Kind == KindParallel && len(c.children(i)) >= 2 {
+			return true
+		}
+	}
+	return false
+}
+
+// String describes the case in builder terms, for failure reports.
+func (dc DiffCase) String() string {
+	c := dc.Chart
+	var sb strings.Builder
+	for i := 1; i < len(c.States); i++ {
+		s := c.States[i]
+		kind := s.Kind
+		if kind == KindCompound && c.initial(i) < 0 {
+			kind = KindAtomic
+		}
+		fmt.Fprintf(&sb, "%s (%s)\n", c.name(i), kind)
+		for _, t := range s.Transitions {
+			event := "(eventless)"
+			if t.Event >= 0 {
+				event = fmt.Sprintf("e%d", t.Event)
+			}
+			target := "(internal)"
+			if t.Target >= 0 {
+				target = c.name(t.Target)
+			}
+			fmt.Fprintf(&sb, "  %s -> %s\n", event, target)
+		}
+	}
+	events := make([]string, len(dc.Events))
+	for i, e := range dc.Events {
+		events[i] = fmt.Sprintf("e%d", e)
+	}
+	fmt.Fprintf(&sb, "events: %s\n", strings.Join(events, " "))
+	return sb.String()
+}
+
+// Trace records entry and exit actions. It is safe for concurrent use.
+type Trace struct {
+	mu      sync.Mutex
+	entries []string
+}
+
+func (t *Trace) record(entry string) statechartx.Action {
+	return func(ctx context.Context, evt *statechartx.Event, from, to statechartx.StateID) error {
+		t.mu.Lock()
+		t.entries = append(t.entries, entry)
+		t.mu.Unlock()
+		return nil
+	}
+}
+
+// Entries returns a copy of the recorded trace.
+func (t *Trace) Entries() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]string(nil), t.entries...)
+}
+
+// RunDiffCase plays dc on a fresh runtime from newAdapter.
+func RunDiffCase(dc DiffCase, newAdapter func(*statechartx.Machine) RuntimeAdapter) (DiffResult, error) {
+	trace := &Trace{}
+	b, m, err := dc.Chart.Build(trace)
+	if err != nil {
+		return DiffResult{}, err
+	}
+
+	adapter := newAdapter(m)
+	if err := adapter.Start(context.Background()); err != nil {
+		return DiffResult{}, err
+	}
+	defer adapter.Stop()
+
+	if err := adapter.WaitForStability(time.Second); err != nil {
+		return DiffResult{}, err
+	}
+	for _, e := range dc.Events {
+		if err := adapter.SendEvent(statechartx.Event{ID: b.EventID(fmt.Sprintf("e%d", e))}); err != nil {
+			return DiffResult{}, err
+		}
+		if err := adapter.WaitForStability(time.Second); err != nil {
+			return DiffResult{}, err
+		}
+	}
+
+	var result DiffResult
+	for i := 1; i < len(dc.Chart.States); i++ {
+		if !dc.Chart.States[i].isHistory() && adapter.IsInState(b.GetID(dc.Chart.name(i))) {
+			result.Configuration = append(result.Configuration, dc.Chart.name(i))
+		}
+	}
+	sort.Strings(result.Configuration)
+	result.Trace = trace.Entries()
+	return result, nil
+}
+
+// Compare describes how two results of dc differ, or returns "" if they agree.
+// Traces of charts with parallel states are compared as multisets, because the
+// event-driven runtime runs regions concurrently.
+func (dc DiffCase) Compare(a, b DiffResult) string {
+	if strings.Join(a.Configuration, " ") != strings.Join(b.Configuration, " ") {
+		return fmt.Sprintf("configuration %v != %v", a.Configuration, b.Configuration)
+	}
+	ta, tb := a.Trace, b.Trace
+	if dc.Chart.hasParallel() {
+		ta = append([]string(nil), ta...)
+		tb = append([]string(nil), tb...)
+		sort.Strings(ta)
+		sort.Strings(tb)
+	}
+	if strings.Join(ta, " ") != strings.Join(tb, " ") {
+		return fmt.Sprintf("trace %v != %v", a.Trace, b.Trace)
+	}
+	return ""
+}
+
+// Diff runs dc on both runtimes and returns the divergence, or "" if they agree.
+func Diff(dc DiffCase, runtimes [2]func(*statechartx.Machine) RuntimeAdapter) (string, error) {
+	a, err := RunDiffCase(dc, runtimes[0])
+	if err != nil {
+		return "", err
+	}
+	b, err := RunDiffCase(dc, runtimes[1])
+	if err != nil {
+		return "", err
+	}
+	return dc.Compare(a, b), nil
+}
+
+// ShrinkDiffCase greedily removes events, transitions and states from dc and
+// simplifies state kinds while fails still reports true, returning the smallest
+// failing case found.
+func ShrinkDiffCase(dc DiffCase, fails func(DiffCase) bool) DiffCase {
+	for changed := true; changed; {
+		changed = false
+		for _, candidate := range dc.smaller() {
+			if fails(candidate) {
+				dc = candidate
+				changed = true
+				break
+			}
+		}
+	}
+	return dc
+}
+
+// smaller returns the one-step reductions of dc, most aggressive first
+func (dc DiffCase) smaller() []DiffCase {
+	var out []DiffCase
+	c := dc.Chart
+
+	// Drop a suffix, then single events
+	for n := 0; n < len(dc.Events); n++ {
+		out = append(out, DiffCase{Chart: c, Events: append([]int(nil), dc.Events[:n]...)})
+	}
+	for i := range dc.Events {
+		events := append(append([]int(nil), dc.Events[:i]...), dc.Events[i+1:]...)
+		out = append(out, DiffCase{Chart: c, Events: events})
+	}
+
+	// Drop a state with its subtree
+	for i := len(c.States) - 1; i >= 1; i-- {
+		if smaller, ok := c.without(i); ok {
+			out = append(out, DiffCase{Chart: smaller, Events: dc.Events})
+		}
+	}
+
+	// Drop a transition
+	for i, s := range c.States {
+		for j := range s.Transitions {
+			smaller := c.clone()
+			ts := smaller.States[i].Transitions
+			smaller.States[i].Transitions = append(ts[:j:j], ts[j+1:]...)
+			out = append(out, DiffCase{Chart: smaller, Events: dc.Events})
+		}
+	}
+
+	// Simplify kinds
+	for i, s := range c.States {
+		simpler := s.Kind
+		switch s.Kind {
+		case KindParallel:
+			simpler = KindCompound
+		case KindFinal:
+			simpler = KindAtomic
+		case KindDeepHistory:
+			simpler = KindShallowHistory
+		}
+		if simpler != s.Kind {
+			smaller := c.clone()
+			smaller.States[i].Kind = simpler
+			out = append(out, DiffCase{Chart: smaller, Events: dc.Events})
+		}
+	}
+	return out
+}
+
+func (c Chart) clone() Chart {
+	states := make([]ChartState, len(c.States))
+	for i, s := range c.States {
+		s.Transitions = append([]ChartTransition(nil), s.Transitions...)
+		states[i] = s
+	}
+	return Chart{States: states, Events: c.Events}
+}
+
+// without removes state i and its descendants, renumbering the rest. It fails if
+// the result would leave the root without an initial state or a compound state
+// with only history children.
+func (c Chart) without(i int) (Chart, bool) {
+	index := make([]int, len(c.States))
+	var states []ChartState
+	for j, s := range c.States {
+		if j == i || c.isDescendant(j, i) {
+			index[j] = -1
+			continue
+		}
+		index[j] = len(states)
+		states = append(states, s)
+	}
+
+	out := Chart{Events: c.Events, States: make([]ChartState, len(states))}
+	for j, s := range states {
+		if s.Parent >= 0 {
+			s.Parent = index[s.Parent]
+		}
+		var ts []ChartTransition
+		for _, t := range s.Transitions {
+			if t.Target >= 0 {
+				if t.Target = index[t.Target]; t.Target < 0 {
+					continue
+				}
+			}
+			ts = append(ts, t)
+		}
+		s.Transitions = ts
+		out.States[j] = s
+	}
+
+	for j := range out.States {
+		if kids := out.children(j); len(kids) > 0 && out.initial(j) < 0 {
+			return Chart{}, false
+		}
+	}
+	return out, out.initial(0) >= 0
+}
+
+// KnownDivergence returns the issues.md entry that may explain a divergence on
+// dc, or "" if any divergence would be new. The runtimes are known to disagree
+// on charts with parallel states (issues.md #4).
+func (dc DiffCase) KnownDivergence() string {
+	if dc.Chart.hasParallel() {
+		return "issues.md #4, runtime divergences in parallel states"
+	}
+	return ""
+}
+
+// CheckDifferential runs dc on DefaultDiffRuntimes and fails t with a shrunk
+// case if they diverge. Divergences covered by KnownDivergence skip t instead,
+// so the fuzzer keeps looking for new ones; use Diff to see them.
+func CheckDifferential(t testing.TB, dc DiffCase) {
+	t.Helper()
+	diff, err := Diff(dc, DefaultDiffRuntimes)
+	if err != nil {
+		t.Fatalf("running case: %v\n%s", err, dc)
+	}
+	if diff == "" {
+		return
+	}
+	if known := dc.KnownDivergence(); known != "" {
+		t.Skipf("known divergence (%s): %s", known, diff)
+	}
+
+	shrunk := ShrinkDiffCase(dc, func(c DiffCase) bool {
+		d, err := Diff(c, DefaultDiffRuntimes)
+		return err == nil && d != ""
+	})
+	if d, err := Diff(shrunk, DefaultDiffRuntimes); err == nil && d != "" {
+		diff = d
+	}
+	t.Fatalf("runtimes diverge: %s\nminimal case:\n%s", diff, shrunk)
+}
@@ -0,0 +1,117 @@
+package testutil
+
+import (
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+// FuzzRuntimesAgree compares the event-driven and tick-based runtimes on generated
+// charts and events. Run with: go test ./testutil -fuzz FuzzRuntimesAgree
+func FuzzRuntimesAgree(f *testing.F) {
+	f.Add([]byte{})
+	f.Add([]byte("\xbc\xa8\xa3\xc1I]\xdb\xfb\xdc\v}u\xb8{\x9c\xf7X`\xb7+\xbe\xf5\x936G\x1c\"\xe5\xd6w\xc5c\xee\xceM؊\xe6VU堔\xe9\xce\xf2\xfb'"))
+	f.Add([]byte("msѹ&\x00}\xeb\x00!Gf\xb5\x1e~\xe1\xb51\xd7l\x80`\xc9i\xc3\xeb\x10'\x1e\xc4h\xb6&\x83+\x05q\x97\xfd\xe1\x95\x1b\xc6__\xae\x1f'"))
+	f.Add([]byte("\x05\xd0\xf5\x06\x8fm\x15\xd2\x1d\xeeY(E\x18.\x92\x8de\x1b\xaf[\xd4\x06\x1bM\xc8\x1e\vΧ\xca\xe8\xa3Ӥ\xc0ӓ\x9b\x8bN2%\x80H\xfe\xe1\xc3"))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		CheckDifferential(t, DecodeDiffCase(data))
+	})
+}
+
+// TestDecodeDiffCase verifies that arbitrary bytes decode deterministically to charts that build
+func TestDecodeDiffCase(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for i := 0; i < 500; i++ {
+		data := make([]byte, r.Intn(64))
+		r.Read(data)
+
+		dc := DecodeDiffCase(data)
+		if !reflect.DeepEqual(dc, DecodeDiffCase(data)) {
+			t.Fatalf("decoding %q is not deterministic", data)
+		}
+		if _, _, err := dc.Chart.Build(&Trace{}); err != nil {
+			t.Fatalf("decoded chart does not build: %v\n%s", err, dc)
+		}
+	}
+}
+
+// TestShrinkDiffCase verifies shrinking against a synthetic failure
+func TestShrinkDiffCase(t *testing.T) {
+	dc := DiffCase{
+		Chart: Chart{Events: 2, States: []ChartState{
+			{Parent: -1, Kind: KindCompound},
+			{Parent: 0, Kind: KindCompound, Transitions: []ChartTransition{{Event: 0, Target: 4}}},
+			{Parent: 1, Kind: KindAtomic, Transitions: []ChartTransition{{Event: -1, Target: 3}}},
+			{Parent: 1, Kind: KindFinal},
+			{Parent: 0, Kind: KindParallel},
+			{Parent: 4, Kind: KindAtomic, Transitions: []ChartTransition{{Event: 1, Target: 2}}},
+			{Parent: 4, Kind: KindAtomic},
+		}},
+		Events: []int{0, 1, 1, 0},
+	}
+
+	// "Fails" whenever a final state exists and event 1 is sent
+	fails := func(c DiffCase) bool {
+		hasFinal := false
+		for _, s := range c.Chart.States {
+			hasFinal = hasFinal || s.Kind == KindFinal
+		}
+		for _, e := range c.Events {
+			if e == 1 && hasFinal {
+				return true
+			}
+		}
+		return false
+	}
+
+	shrunk := ShrinkDiffCase(dc, fails)
+	if !fails(shrunk) {
+		t.Fatalf("shrunk case no longer fails:\n%s", shrunk)
+	}
+	want := "s1 (compound)\ns1.s2 (final)\nevents: e1\n"
+	if got := shrunk.String(); got != want {
+		t.Errorf("expected minimal case\n%s\ngot\n%s", want, got)
+	}
+}
+
+// TestCompareParallelTraces verifies that region interleavings are not divergences
+func TestCompareParallelTraces(t *testing.T) {
+	dc := DecodeDiffCase(nil)
+	a := DiffResult{Configuration: []string{"s1"}, Trace: []string{"+s1", "+s2"}}
+	b := DiffResult{Configuration: []string{"s1"}, Trace: []string{"+s2", "+s1"}}
+	if dc.Compare(a, b) == "" {
+		t.Error("expected trace order to matter without parallel states")
+	}
+
+	dc.Chart = Chart{Events: 1, States: []ChartState{
+		{Parent: -1, Kind: KindCompound},
+		{Parent: 0, Kind: KindParallel},
+		{Parent: 1, Kind: KindAtomic},
+		{Parent: 1, Kind: KindAtomic},
+	}}
+	if d := dc.Compare(a, b); d != "" {
+		t.Errorf("expected parallel traces to compare as multisets, got %s", d)
+	}
+	b.Configuration = nil
+	if dc.Compare(a, b) == "" {
+		t.Error("expected configurations to differ")
+	}
+}
+
+// TestKnownDivergence verifies that only charts with parallel states are
+// filtered as known divergences
+func TestKnownDivergence(t *testing.T) {
+	dc := DiffCase{Chart: Chart{Events: 1, States: []ChartState{
+		{Parent: -1, Kind: KindCompound},
+		{Parent: 0, Kind: KindCompound},
+		{Parent: 1, Kind: KindAtomic},
+		{Parent: 1, Kind: KindAtomic},
+	}}}
+	if known := dc.KnownDivergence(); known != "" {
+		t.Errorf("expected no known divergence without parallel states, got %q", known)
+	}
+	dc.Chart.States[1].Kind = KindParallel
+	if dc.KnownDivergence() == "" {
+		t.Error("expected parallel states to be a known divergence")
+	}
+}
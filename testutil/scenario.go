@@ -0,0 +1,116 @@
+package testutil
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/comalice/statechartx"
+)
+
+// ScenarioFormatVersion is the current version of the scenario data file format.
+const ScenarioFormatVersion = 1
+
+// Scenario is a named event sequence with the states expected after each event.
+// States and events are referred to by MachineBuilder name, so scenarios can be
+// stored in data files and shared between runtimes.
+type Scenario struct {
+	Name  string         `json:"name"`
+	Steps []ScenarioStep `json:"steps"`
+}
+
+// ScenarioStep sends Event (nothing for an empty Event) and then checks that every
+// state in Expect is active.
+type ScenarioStep struct {
+	Event  string   `json:"event,omitempty"`
+	Expect []string `json:"expect,omitempty"`
+	// Guards documents the guard results the step relies on (guard name -> value).
+	// The runner does not enforce them; they explain why an expectation may fail.
+	Guards map[string]bool `json:"guards,omitempty"`
+}
+
+// ScenarioFile is the JSON data file format for scenarios.
+type ScenarioFile struct {
+	Version   int        `json:"version"`
+	Machine   string     `json:"machine,omitempty"`
+	Scenarios []Scenario `json:"scenarios"`
+}
+
+// ScenarioNames resolves the names used in scenarios.
+// *statechartx.MachineBuilder satisfies it.
+type ScenarioNames interface {
+	GetID(name string) statechartx.StateID
+	GetName(id statechartx.StateID) string
+	EventID(name string) statechartx.EventID
+}
+
+// ReadScenarios decodes a scenario data file.
+func ReadScenarios(r io.Reader) (*ScenarioFile, error) {
+	var f ScenarioFile
+	if err := json.NewDecoder(r).Decode(&f); err != nil {
+		return nil, err
+	}
+	if f.Version != ScenarioFormatVersion {
+		return nil, fmt.Errorf("unsupported scenario file version %d", f.Version)
+	}
+	return &f, nil
+}
+
+// WriteScenarios encodes f as an indented scenario data file, setting its version.
+func WriteScenarios(w io.Writer, f *ScenarioFile) error {
+	f.Version = ScenarioFormatVersion
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(f)
+}
+
+// RunScenario starts adapter, plays sc and reports every unmet expectation on t.
+// The adapter is stopped before RunScenario returns.
+func RunScenario(t testing.TB, adapter RuntimeAdapter, names ScenarioNames, sc Scenario) {
+	t.Helper()
+
+	if err := adapter.Start(context.Background()); err != nil {
+		t.Fatalf("%s: start failed: %v", sc.Name, err)
+	}
+	defer adapter.Stop()
+
+	for i, step := range sc.Steps {
+		if step.Event != "" {
+			id := names.EventID(step.Event)
+			if id == statechartx.NO_EVENT {
+				t.Fatalf("%s: step %d: unknown event %q", sc.Name, i, step.Event)
+			}
+			if err := adapter.SendEvent(statechartx.Event{ID: id}); err != nil {
+				t.Fatalf("%s: step %d: send %s failed: %v", sc.Name, i, step.Event, err)
+			}
+		}
+		if err := adapter.WaitForStability(time.Second); err != nil {
+			t.Fatalf("%s: step %d: %v", sc.Name, i, err)
+		}
+
+		for _, name := range step.Expect {
+			id := names.GetID(name)
+			if names.GetName(id) != name {
+				t.Fatalf("%s: step %d: unknown state %q", sc.Name, i, name)
+			}
+			if !adapter.IsInState(id) {
+				t.Errorf("%s: step %d (%s): expected %s to be active, current state is %s",
+					sc.Name, i, step.Event, name, names.GetName(adapter.GetCurrentState()))
+			}
+		}
+	}
+}
+
+// RunScenarios runs each scenario as a subtest on a fresh adapter from newAdapter.
+func RunScenarios(t *testing.T, newAdapter func() RuntimeAdapter, names ScenarioNames, scenarios []Scenario) {
+	t.Helper()
+	for _, sc := range scenarios {
+		sc := sc
+		t.Run(sc.Name, func(t *testing.T) {
+			RunScenario(t, newAdapter(), names, sc)
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package visualize
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/comalice/statechartx"
+)
+
+// DOT renders m as a Graphviz digraph.
+//
+// Compound and parallel states become clusters (parallel clusters are dashed).
+// Each cluster contains a point node that marks its initial child and serves as the
+// endpoint of transitions to and from the cluster.
+func DOT(m *statechartx.Machine, opts Options) string {
+	d := newDiagram(m, opts)
+	root := m.Root()
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "digraph %s {\n", quote(d.name(root)))
+	sb.WriteString("  compound=true;\n")
+	sb.WriteString("  rankdir=LR;\n")
+	sb.WriteString("  node [shape=box, style=rounded, fontsize=10];\n")
+	sb.WriteString("  edge [fontsize=9];\n")
+
+	d.dotInitial(&sb, root, "  ")
+	for _, child := range root.SortedChildren() {
+		d.dotState(&sb, child, "  ")
+	}
+	d.dotEdges(&sb, root)
+
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+// dotNode returns the node used as the endpoint for edges to or from s
+func (d *diagram) dotNode(s *statechartx.State) string {
+	if isComposite(s) || s.Parent == nil {
+		return quote(d.name(s) + ":initial")
+	}
+	return quote(d.name(s))
+}
+
+// dotInitial writes the initial marker of a composite state (or the root)
+func (d *diagram) dotInitial(sb *strings.Builder, s *statechartx.State, indent string) {
+	if s.IsParallel {
+		fmt.Fprintf(sb, "%s%s [shape=point, style=invis];\n", indent, d.dotNode(s))
+		return
+	}
+	fmt.Fprintf(sb, "%s%s [shape=point, width=0.1];\n", indent, d.dotNode(s))
+	if initial := d.m.GetState(s.Initial); initial != nil && s.Initial != s.ID {
+		fmt.Fprintf(sb, "%s%s -> %s%s;\n", indent, d.dotNode(s), d.dotNode(initial), attrList(d.withHead(nil, initial)))
+	}
+}
+
+func (d *diagram) dotState(sb *strings.Builder, s *statechartx.State, indent string) {
+	name := d.name(s)
+
+	switch {
+	case s.IsHistoryState:
+		fmt.Fprintf(sb, "%s%s [shape=circle, label=%s];\n", indent, quote(name), quote(historyLabel(s)))
+		if def := d.m.GetState(s.HistoryDefault); def != nil {
+			fmt.Fprintf(sb, "%s%s -> %s%s;\n", indent, quote(name), d.dotNode(def), attrList(d.withHead([]string{"style=dashed"}, def)))
+		}
+	case isComposite(s):
+		fmt.Fprintf(sb, "%ssubgraph %s {\n", indent, quote("cluster_"+name))
+		inner := indent + "  "
+		fmt.Fprintf(sb, "%slabel=%s;\n", inner, quote(name))
+		style := "rounded"
+		if s.IsParallel {
+			style = "dashed"
+		}
+		if d.active(s) {
+			fmt.Fprintf(sb, "%sstyle=\"%s,filled\";\n%sfillcolor=honeydew;\n", inner, style, inner)
+		} else {
+			fmt.Fprintf(sb, "%sstyle=%s;\n", inner, style)
+		}
+		d.dotInitial(sb, s, inner)
+		for _, child := range s.SortedChildren() {
+			d.dotState(sb, child, inner)
+		}
+		fmt.Fprintf(sb, "%s}\n", indent)
+	default:
+		attrs := []string{"label=" + quote(name)}
+		if isFinal(s) {
+			attrs = append(attrs, "peripheries=2")
+		}
+		if d.active(s) {
+			attrs = append(attrs, `style="rounded,filled"`, "fillcolor=lightgreen")
+		}
+		fmt.Fprintf(sb, "%s%s [%s];\n", indent, quote(name), strings.Join(attrs, ", "))
+	}
+}
+
+// dotEdges writes every transition in the machine, in declaration order
+func (d *diagram) dotEdges(sb *strings.Builder, s *statechartx.State) {
+	for _, t := range s.Transitions {
+		if t == nil {
+			continue
+		}
+		target := d.target(t, s)
+		if target == nil {
+			continue
+		}
+		attrs := []string{"label=" + quote(d.label(t))}
+		// Graphviz rejects clipping an edge at a cluster that contains its other end
+		if isComposite(s) && target != s && !target.IsDescendantOf(s) {
+			attrs = append(attrs, "ltail="+quote("cluster_"+d.name(s)))
+		}
+		if target != s && !s.IsDescendantOf(target) {
+			attrs = d.withHead(attrs, target)
+		}
+		if t.Target == 0 {
+			attrs = append(attrs, "style=dotted")
+		}
+		fmt.Fprintf(sb, "  %s -> %s%s;\n", d.dotNode(s), d.dotNode(target), attrList(attrs))
+	}
+	for _, child := range s.SortedChildren() {
+		d.dotEdges(sb, child)
+	}
+}
+
+// withHead appends lhead to attrs when an edge points at a cluster
+func (d *diagram) withHead(attrs []string, target *statechartx.State) []string {
+	if isComposite(target) {
+		attrs = append(attrs, "lhead="+quote("cluster_"+d.name(target)))
+	}
+	return attrs
+}
+
+// attrList formats an attribute list, or nothing if attrs is empty
+func attrList(attrs []string) string {
+	if len(attrs) == 0 {
+		return ""
+	}
+	return " [" + strings.Join(attrs, ", ") + "]"
+}
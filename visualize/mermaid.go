@@ -0,0 +1,118 @@
+package visualize
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/comalice/statechartx"
+)
+
+// Mermaid renders m as a Mermaid stateDiagram-v2.
+//
+// Transitions are written inside the innermost composite state that contains both
+// ends, since Mermaid creates a new state when a nested state is referenced from
+// outside its parent. Parallel regions are separated with "--". Final states are
+// drawn with a transition to [*]; history states are labelled H or H*.
+func Mermaid(m *statechartx.Machine, opts Options) string {
+	d := newDiagram(m, opts)
+	root := m.Root()
+
+	// Group transitions by the composite state that scopes them
+	scoped := make(map[statechartx.StateID][]string)
+	d.collectScoped(root, scoped)
+
+	var sb strings.Builder
+	sb.WriteString("stateDiagram-v2\n")
+	d.mermaidBody(&sb, root, scoped, "  ")
+
+	if opts.Active != nil {
+		var active []string
+		d.walk(root, func(s *statechartx.State) {
+			if !isComposite(s) && !s.IsHistoryState && s.Parent != nil && d.active(s) {
+				active = append(active, d.ident(s))
+			}
+		})
+		if len(active) > 0 {
+			sb.WriteString("  classDef active fill:#90ee90,stroke:#2e7d32\n")
+			fmt.Fprintf(&sb, "  class %s active\n", strings.Join(active, ","))
+		}
+	}
+	return sb.String()
+}
+
+// mermaidBody writes the contents of a composite state (or the root)
+func (d *diagram) mermaidBody(sb *strings.Builder, s *statechartx.State, scoped map[statechartx.StateID][]string, indent string) {
+	if !s.IsParallel {
+		if initial := d.m.GetState(s.Initial); initial != nil && s.Initial != s.ID {
+			fmt.Fprintf(sb, "%s[*] --> %s\n", indent, d.ident(initial))
+		}
+	}
+	for i, child := range s.SortedChildren() {
+		if s.IsParallel && i > 0 {
+			fmt.Fprintf(sb, "%s--\n", indent)
+		}
+		d.mermaidState(sb, child, scoped, indent)
+	}
+	for _, line := range scoped[s.ID] {
+		fmt.Fprintf(sb, "%s%s\n", indent, line)
+	}
+}
+
+func (d *diagram) mermaidState(sb *strings.Builder, s *statechartx.State, scoped map[statechartx.StateID][]string, indent string) {
+	name := d.name(s)
+	id := d.ident(s)
+
+	switch {
+	case s.IsHistoryState:
+		fmt.Fprintf(sb, "%sstate %s as %s\n", indent, quote(historyLabel(s)), id)
+		if def := d.m.GetState(s.HistoryDefault); def != nil {
+			fmt.Fprintf(sb, "%s%s --> %s : default\n", indent, id, d.ident(def))
+		}
+	case isComposite(s):
+		fmt.Fprintf(sb, "%sstate %s as %s {\n", indent, quote(name), id)
+		d.mermaidBody(sb, s, scoped, indent+"  ")
+		fmt.Fprintf(sb, "%s}\n", indent)
+	default:
+		fmt.Fprintf(sb, "%sstate %s as %s\n", indent, quote(name), id)
+		if isFinal(s) {
+			fmt.Fprintf(sb, "%s%s --> [*]\n", indent, id)
+		}
+	}
+}
+
+// collectScoped assigns each transition line to the composite state that must contain it
+func (d *diagram) collectScoped(s *statechartx.State, scoped map[statechartx.StateID][]string) {
+	for _, t := range s.Transitions {
+		if t == nil {
+			continue
+		}
+		target := d.target(t, s)
+		if target == nil {
+			continue
+		}
+		scope := s.Parent
+		for scope != nil && scope.Parent != nil && !target.IsDescendantOf(scope) {
+			scope = scope.Parent
+		}
+		if scope == nil {
+			continue // root transitions have no source node to draw
+		}
+
+		line := d.ident(s) + " --> " + d.ident(target)
+		if label := d.label(t); label != "" {
+			line += " : " + strings.ReplaceAll(label, "\n", " ")
+		}
+		scoped[scope.ID] = append(scoped[scope.ID], line)
+	}
+	for _, child := range s.SortedChildren() {
+		d.collectScoped(child, scoped)
+	}
+}
+
+// walk visits s and its descendants in declaration order
+func (d *diagram) walk(s *statechartx.State, visit func(*statechartx.State)) {
+	visit(s)
+	for _, child := range s.SortedChildren() {
+		d.walk(child, visit)
+	}
+}
@@ -0,0 +1,88 @@
+package visualize
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/comalice/statechartx"
+)
+
+// PlantUML renders m as a PlantUML state diagram.
+//
+// Parallel regions are separated with "--", history states use the <<history>> and
+// <<history*>> stereotypes, and active states are filled light green.
+func PlantUML(m *statechartx.Machine, opts Options) string {
+	d := newDiagram(m, opts)
+	root := m.Root()
+
+	var sb strings.Builder
+	sb.WriteString("@startuml\n")
+	fmt.Fprintf(&sb, "title %s\n", d.name(root))
+	d.plantUMLBody(&sb, root, "")
+	d.walk(root, func(s *statechartx.State) {
+		if s.Parent == nil {
+			return
+		}
+		for _, t := range s.Transitions {
+			if t == nil {
+				continue
+			}
+			target := d.target(t, s)
+			if target == nil {
+				continue
+			}
+			line := d.ident(s) + " --> " + d.ident(target)
+			if label := d.label(t); label != "" {
+				line += " : " + strings.ReplaceAll(label, "\n", " ")
+			}
+			sb.WriteString(line + "\n")
+		}
+	})
+	sb.WriteString("@enduml\n")
+	return sb.String()
+}
+
+// plantUMLBody writes the contents of a composite state (or the root)
+func (d *diagram) plantUMLBody(sb *strings.Builder, s *statechartx.State, indent string) {
+	if !s.IsParallel {
+		if initial := d.m.GetState(s.Initial); initial != nil && s.Initial != s.ID {
+			fmt.Fprintf(sb, "%s[*] --> %s\n", indent, d.ident(initial))
+		}
+	}
+	for i, child := range s.SortedChildren() {
+		if s.IsParallel && i > 0 {
+			fmt.Fprintf(sb, "%s--\n", indent)
+		}
+		d.plantUMLState(sb, child, indent)
+	}
+}
+
+func (d *diagram) plantUMLState(sb *strings.Builder, s *statechartx.State, indent string) {
+	name := d.name(s)
+	id := d.ident(s)
+	color := ""
+	if d.active(s) {
+		color = " #lightgreen"
+	}
+
+	switch {
+	case s.IsHistoryState:
+		stereotype := "<<history>>"
+		if s.HistoryType == statechartx.HistoryDeep {
+			stereotype = "<<history*>>"
+		}
+		fmt.Fprintf(sb, "%sstate %s %s\n", indent, id, stereotype)
+		if def := d.m.GetState(s.HistoryDefault); def != nil {
+			fmt.Fprintf(sb, "%s%s -[dashed]-> %s\n", indent, id, d.ident(def))
+		}
+	case isComposite(s):
+		fmt.Fprintf(sb, "%sstate %s as %s%s {\n", indent, quote(name), id, color)
+		d.plantUMLBody(sb, s, indent+"  ")
+		fmt.Fprintf(sb, "%s}\n", indent)
+	default:
+		fmt.Fprintf(sb, "%sstate %s as %s%s\n", indent, quote(name), id, color)
+		if isFinal(s) {
+			fmt.Fprintf(sb, "%s%s --> [*]\n", indent, id)
+		}
+	}
+}
@@ -0,0 +1,177 @@
+// Package visualize renders statechartx machines as diagrams.
+//
+// Three output formats are supported:
+//
+//   - DOT: Graphviz digraph with clusters for compound and parallel states
+//   - Mermaid: stateDiagram-v2, suitable for Markdown documentation
+//   - PlantUML: @startuml state diagram
+//
+// All formats draw the state hierarchy (compound states nest their children,
+// parallel states separate their regions), initial markers, history
+// pseudo-states with their default transitions, final states, and transitions
+// labelled "event [guard] / action1, action2". Guards and actions are labelled with
+// their Registry names when they have one.
+//
+// Set Options.Active to a running Runtime (or RealtimeRuntime) to highlight the
+// active configuration.
+//
+// Example:
+//
+//	machine, _ := b.Build()
+//	fmt.Println(visualize.Mermaid(machine, visualize.Options{Names: b}))
+package visualize
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/comalice/statechartx"
+)
+
+// StateChecker reports whether a state is active. *statechartx.Runtime and
+// *realtime.RealtimeRuntime both satisfy it.
+type StateChecker interface {
+	IsInState(stateID statechartx.StateID) bool
+}
+
+// Options control diagram rendering.
+type Options struct {
+	// Names provides state and event names (typically the *statechartx.MachineBuilder).
+	// If nil, states are named "s<id>" and events "e<id>".
+	Names statechartx.Namer
+
+	// Active, if set, highlights the states it reports as active.
+	Active StateChecker
+
+	// HideActions omits the "/ action" part of transition labels.
+	HideActions bool
+}
+
+// Label text for guards and actions without a registry name.
+const (
+	anonymousGuard  = "guard"
+	anonymousAction = "action"
+)
+
+// diagram holds what every renderer needs: the machine and resolved options
+type diagram struct {
+	m      *statechartx.Machine
+	opts   Options
+	idents map[statechartx.StateID]string // Mermaid/PlantUML identifiers, unique per render
+}
+
+// newDiagram assigns every state a unique identifier. States whose names map to
+// the same identifier (e.g. "a.b" and "a_b") get a numeric suffix in declaration order.
+func newDiagram(m *statechartx.Machine, opts Options) *diagram {
+	d := &diagram{m: m, opts: opts, idents: make(map[statechartx.StateID]string)}
+	taken := make(map[string]bool)
+	d.walk(m.Root(), func(s *statechartx.State) {
+		base := identifier(d.name(s))
+		id := base
+		for n := 2; taken[id]; n++ {
+			id = fmt.Sprintf("%s_%d", base, n)
+		}
+		taken[id] = true
+		d.idents[s.ID] = id
+	})
+	return d
+}
+
+// ident returns the unique Mermaid/PlantUML identifier of s
+func (d *diagram) ident(s *statechartx.State) string {
+	return d.idents[s.ID]
+}
+
+func (d *diagram) name(s *statechartx.State) string {
+	return statechartx.StateNameOf(d.opts.Names, s.ID)
+}
+
+func (d *diagram) active(s *statechartx.State) bool {
+	return d.opts.Active != nil && d.opts.Active.IsInState(s.ID)
+}
+
+// label returns the "event [guard] / actions" label of a transition
+func (d *diagram) label(t *statechartx.Transition) string {
+	var parts []string
+	if event := statechartx.EventNameOf(d.opts.Names, t.Event); event != "" {
+		parts = append(parts, event)
+	}
+	if t.GuardRef != "" {
+		parts = append(parts, "["+t.GuardRef+"]")
+	} else if t.Guard != nil {
+		parts = append(parts, "["+anonymousGuard+"]")
+	}
+	if !d.opts.HideActions {
+		if actions := actionNames(t); len(actions) > 0 {
+			parts = append(parts, "/ "+strings.Join(actions, ", "))
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// actionNames lists a transition's actions in execution order
+func actionNames(t *statechartx.Transition) []string {
+	var names []string
+	if t.Action != nil {
+		names = append(names, anonymousAction)
+	}
+	for i, a := range t.Actions {
+		if a == nil {
+			continue
+		}
+		if i < len(t.ActionRefs) && t.ActionRefs[i] != "" {
+			names = append(names, t.ActionRefs[i])
+		} else {
+			names = append(names, anonymousAction)
+		}
+	}
+	return names
+}
+
+// target returns the state a transition points to; internal transitions loop to their source
+func (d *diagram) target(t *statechartx.Transition, source *statechartx.State) *statechartx.State {
+	if t.Target == 0 {
+		return source
+	}
+	return d.m.GetState(t.Target)
+}
+
+func isComposite(s *statechartx.State) bool {
+	return len(s.Children) > 0 && !s.IsHistoryState
+}
+
+func isFinal(s *statechartx.State) bool {
+	return s.IsFinal || s.Final
+}
+
+// historyLabel returns "H" for shallow and "H*" for deep history
+func historyLabel(s *statechartx.State) string {
+	if s.HistoryType == statechartx.HistoryDeep {
+		return "H*"
+	}
+	return "H"
+}
+
+// identifier converts a state name into an identifier safe for Mermaid and PlantUML
+func identifier(name string) string {
+	var sb strings.Builder
+	for i, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r == '_':
+			sb.WriteRune(r)
+		case r >= '0' && r <= '9':
+			if i == 0 {
+				sb.WriteRune('_')
+			}
+			sb.WriteRune(r)
+		default:
+			sb.WriteRune('_')
+		}
+	}
+	return sb.String()
+}
+
+// quote escapes a string for use inside double quotes
+func quote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s) + `"`
+}
@@ -0,0 +1,156 @@
+package visualize_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/comalice/statechartx"
+	"github.com/comalice/statechartx/visualize"
+)
+
+func buildChart(t *testing.T) (*statechartx.MachineBuilder, *statechartx.Machine) {
+	t.Helper()
+	reg := statechartx.NewRegistry()
+	reg.RegisterGuard("hasPower", statechartx.Always())
+	reg.RegisterAction("beep", func(ctx context.Context, evt *statechartx.Event, from, to statechartx.StateID) error {
+		return nil
+	})
+
+	b := statechartx.NewMachineBuilder("device", "off").WithRegistry(reg)
+	b.State("off").OnRef("power", "on", "hasPower", "beep")
+	b.State("on").Compound("on.idle").
+		OnRef("power", "off", "").
+		OnRef("resume", "on.hist", "")
+	b.State("on.idle").OnInternalRef("ping", "", "beep").On("work", "on.done", statechartx.Always(), nil)
+	b.State("on.done").Final(nil)
+	b.State("on.hist").History(statechartx.HistoryDeep, "on.idle")
+	b.State("both").Parallel()
+	b.State("both.left").Atomic()
+	b.State("both.right").Atomic()
+
+	machine, err := b.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b, machine
+}
+
+func assertContains(t *testing.T, format, out string, want ...string) {
+	t.Helper()
+	for _, w := range want {
+		if !strings.Contains(out, w) {
+			t.Errorf("%s output should contain %q:\n%s", format, w, out)
+		}
+	}
+}
+
+func TestDOT(t *testing.T) {
+	b, machine := buildChart(t)
+	out := visualize.DOT(machine, visualize.Options{Names: b})
+
+	assertContains(t, "DOT", out,
+		`digraph "device" {`,
+		`subgraph "cluster_on" {`,
+		`subgraph "cluster_both" {`,
+		`style=dashed;`,
+		`"device:initial" -> "off";`,
+		`"on:initial" -> "on.idle";`,
+		`"on.hist" [shape=circle, label="H*"];`,
+		`"on.hist" -> "on.idle" [style=dashed];`,
+		`"on.done" [label="on.done", peripheries=2];`,
+		`"off" -> "on:initial" [label="power [hasPower] / beep", lhead="cluster_on"];`,
+		`"on:initial" -> "off" [label="power", ltail="cluster_on"];`,
+		`"on.idle" -> "on.idle" [label="ping / beep", style=dotted];`,
+		`"on.idle" -> "on.done" [label="work [guard]"];`,
+	)
+	if strings.Contains(out, "lightgreen") {
+		t.Error("nothing should be highlighted without Options.Active")
+	}
+}
+
+func TestMermaid(t *testing.T) {
+	b, machine := buildChart(t)
+	out := visualize.Mermaid(machine, visualize.Options{Names: b, HideActions: true})
+
+	assertContains(t, "Mermaid", out,
+		"stateDiagram-v2\n",
+		"  [*] --> off\n",
+		`  state "on" as on {`,
+		"    [*] --> on_idle\n",
+		`    state "H*" as on_hist`,
+		"    on_hist --> on_idle : default\n",
+		"    on_done --> [*]\n",
+		"    on_idle --> on_done : work [guard]\n",
+		"  off --> on : power [hasPower]\n",
+		"    --\n",
+	)
+	if strings.Contains(out, "beep") {
+		t.Error("HideActions should omit actions")
+	}
+}
+
+func TestPlantUML(t *testing.T) {
+	b, machine := buildChart(t)
+	out := visualize.PlantUML(machine, visualize.Options{Names: b})
+
+	assertContains(t, "PlantUML", out,
+		"@startuml\n",
+		`state "on" as on {`,
+		"  state on_hist <<history*>>\n",
+		"off --> on : power [hasPower] / beep\n",
+		"on_idle --> on_idle : ping / beep\n",
+		"@enduml\n",
+	)
+}
+
+func TestActiveConfigurationHighlight(t *testing.T) {
+	b, machine := buildChart(t)
+	rt := statechartx.NewRuntime(machine, nil)
+	ctx := context.Background()
+	if err := rt.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+	defer rt.Stop()
+
+	rt.SendEvent(ctx, statechartx.Event{ID: b.EventID("power")})
+	time.Sleep(50 * time.Millisecond)
+
+	opts := visualize.Options{Names: b, Active: rt}
+	assertContains(t, "DOT", visualize.DOT(machine, opts),
+		`"on.idle" [label="on.idle", style="rounded,filled", fillcolor=lightgreen];`,
+		"fillcolor=honeydew;",
+	)
+	assertContains(t, "Mermaid", visualize.Mermaid(machine, opts), "  class on_idle active\n")
+	assertContains(t, "PlantUML", visualize.PlantUML(machine, opts), `state "on.idle" as on_idle #lightgreen`)
+
+	if strings.Contains(visualize.DOT(machine, opts), `"off" [label="off", style="rounded,filled"`) {
+		t.Error("inactive state should not be highlighted")
+	}
+}
+
+func TestIdentifiersUnique(t *testing.T) {
+	b := statechartx.NewMachineBuilder("clash", "a-b")
+	b.State("a-b").On("go", "a_b", nil, nil)
+	b.State("a_b").On("go", "a b", nil, nil)
+	b.State("a b").Atomic()
+	machine, err := b.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	opts := visualize.Options{Names: b}
+	assertContains(t, "Mermaid", visualize.Mermaid(machine, opts),
+		`state "a-b" as a_b`+"\n",
+		`state "a_b" as a_b_2`+"\n",
+		`state "a b" as a_b_3`+"\n",
+		"a_b --> a_b_2 : go\n",
+		"a_b_2 --> a_b_3 : go\n",
+	)
+	assertContains(t, "PlantUML", visualize.PlantUML(machine, opts),
+		`state "a-b" as a_b`+"\n",
+		`state "a b" as a_b_3`+"\n",
+		"a_b_2 --> a_b_3 : go\n",
+	)
+}